@@ -2,10 +2,15 @@ package main
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/ksmithbaylor/gohodl/internal/config"
 )
 
 func main() {
+	if err := config.Load(config.DEFAULT_PATH); err != nil {
+		log.Fatal(err)
+	}
+
 	fmt.Printf("Config: %#+v\n", config.Config)
 }
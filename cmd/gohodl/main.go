@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/cost_basis"
+	"github.com/ksmithbaylor/gohodl/internal/ctc"
+	"github.com/ksmithbaylor/gohodl/internal/generic"
+	handlers "github.com/ksmithbaylor/gohodl/internal/handlers/kevin"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+const (
+	EXIT_OK      = 0
+	EXIT_FAILURE = 1
+	EXIT_USAGE   = 2
+)
+
+const USAGE = `Usage: gohodl <command> [flags]
+
+Commands:
+  identify  Find the transaction hashes of owned addresses
+  fetch     Fetch and cache the identified transactions
+  analyze   Summarize the fetched EVM transactions into txs.csv
+  export    Export the transactions of each tax year or range, in each format
+  report    Write Form 8949 rows and a Schedule D summary for each tax year or range
+  status    Show how far each network has made it through the steps
+  explain   Show everything about one transaction and how it would be exported
+  snapshot  Save one transaction as a fixture for the handler tests
+  run       Run identify, fetch, analyze and export in order
+
+Run 'gohodl <command> -h' to see the flags for a command.
+`
+
+// Everything a command needs, built from the flags
+type environment struct {
+	db      *util.FileDB
+	opts    ctc.Options
+	clients func() generic.AllNodeClients
+	args    []string // Positional arguments after the flags
+
+	fixturesDir string // Where snapshot saves fixtures
+}
+
+type command struct {
+	args func(args []string) error // Checks the positional arguments (default none)
+	run  func(env environment) error
+}
+
+var COMMANDS = map[string]command{
+	"identify": {run: func(env environment) error {
+		return ctc.IdentifyTransactions(env.db, env.clients(), env.opts)
+	}},
+	"fetch": {run: func(env environment) error {
+		return ctc.FetchTransactions(env.db, env.clients(), env.opts)
+	}},
+	"analyze": {run: func(env environment) error {
+		return ctc.AnalyzeTransactions(env.db, env.opts)
+	}},
+	"export": {run: func(env environment) error {
+		return ctc.ExportTransactions(env.db, env.clients(), env.opts)
+	}},
+	"report": {run: func(env environment) error {
+		return ctc.Report(env.db, env.clients(), env.opts)
+	}},
+	"status": {run: func(env environment) error {
+		return ctc.Status(env.db, env.opts)
+	}},
+	"explain": {
+		args: func(args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return fmt.Errorf("Usage: gohodl explain [flags] [network] <hash>")
+			}
+			return nil
+		},
+		run: func(env environment) error {
+			network, hash := "", env.args[0]
+			if len(env.args) == 2 {
+				network, hash = env.args[0], env.args[1]
+			}
+			return ctc.Explain(env.db, env.clients(), env.opts, network, hash)
+		},
+	},
+	"snapshot": {
+		args: func(args []string) error {
+			if len(args) < 1 || len(args) > 2 {
+				return fmt.Errorf("Usage: gohodl snapshot [flags] [network] <hash>")
+			}
+			return nil
+		},
+		run: func(env environment) error {
+			network, hash := "", env.args[0]
+			if len(env.args) == 2 {
+				network, hash = env.args[0], env.args[1]
+			}
+			return ctc.Snapshot(env.db, env.clients(), env.opts, network, hash, env.fixturesDir)
+		},
+	},
+	"run": {run: func(env environment) error {
+		clients := env.clients()
+		if err := ctc.IdentifyTransactions(env.db, clients, env.opts); err != nil {
+			return err
+		}
+		if err := ctc.FetchTransactions(env.db, clients, env.opts); err != nil {
+			return err
+		}
+		if err := ctc.AnalyzeTransactions(env.db, env.opts); err != nil {
+			return err
+		}
+		return ctc.ExportTransactions(env.db, clients, env.opts)
+	}},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, USAGE)
+		if len(args) == 0 {
+			return EXIT_USAGE
+		}
+		return EXIT_OK
+	}
+
+	name := args[0]
+	cmd, found := COMMANDS[name]
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n%s", name, USAGE)
+		return EXIT_USAGE
+	}
+
+	flags := flag.NewFlagSet("gohodl "+name, flag.ContinueOnError)
+	networks := flags.String("networks", "", "comma-separated networks to work on (default all)")
+	labels := flags.String("labels", "", "comma-separated address labels to identify (default all)")
+	years := flags.String("years", "", "comma-separated tax years to export, each to its own CSV (default last complete year)")
+	from := flags.String("from", "", "start of the export range as YYYY-MM-DD in the tax timezone, inclusive")
+	to := flags.String("to", "", "end of the export range as YYYY-MM-DD in the tax timezone, exclusive")
+	split := flags.Bool("split", false, "split the export range into one CSV per tax year")
+	dataDir := flags.String("data", "data", "directory for cached data and CSVs")
+	configPath := flags.String("config", config.DEFAULT_PATH, "path to the config file")
+	strict := flags.Bool("strict", false, "fail the export if any transaction couldn't be handled")
+	formats := flags.String("formats", "", "comma-separated export formats: "+strings.Join(ledger.FormatNames(), ", ")+" (default ctc)")
+	method := flags.String("method", string(cost_basis.FIFO), "cost basis method for reports: fifo, lifo, hifo or average")
+	fixturesDir := flags.String("fixtures", "internal/handlers/kevin/testdata", "directory to save snapshot fixtures to")
+	retryDead := flags.Bool("retry-dead", false, "fetch only the transactions that ran out of fetch attempts before")
+	open := flags.Bool("open", false, "open unhandled transactions in the explorer while exporting")
+
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return EXIT_OK
+		}
+		return EXIT_USAGE
+	}
+	if cmd.args != nil {
+		if err := cmd.args(flags.Args()); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return EXIT_USAGE
+		}
+	} else if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+		return EXIT_USAGE
+	}
+
+	// The handlers check OPEN before opening transactions in the explorer
+	if *open {
+		os.Setenv("OPEN", "true")
+	}
+
+	if err := config.Load(*configPath); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return EXIT_FAILURE
+	}
+
+	if config.Config.Rules != "" {
+		if err := handlers.LoadRules(config.Config.Rules); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return EXIT_FAILURE
+		}
+	}
+
+	periods, err := parsePeriods(config.Config.Tax, *years, *from, *to, *split)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return EXIT_USAGE
+	}
+
+	costBasisMethod, err := cost_basis.ParseMethod(*method)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return EXIT_USAGE
+	}
+
+	opts := ctc.Options{
+		Networks: splitList(*networks),
+		Labels:   splitList(*labels),
+		Periods:  periods,
+		Strict:   *strict,
+		Formats:  splitList(*formats),
+		Method:   costBasisMethod,
+
+		RetryDead: *retryDead,
+	}
+
+	db := util.NewFileDB(*dataDir)
+	env := environment{
+		db:   db,
+		opts: opts,
+		clients: func() generic.AllNodeClients {
+			return generic.NewAllNodeClients(opts.FilterNetworks(config.Config.AllNetworks()), db)
+		},
+		args: flags.Args(),
+
+		fixturesDir: *fixturesDir,
+	}
+
+	if err := cmd.run(env); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", name, err.Error())
+		return EXIT_FAILURE
+	}
+
+	return EXIT_OK
+}
+
+func splitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parsePeriods turns the -years or -from/-to flags into the periods to export,
+// or none for the default.
+func parsePeriods(cfg tax_period.Config, years, from, to string, split bool) ([]tax_period.Period, error) {
+	if years != "" && (from != "" || to != "") {
+		return nil, fmt.Errorf("Use either -years or -from and -to, not both")
+	}
+	if (from == "") != (to == "") {
+		return nil, fmt.Errorf("Both -from and -to are needed for an export range")
+	}
+	if split && from == "" {
+		return nil, fmt.Errorf("Only an export range given by -from and -to can be split")
+	}
+
+	periods := make([]tax_period.Period, 0)
+
+	for _, year := range splitList(years) {
+		yearInt, err := strconv.Atoi(year)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tax year '%s'", year)
+		}
+		period, err := cfg.Year(yearInt)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	if from != "" {
+		period, err := cfg.Range(from, to)
+		if err != nil {
+			return nil, err
+		}
+		if !split {
+			return []tax_period.Period{period}, nil
+		}
+		return cfg.SplitByYear(period)
+	}
+
+	return periods, nil
+}
@@ -2,14 +2,20 @@ package main
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/ksmithbaylor/gohodl/internal/config"
 	"github.com/ksmithbaylor/gohodl/internal/core"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
 	"github.com/ksmithbaylor/gohodl/internal/generic"
+	"github.com/ksmithbaylor/gohodl/internal/util"
 )
 
 func main() {
+	if err := config.Load(config.DEFAULT_PATH); err != nil {
+		log.Fatal(err)
+	}
+
 	allNetworks := config.Config.EvmNetworks
 
 	longestName := 0
@@ -19,7 +25,7 @@ func main() {
 
 	fmt.Println("Connecting...")
 
-	clients := generic.NewAllNodeClients(config.Config.AllNetworks())
+	clients := generic.NewAllNodeClients(config.Config.AllNetworks(), util.NewFileDB("data"))
 
 	fmt.Printf("\nBlock numbers:\n")
 	clients.ForEach(func(networkName string, client core.NodeClient) {
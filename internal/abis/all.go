@@ -0,0 +1,75 @@
+package abis
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// Every known ABI by name, for decoding transactions without knowing ahead of
+// time which contract they're for
+var ALL = map[string]*abi.ABI{
+	"aave":                  &AaveAbi,
+	"aave_rewards":          &AaveRewardsAbi,
+	"erc1155":               &Erc1155Abi,
+	"erc20":                 &Erc20Abi,
+	"erc721":                &Erc721Abi,
+	"friend_tech":           &FriendTechAbi,
+	"instadapp":             &InstadappAbi,
+	"moonwell_comptroller":  &MoonwellComptrollerAbi,
+	"moonwell_native_token": &MoonwellNativeTokenAbi,
+	"moonwell_staking":      &MoonwellStakingAbi,
+	"moonwell_token":        &MoonwellTokenAbi,
+	"one_inch":              &OneInchAbi,
+	"paraswap":              &ParaswapAbi,
+	"uniswap_universal":     &UniswapUniversalAbi,
+	"uniswap_v2":            &UniswapV2Abi,
+	"uniswap_v3":            &UniswapV3Abi,
+	"wonderland":            &WonderlandAbi,
+	"wrapped_native":        &WrappedNativeAbi,
+	"x_squared":             &XSquaredAbi,
+}
+
+// AllNames returns the names of every known ABI, sorted.
+func AllNames() []string {
+	names := make([]string, 0, len(ALL))
+	for name := range ALL {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type DecodedCall struct {
+	Abi    string
+	Method string // Full signature, like transfer(address,uint256)
+	Args   map[string]any
+	Err    error // Set if the selector matched but the arguments didn't decode
+}
+
+// DecodeCalldata decodes the calldata of a transaction against every known
+// ABI with a method for its selector.
+func DecodeCalldata(data []byte) []DecodedCall {
+	calls := make([]DecodedCall, 0)
+	if len(data) < 4 {
+		return calls
+	}
+
+	for _, name := range AllNames() {
+		method, err := ALL[name].MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+
+		args := make(map[string]any)
+		err = method.Inputs.UnpackIntoMap(args, data[4:])
+		calls = append(calls, DecodedCall{
+			Abi:    name,
+			Method: method.Sig,
+			Args:   args,
+			Err:    err,
+		})
+	}
+
+	return calls
+}
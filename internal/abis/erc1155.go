@@ -0,0 +1,38 @@
+package abis
+
+import (
+	_ "embed"
+	"log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var Erc1155Abi abi.ABI
+var ERC1155_SAFE_TRANSFER_FROM string
+var ERC1155_SAFE_BATCH_TRANSFER_FROM string
+var ERC1155_SET_APPROVAL_FOR_ALL string
+
+//go:embed erc1155.json
+var erc1155AbiJson string
+
+func init() {
+	abi, err := abi.JSON(strings.NewReader(erc1155AbiJson))
+	if err != nil {
+		log.Fatalf("Could not parse ERC-1155 ABI: %s\n", err.Error())
+	}
+	Erc1155Abi = abi
+	for _, method := range abi.Methods {
+		selector := "0x" + common.Bytes2Hex(method.ID)
+
+		switch method.Name {
+		case "safeTransferFrom":
+			ERC1155_SAFE_TRANSFER_FROM = selector
+		case "safeBatchTransferFrom":
+			ERC1155_SAFE_BATCH_TRANSFER_FROM = selector
+		case "setApprovalForAll":
+			ERC1155_SET_APPROVAL_FOR_ALL = selector
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package abis
+
+import (
+	_ "embed"
+	"log"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var Erc721Abi abi.ABI
+var ERC721_TRANSFER_FROM string // Same selector as ERC20_TRANSFER_FROM
+var ERC721_SAFE_TRANSFER_FROM string
+var ERC721_SAFE_TRANSFER_FROM_0 string
+var ERC721_APPROVE string
+var ERC721_SET_APPROVAL_FOR_ALL string
+
+//go:embed erc721.json
+var erc721AbiJson string
+
+func init() {
+	abi, err := abi.JSON(strings.NewReader(erc721AbiJson))
+	if err != nil {
+		log.Fatalf("Could not parse ERC-721 ABI: %s\n", err.Error())
+	}
+	Erc721Abi = abi
+	for _, method := range abi.Methods {
+		selector := "0x" + common.Bytes2Hex(method.ID)
+
+		switch method.Name {
+		case "transferFrom":
+			ERC721_TRANSFER_FROM = selector
+		case "safeTransferFrom":
+			ERC721_SAFE_TRANSFER_FROM = selector
+		case "safeTransferFrom0":
+			ERC721_SAFE_TRANSFER_FROM_0 = selector
+		case "approve":
+			ERC721_APPROVE = selector
+		case "setApprovalForAll":
+			ERC721_SET_APPROVAL_FOR_ALL = selector
+		}
+	}
+}
@@ -2,34 +2,48 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cosmos"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/prices"
+	"github.com/ksmithbaylor/gohodl/internal/solana"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/utxo"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
-// Global, read in at startup
+// Global, read in at startup with Load
 var Config config
 
+const DEFAULT_PATH = "config.yml"
+
 ////////////////////////////////////////////////////////////////////////////////
 // Top-level
 
 type config struct {
-	Ownership   blockchains   `mapstructure:"ownership"`
-	EvmNetworks []evm.Network `mapstructure:"evm_networks"`
+	Ownership      blockchains       `mapstructure:"ownership"`
+	EvmNetworks    []evm.Network     `mapstructure:"evm_networks"`
+	UtxoNetworks   []utxo.Network    `mapstructure:"utxo_networks"`
+	SolanaNetworks []solana.Network  `mapstructure:"solana_networks"`
+	CosmosNetworks []cosmos.Network  `mapstructure:"cosmos_networks"`
+	Prices         prices.Config     `mapstructure:"prices"`
+	Tax            tax_period.Config `mapstructure:"tax"`
+	AssetRegistry  string            `mapstructure:"asset_registry"` // Path to a YAML or JSON file of canonical assets
+	Rules          string            `mapstructure:"rules"`          // Path to a YAML file of transaction classification rules
+	Overrides      string            `mapstructure:"overrides"`      // Path to a YAML file of per-transaction overrides
 }
 
 type blockchains struct {
-	// Bitcoin  utxo     `mapstructure:"bitcoin"`
-	Ethereum ethereum `mapstructure:"ethereum"`
-	// Solana   solana   `mapstructure:"solana"`
-	// Cosmos   cosmos   `mapstructure:"cosmos"`
-	// Dogecoin utxo     `mapstructure:"dogecoin"`
-	// Litecoin utxo     `mapstructure:"litecoin"`
+	Bitcoin  utxoWallets     `mapstructure:"bitcoin"`
+	Ethereum ethereum        `mapstructure:"ethereum"`
+	Solana   solanaWallets   `mapstructure:"solana"`
+	Cosmos   cosmosAddresses `mapstructure:"cosmos"`
+	Dogecoin utxoWallets     `mapstructure:"dogecoin"`
+	Litecoin utxoWallets     `mapstructure:"litecoin"`
 }
 
 type addresses[t any] map[string]t
@@ -37,14 +51,14 @@ type addresses[t any] map[string]t
 ////////////////////////////////////////////////////////////////////////////////
 // UTXO networks
 
-// type utxo struct {
-//   Xpubs map[string]xpub `mapstructure:"xpubs"`
-// }
-//
-// type xpub struct {
-//   Type core.UtxoWalletScheme `mapstructure:"type"`
-//   Key  string                `mapstructure:"key"`
-// }
+type utxoWallets struct {
+	Xpubs map[string]xpub `mapstructure:"xpubs"`
+}
+
+type xpub struct {
+	Type core.UtxoWalletScheme `mapstructure:"type"`
+	Key  string                `mapstructure:"key"`
+}
 
 ////////////////////////////////////////////////////////////////////////////////
 // Ethereum
@@ -56,30 +70,45 @@ type ethereum struct {
 ////////////////////////////////////////////////////////////////////////////////
 // Solana
 
-// type solana struct {
-//   Addresses addresses[core.SolanaAddress] `mapstructure:"addresses"`
-// }
+type solanaWallets struct {
+	Addresses addresses[core.SolanaAddress] `mapstructure:"addresses"`
+}
 
 ////////////////////////////////////////////////////////////////////////////////
 // Cosmos
 
-// type cosmos map[core.CosmosNetwork]addresses[core.CosmosAddress]
+type cosmosAddresses map[core.CosmosNetwork]addresses[core.CosmosAddress]
 
 ////////////////////////////////////////////////////////////////////////////////
 // Initialization
 
-func init() {
+// Load reads the config file at the given path into the global Config, and
+// loads the asset registry it points to. It must be called before anything
+// reads Config.
+func Load(path string) error {
 	v := viper.NewWithOptions(viper.KeyDelimiter("::"))
-	v.AddConfigPath(".")
-	v.SetConfigFile("config.yml")
+	v.SetConfigFile(path)
 
 	if err := v.ReadInConfig(); err != nil {
-		log.Fatal(fmt.Errorf("could not read in config: %w", err))
+		return fmt.Errorf("Could not read in config: %w", err)
+	}
+
+	var loaded config
+	if err := v.Unmarshal(&loaded, viper.DecodeHook(CustomDecoder())); err != nil {
+		return fmt.Errorf("Invalid config: %w", err)
 	}
 
-	if err := v.Unmarshal(&Config, viper.DecodeHook(CustomDecoder())); err != nil {
-		log.Fatal(fmt.Errorf("invalid config: %w", err))
+	if loaded.AssetRegistry != "" {
+		registry, err := core.LoadAssetRegistry(loaded.AssetRegistry)
+		if err != nil {
+			return err
+		}
+		core.ASSET_REGISTRY = registry
 	}
+
+	Config = loaded
+
+	return nil
 }
 
 func CustomDecoder() mapstructure.DecodeHookFunc {
@@ -104,9 +133,79 @@ func (c config) AllNetworks() []core.Network {
 	for _, evmNetwork := range c.EvmNetworks {
 		networks = append(networks, core.Network(evmNetwork))
 	}
+	for _, utxoNetwork := range c.UtxoNetworks {
+		networks = append(networks, core.Network(utxoNetwork))
+	}
+	for _, solanaNetwork := range c.SolanaNetworks {
+		networks = append(networks, core.Network(solanaNetwork))
+	}
+	for _, cosmosNetwork := range c.CosmosNetworks {
+		networks = append(networks, core.Network(cosmosNetwork))
+	}
 	return networks
 }
 
+// OwnedAddresses returns a label -> address map of everything owned on the
+// given network, in the form its indexer expects. For UTXO networks, each
+// "address" is a wallet descriptor covering every address derived from an
+// xpub.
+func (c config) OwnedAddresses(network core.Network) map[string]string {
+	owned := make(map[string]string)
+
+	switch n := network.(type) {
+	case evm.Network:
+		for label, addr := range c.Ownership.Ethereum.Addresses {
+			owned[label] = addr.Hex()
+		}
+	case solana.Network:
+		for label, addr := range c.Ownership.Solana.Addresses {
+			owned[label] = addr.String()
+		}
+	case cosmos.Network:
+		for label, addr := range c.Ownership.Cosmos[n.Name] {
+			owned[label] = addr.String()
+		}
+	case utxo.Network:
+		for label, wallet := range c.utxoWalletsFor(n).Xpubs {
+			owned[label] = utxo.Wallet{
+				Label:  label,
+				Scheme: wallet.Type,
+				Key:    wallet.Key,
+			}.Descriptor()
+		}
+	}
+
+	return owned
+}
+
+func (c config) utxoWalletsFor(network utxo.Network) utxoWallets {
+	chain := network.Chain
+	if chain == "" {
+		chain = network.Name
+	}
+
+	switch chain {
+	case "bitcoin":
+		return c.Ownership.Bitcoin
+	case "dogecoin":
+		return c.Ownership.Dogecoin
+	case "litecoin":
+		return c.Ownership.Litecoin
+	default:
+		return utxoWallets{}
+	}
+}
+
+func (c config) UtxoNetworkByName(name string) utxo.Network {
+	for _, network := range c.UtxoNetworks {
+		if network.Name == name {
+			return network
+		}
+	}
+
+	return utxo.Network{}
+}
+
 func (c config) IsMyEvmAddress(addr common.Address) bool {
 	for _, address := range c.Ownership.Ethereum.Addresses {
 		if address == addr {
@@ -120,6 +219,44 @@ func (c config) IsMyEvmAddressString(addr string) bool {
 	return c.IsMyEvmAddress(common.HexToAddress(addr))
 }
 
+func (c config) IsMySolanaAddress(addr core.SolanaAddress) bool {
+	for _, address := range c.Ownership.Solana.Addresses {
+		if address == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (c config) SolanaNetworkByName(name string) solana.Network {
+	for _, network := range c.SolanaNetworks {
+		if network.Name == name {
+			return network
+		}
+	}
+
+	return solana.Network{}
+}
+
+func (c config) IsMyCosmosAddress(network core.CosmosNetwork, addr string) bool {
+	for _, address := range c.Ownership.Cosmos[network] {
+		if address.String() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (c config) CosmosNetworkByName(name string) cosmos.Network {
+	for _, network := range c.CosmosNetworks {
+		if network.Name.String() == name {
+			return network
+		}
+	}
+
+	return cosmos.Network{}
+}
+
 func (c config) EvmNetworkByName(name string) evm.Network {
 	for _, network := range c.EvmNetworks {
 		if string(network.Name) == name {
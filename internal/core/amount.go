@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/shopspring/decimal"
@@ -55,13 +56,22 @@ func NewAmountFromDecimal(asset Asset, decimalValue decimal.Decimal) (Amount, er
 }
 
 func NewAmountFromAtomicValue(asset Asset, atomicValue uint64) Amount {
-	value := decimal.New(int64(atomicValue), -int32(asset.Decimals))
+	atomic := new(big.Int).SetUint64(atomicValue)
+	value := decimal.NewFromBigInt(atomic, -int32(asset.Decimals))
 
 	return Amount{
 		Value: value,
 		Asset: asset,
 	}
+}
 
+// NewSignedAmountFromDecimal is like NewAmountFromDecimal, but allows negative
+// values for representing outflows and net changes.
+func NewSignedAmountFromDecimal(asset Asset, decimalValue decimal.Decimal) Amount {
+	return Amount{
+		Value: decimalValue.Truncate(int32(asset.Decimals)),
+		Asset: asset,
+	}
 }
 
 func NewAmountFromAtomicDecimal(asset Asset, atomicDecimal decimal.Decimal) Amount {
@@ -77,8 +87,10 @@ func (a Amount) String() string {
 	return fmt.Sprintf("%s %s", a.Value.StringFixed(int32(a.Asset.Decimals)), a.Asset)
 }
 
+// Add combines amounts of fungible assets (see Asset.FungibleWith), keeping the
+// asset of the receiver.
 func (a Amount) Add(other Amount) (Amount, error) {
-	if a.Asset != other.Asset {
+	if !a.Asset.FungibleWith(other.Asset) {
 		return a, fmt.Errorf("Cannot add %s amount and %s amount", a.Asset, other.Asset)
 	}
 
@@ -89,7 +101,7 @@ func (a Amount) Add(other Amount) (Amount, error) {
 }
 
 func (a Amount) Sub(other Amount) (Amount, error) {
-	if a.Asset != other.Asset {
+	if !a.Asset.FungibleWith(other.Asset) {
 		return a, fmt.Errorf("Cannot subtract %s amount and %s amount", a.Asset, other.Asset)
 	}
 
@@ -105,3 +117,49 @@ func (a Amount) Neg() Amount {
 		Asset: a.Asset,
 	}
 }
+
+func (a Amount) Abs() Amount {
+	return Amount{
+		Value: a.Value.Abs(),
+		Asset: a.Asset,
+	}
+}
+
+func (a Amount) Sign() int {
+	return a.Value.Sign()
+}
+
+func (a Amount) IsZero() bool {
+	return a.Value.IsZero()
+}
+
+func (a Amount) IsPositive() bool {
+	return a.Value.IsPositive()
+}
+
+func (a Amount) IsNegative() bool {
+	return a.Value.IsNegative()
+}
+
+// Cmp returns -1, 0 or 1 if the amount is less than, equal to or greater than
+// the other amount of a fungible asset.
+func (a Amount) Cmp(other Amount) (int, error) {
+	if !a.Asset.FungibleWith(other.Asset) {
+		return 0, fmt.Errorf("Cannot compare %s amount and %s amount", a.Asset, other.Asset)
+	}
+
+	return a.Value.Cmp(other.Value), nil
+}
+
+// MulPrice converts the amount to the quote asset of the price, keeping full
+// precision. The amount must be of the base asset of the price.
+func (a Amount) MulPrice(price Price) (Amount, error) {
+	if !a.Asset.FungibleWith(price.Base) {
+		return a, fmt.Errorf("Cannot value %s amount with price of %s", a.Asset, price.Base)
+	}
+
+	return Amount{
+		Value: a.Value.Mul(price.Value),
+		Asset: price.Quote,
+	}, nil
+}
@@ -226,3 +226,108 @@ func TestSub_Mismatch(t *testing.T) {
 	expected := fmt.Sprintf("Cannot subtract %s amount and %s amount", eth, usdc)
 	assert.EqualError(t, err, expected)
 }
+
+// Large atomic values
+
+func TestFromAtomic_LargerThanInt64(t *testing.T) {
+	input := uint64(18446744073709551615)
+	amount := core.NewAmountFromAtomicValue(eth, input)
+	assert.Equal(t, "18.446744073709551615", amount.Value.String())
+}
+
+// Signed amount tests
+
+func TestSignedFromDecimal(t *testing.T) {
+	input, _ := decimal.NewFromString("-1.2345678")
+	amount := core.NewSignedAmountFromDecimal(usdc, input)
+	assert.Equal(t, "-1.234567", amount.Value.String())
+	assert.True(t, amount.IsNegative())
+	assert.Equal(t, -1, amount.Sign())
+	assert.Equal(t, "1.234567", amount.Abs().Value.String())
+}
+
+func TestSignHelpers_Zero(t *testing.T) {
+	amount := usdc.WithAtomicValue(0)
+	assert.True(t, amount.IsZero())
+	assert.False(t, amount.IsPositive())
+	assert.False(t, amount.IsNegative())
+	assert.Equal(t, 0, amount.Sign())
+}
+
+// Cmp tests
+
+func TestCmp(t *testing.T) {
+	a, _ := core.NewAmountFromDecimalString(usdc, "1.5")
+	b, _ := core.NewAmountFromDecimalString(usdc, "2")
+	result, err := a.Cmp(b)
+	assert.Nil(t, err)
+	assert.Equal(t, -1, result)
+	result, err = b.Cmp(a)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result)
+	result, err = a.Cmp(a)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result)
+}
+
+func TestCmp_Mismatch(t *testing.T) {
+	a, _ := core.NewAmountFromDecimalString(usdc, "1")
+	b, _ := core.NewAmountFromDecimalString(eth, "1")
+	_, err := a.Cmp(b)
+	expected := fmt.Sprintf("Cannot compare %s amount and %s amount", usdc, eth)
+	assert.EqualError(t, err, expected)
+}
+
+// MulPrice tests
+
+var usd core.Asset = core.FiatAsset("usd")
+
+func TestMulPrice(t *testing.T) {
+	amount, _ := core.NewAmountFromDecimalString(eth, "1.5")
+	price, err := core.NewPrice(eth, usd, decimal.RequireFromString("2300.50"))
+	assert.Nil(t, err)
+	value, err := amount.MulPrice(price)
+	assert.Nil(t, err)
+	assert.Equal(t, usd, value.Asset)
+	assert.Equal(t, "3450.75 fiat/fiat/fiat/USD/USD", value.String())
+}
+
+func TestMulPrice_FungibleBase(t *testing.T) {
+	baseEth := eth
+	baseEth.NetworkName = "base"
+	amount, _ := core.NewAmountFromDecimalString(baseEth, "2")
+	price, _ := core.NewPrice(eth, usd, decimal.NewFromInt(3000))
+	value, err := amount.MulPrice(price)
+	assert.Nil(t, err)
+	assert.Equal(t, "6000", value.Value.String())
+}
+
+func TestMulPrice_Mismatch(t *testing.T) {
+	amount, _ := core.NewAmountFromDecimalString(usdc, "1")
+	price, _ := core.NewPrice(eth, usd, decimal.NewFromInt(3000))
+	_, err := amount.MulPrice(price)
+	expected := fmt.Sprintf("Cannot value %s amount with price of %s", usdc, eth)
+	assert.EqualError(t, err, expected)
+}
+
+// Price tests
+
+func TestNewPrice_Negative(t *testing.T) {
+	_, err := core.NewPrice(eth, usd, decimal.NewFromInt(-1))
+	expected := fmt.Sprintf("Negative price ('-1') of %s in %s not allowed", eth, usd)
+	assert.EqualError(t, err, expected)
+}
+
+func TestPriceInvert(t *testing.T) {
+	price, _ := core.NewPrice(eth, usd, decimal.NewFromInt(2000))
+	inverted, err := price.Invert()
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0005 ETH/USD", inverted.String())
+}
+
+func TestFiatAsset(t *testing.T) {
+	assert.Equal(t, uint8(2), core.FiatAsset("eur").Decimals)
+	assert.Equal(t, "EUR", core.FiatAsset("eur").Symbol)
+	assert.Equal(t, uint8(0), core.FiatAsset("JPY").Decimals)
+	assert.True(t, core.FiatAsset("USD").IsFiat())
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -9,13 +11,16 @@ import (
 type AssetKind string
 
 const (
-	// UtxoNative AssetKind = "utxo_native"
+	UtxoNative AssetKind = "utxo_native"
 	EvmNative  AssetKind = "evm_native"
 	Erc20Token AssetKind = "erc20"
 	Erc721Nft  AssetKind = "erc721"
-	// SvmNative  AssetKind = "svm_native"
-	// SplToken   AssetKind = "spl_token"
-	// SplNft     AssetKind = "spl_nft"
+	Erc1155Nft AssetKind = "erc1155"
+	Fiat       AssetKind = "fiat"
+	SvmNative  AssetKind = "svm_native"
+	SplToken   AssetKind = "spl_token"
+	SplNft     AssetKind = "spl_nft"
+	CosmosCoin AssetKind = "cosmos_coin"
 )
 
 // An Asset uniquely identifies an asset across all networks. It is used in
@@ -30,6 +35,33 @@ type Asset struct {
 	Decimals    uint8       // Number of decimal places used for formatting
 }
 
+// Currencies that are not usually divided into hundredths
+var ZERO_DECIMAL_FIAT = []string{"JPY", "KRW", "VND", "CLP", "ISK"}
+
+// FiatAsset returns the asset for a fiat currency, identified by its ISO 4217
+// code (USD, EUR, ...).
+func FiatAsset(code string) Asset {
+	code = strings.ToUpper(code)
+
+	decimals := uint8(2)
+	if slices.Contains(ZERO_DECIMAL_FIAT, code) {
+		decimals = 0
+	}
+
+	return Asset{
+		NetworkKind: FiatNetworkKind,
+		NetworkName: "fiat",
+		Kind:        Fiat,
+		Identifier:  code,
+		Symbol:      code,
+		Decimals:    decimals,
+	}
+}
+
+func (a Asset) IsFiat() bool {
+	return a.Kind == Fiat
+}
+
 func (a Asset) String() string {
 	return fmt.Sprintf("%s/%s/%s/%s/%s",
 		a.NetworkKind,
@@ -40,19 +72,38 @@ func (a Asset) String() string {
 	)
 }
 
-func (a Asset) FungibleWith(other Asset) bool {
-	if a == other {
-		return true
+func (a Asset) IsNative() bool {
+	return a.Kind == EvmNative || a.Kind == UtxoNative || a.Kind == SvmNative
+}
+
+func (a Asset) IsNft() bool {
+	return a.Kind == Erc721Nft || a.Kind == Erc1155Nft || a.Kind == SplNft
+}
+
+// CanonicalID identifies the asset across networks. Assets in the registry use
+// their canonical ID, EVM native assets are identified by symbol (ETH on every
+// rollup is the same ETH), and anything else is only itself.
+func (a Asset) CanonicalID() string {
+	if registered, found := ASSET_REGISTRY.Lookup(a); found {
+		return registered.CanonicalID
 	}
 
-	bothEvm := a.NetworkKind == EvmNetworkKind && other.NetworkKind == EvmNetworkKind
-	bothNative := a.Kind == EvmNative && other.Kind == EvmNative
+	if a.NetworkKind == EvmNetworkKind && a.Kind == EvmNative {
+		if registered, found := ASSET_REGISTRY.LookupNativeSymbol(a.Symbol); found {
+			return registered.CanonicalID
+		}
+		return fmt.Sprintf("%s/%s/%s", a.NetworkKind, a.Kind, a.Symbol)
+	}
 
-	if bothEvm && bothNative {
-		return a.Symbol == other.Symbol
+	return a.String()
+}
+
+func (a Asset) FungibleWith(other Asset) bool {
+	if a == other {
+		return true
 	}
 
-	return false
+	return a.CanonicalID() == other.CanonicalID()
 }
 
 func (a Asset) WithDecimalStringValue(s string) (Amount, error) {
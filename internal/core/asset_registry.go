@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// An AssetRelation describes how a registered asset relates to the canonical
+// asset it is a member of. All members are fungible with one another.
+type AssetRelation string
+
+const (
+	NativeRelation  AssetRelation = "native"  // Issued directly on the network
+	BridgedRelation AssetRelation = "bridged" // Bridged representation of the canonical asset (USDC.e)
+	WrappedRelation AssetRelation = "wrapped" // Wrapped form of the canonical asset (WETH)
+)
+
+// A CanonicalAsset groups assets on different networks that are the same thing
+// for accounting purposes.
+type CanonicalAsset struct {
+	ID      string        `yaml:"id"`
+	Symbol  string        `yaml:"symbol"`
+	Members []AssetMember `yaml:"members"`
+}
+
+// An AssetMember identifies an asset on one network, either by its identifier
+// (token contract, mint, denom) or as the network's native asset.
+type AssetMember struct {
+	Network    string        `yaml:"network"`
+	Identifier string        `yaml:"identifier"`
+	Native     bool          `yaml:"native"`
+	Relation   AssetRelation `yaml:"relation"`
+}
+
+// A RegisteredAsset is the result of looking up an asset in the registry.
+type RegisteredAsset struct {
+	CanonicalID string
+	Symbol      string
+	Relation    AssetRelation
+}
+
+type AssetRegistry struct {
+	assets        []CanonicalAsset
+	members       map[string]RegisteredAsset
+	nativeSymbols map[string]RegisteredAsset // For native assets on networks not listed
+}
+
+// The registry used by Asset.FungibleWith and Asset.CanonicalID, empty unless
+// one is configured
+var ASSET_REGISTRY = NewAssetRegistry()
+
+func NewAssetRegistry() *AssetRegistry {
+	return &AssetRegistry{
+		assets:        make([]CanonicalAsset, 0),
+		members:       make(map[string]RegisteredAsset),
+		nativeSymbols: make(map[string]RegisteredAsset),
+	}
+}
+
+// LoadAssetRegistry reads a registry from a YAML or JSON file.
+func LoadAssetRegistry(path string) (*AssetRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read asset registry %s: %w", path, err)
+	}
+
+	registry, err := ParseAssetRegistry(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid asset registry %s: %w", path, err)
+	}
+
+	return registry, nil
+}
+
+func ParseAssetRegistry(data []byte) (*AssetRegistry, error) {
+	// JSON is valid YAML, so this handles both
+	var file struct {
+		Assets []CanonicalAsset `yaml:"assets"`
+	}
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewAssetRegistry()
+	for _, asset := range file.Assets {
+		err := registry.Add(asset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+func (r *AssetRegistry) Add(canonical CanonicalAsset) error {
+	if canonical.ID == "" {
+		return fmt.Errorf("Canonical asset is missing an ID")
+	}
+
+	for _, existing := range r.assets {
+		if existing.ID == canonical.ID {
+			return fmt.Errorf("Duplicate canonical asset '%s'", canonical.ID)
+		}
+	}
+
+	for _, member := range canonical.Members {
+		relation := member.Relation
+		switch relation {
+		case "":
+			relation = NativeRelation
+		case NativeRelation, BridgedRelation, WrappedRelation:
+		default:
+			return fmt.Errorf("Unknown relation '%s' for %s in '%s'", relation, member.Network, canonical.ID)
+		}
+
+		if member.Network == "" || (member.Identifier == "" && !member.Native) {
+			return fmt.Errorf("Member of '%s' needs a network and either an identifier or native: true", canonical.ID)
+		}
+
+		key := memberKey(member.Network, member.Identifier, member.Native)
+		if existing, found := r.members[key]; found {
+			return fmt.Errorf("%s is in both '%s' and '%s'", key, existing.CanonicalID, canonical.ID)
+		}
+
+		registered := RegisteredAsset{
+			CanonicalID: canonical.ID,
+			Symbol:      canonical.Symbol,
+			Relation:    relation,
+		}
+		r.members[key] = registered
+		if member.Native && canonical.Symbol != "" {
+			r.nativeSymbols[canonical.Symbol] = registered
+		}
+	}
+
+	r.assets = append(r.assets, canonical)
+
+	return nil
+}
+
+func (r *AssetRegistry) Lookup(asset Asset) (RegisteredAsset, bool) {
+	registered, found := r.members[memberKey(asset.NetworkName, asset.Identifier, asset.IsNative())]
+	return registered, found
+}
+
+// LookupNativeSymbol finds the canonical asset registered as native on some
+// network with the given symbol.
+func (r *AssetRegistry) LookupNativeSymbol(symbol string) (RegisteredAsset, bool) {
+	registered, found := r.nativeSymbols[symbol]
+	return registered, found
+}
+
+func (r *AssetRegistry) CanonicalAssets() []CanonicalAsset {
+	return r.assets
+}
+
+func memberKey(network, identifier string, native bool) string {
+	if native {
+		return strings.ToLower(network) + "/native"
+	}
+	return strings.ToLower(network) + "/" + strings.ToLower(identifier)
+}
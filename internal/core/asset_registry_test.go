@@ -0,0 +1,145 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/stretchr/testify/assert"
+)
+
+const TEST_REGISTRY = `
+assets:
+  - id: usdc
+    symbol: USDC
+    members:
+      - network: ethereum
+        identifier: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+      - network: polygon
+        identifier: "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+        relation: bridged
+  - id: eth
+    symbol: ETH
+    members:
+      - network: ethereum
+        native: true
+      - network: ethereum
+        identifier: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+        relation: wrapped
+`
+
+var usdcE core.Asset = core.Asset{
+	NetworkKind: core.EvmNetworkKind,
+	NetworkName: evm.Polygon.String(),
+	Kind:        core.Erc20Token,
+	Identifier:  common.HexToAddress("0x2791bca1f2de4661ed88a30c99a7a9449aa84174").String(),
+	Symbol:      "USDC.e",
+	Decimals:    6,
+}
+
+var weth core.Asset = core.Asset{
+	NetworkKind: core.EvmNetworkKind,
+	NetworkName: evm.Ethereum.String(),
+	Kind:        core.Erc20Token,
+	Identifier:  common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2").String(),
+	Symbol:      "WETH",
+	Decimals:    18,
+}
+
+var baseEth core.Asset = core.Asset{
+	NetworkKind: core.EvmNetworkKind,
+	NetworkName: evm.Base.String(),
+	Kind:        core.EvmNative,
+	Identifier:  common.Address{}.String(),
+	Symbol:      "ETH",
+	Decimals:    18,
+}
+
+func useTestRegistry(t *testing.T) {
+	registry, err := core.ParseAssetRegistry([]byte(TEST_REGISTRY))
+	assert.Nil(t, err)
+
+	previous := core.ASSET_REGISTRY
+	core.ASSET_REGISTRY = registry
+	t.Cleanup(func() {
+		core.ASSET_REGISTRY = previous
+	})
+}
+
+// Registry tests
+
+func TestRegistry_Lookup(t *testing.T) {
+	useTestRegistry(t)
+
+	registered, found := core.ASSET_REGISTRY.Lookup(usdcE)
+	assert.True(t, found)
+	assert.Equal(t, core.RegisteredAsset{CanonicalID: "usdc", Symbol: "USDC", Relation: core.BridgedRelation}, registered)
+
+	registered, found = core.ASSET_REGISTRY.Lookup(usdc)
+	assert.True(t, found)
+	assert.Equal(t, core.NativeRelation, registered.Relation)
+
+	registered, found = core.ASSET_REGISTRY.Lookup(weth)
+	assert.True(t, found)
+	assert.Equal(t, core.WrappedRelation, registered.Relation)
+
+	_, found = core.ASSET_REGISTRY.Lookup(zeroDecimalAsset)
+	assert.False(t, found)
+}
+
+func TestRegistry_Fungible(t *testing.T) {
+	assert.False(t, usdc.FungibleWith(usdcE))
+	assert.False(t, eth.FungibleWith(weth))
+	assert.True(t, eth.FungibleWith(baseEth))
+
+	useTestRegistry(t)
+
+	assert.True(t, usdc.FungibleWith(usdcE))
+	assert.True(t, eth.FungibleWith(weth))
+	assert.True(t, weth.FungibleWith(baseEth)) // Via the native symbol
+	assert.False(t, usdc.FungibleWith(weth))
+	assert.Equal(t, "usdc", usdcE.CanonicalID())
+}
+
+func TestRegistry_AddFungible(t *testing.T) {
+	useTestRegistry(t)
+
+	a, _ := core.NewAmountFromDecimalString(usdc, "1.5")
+	b, _ := core.NewAmountFromDecimalString(usdcE, "2")
+	result, err := a.Add(b)
+	assert.Nil(t, err)
+	assert.Equal(t, usdc, result.Asset)
+	assert.Equal(t, "3.5", result.Value.String())
+
+	cmp, err := b.Cmp(a)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cmp)
+}
+
+func TestRegistry_Invalid(t *testing.T) {
+	_, err := core.ParseAssetRegistry([]byte(`
+assets:
+  - id: usdc
+    members:
+      - network: ethereum
+        identifier: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+  - id: usdc2
+    members:
+      - network: ethereum
+        identifier: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+`))
+	assert.ErrorContains(t, err, "is in both 'usdc' and 'usdc2'")
+
+	_, err = core.ParseAssetRegistry([]byte(`{"assets": [{"id": "x", "members": [{"network": "ethereum", "native": true, "relation": "cousin"}]}]}`))
+	assert.ErrorContains(t, err, "Unknown relation 'cousin'")
+
+	_, err = core.ParseAssetRegistry([]byte(`{"assets": [{"id": "x", "members": [{"network": "ethereum"}]}]}`))
+	assert.ErrorContains(t, err, "needs a network and either an identifier or native: true")
+}
+
+func TestRegistry_RepoFile(t *testing.T) {
+	registry, err := core.LoadAssetRegistry("../../assets.yml")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, registry.CanonicalAssets())
+}
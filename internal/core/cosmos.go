@@ -0,0 +1,16 @@
+package core
+
+// A CosmosNetwork is the common name of a Cosmos SDK chain (cosmoshub,
+// osmosis, ...).
+type CosmosNetwork string
+
+// A CosmosAddress is a bech32 account address, with the prefix of its chain.
+type CosmosAddress string
+
+func (n CosmosNetwork) String() string {
+	return string(n)
+}
+
+func (a CosmosAddress) String() string {
+	return string(a)
+}
@@ -3,10 +3,11 @@ package core
 type NetworkKind string
 
 const (
-	EvmNetworkKind NetworkKind = "evm"
-	// UtxoNetworkKind   NetworkKind = "utxo"
-	// SolanaNetworkKind NetworkKind = "solana"
-	// CosmosNetworkKind NetworkKind = "cosmos"
+	EvmNetworkKind    NetworkKind = "evm"
+	FiatNetworkKind   NetworkKind = "fiat"
+	UtxoNetworkKind   NetworkKind = "utxo"
+	SolanaNetworkKind NetworkKind = "solana"
+	CosmosNetworkKind NetworkKind = "cosmos"
 )
 
 func (n NetworkKind) String() string {
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// A Price is the amount of the quote asset that one unit of the base asset is
+// worth, like 2300.50 USD per ETH.
+type Price struct {
+	Base  Asset
+	Quote Asset
+	Value decimal.Decimal
+}
+
+func NewPrice(base, quote Asset, value decimal.Decimal) (Price, error) {
+	if value.LessThan(decimal.Zero) {
+		return Price{}, fmt.Errorf("Negative price ('%s') of %s in %s not allowed", value.String(), base, quote)
+	}
+
+	return Price{
+		Base:  base,
+		Quote: quote,
+		Value: value,
+	}, nil
+}
+
+func (p Price) String() string {
+	return fmt.Sprintf("%s %s/%s", p.Value.String(), p.Quote.Symbol, p.Base.Symbol)
+}
+
+// Invert returns the price of the quote asset in terms of the base asset.
+func (p Price) Invert() (Price, error) {
+	if p.Value.IsZero() {
+		return Price{}, fmt.Errorf("Cannot invert zero price of %s in %s", p.Base, p.Quote)
+	}
+
+	return Price{
+		Base:  p.Quote,
+		Quote: p.Base,
+		Value: decimal.NewFromInt(1).Div(p.Value),
+	}, nil
+}
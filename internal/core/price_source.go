@@ -0,0 +1,15 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+var PRICE_NOT_FOUND = errors.New("price not found")
+
+// A PriceSource looks up the historical price of one unit of an asset in a fiat
+// currency (see FiatAsset). Sources return an error wrapping PRICE_NOT_FOUND when
+// they have no data for the asset, so they can be chained.
+type PriceSource interface {
+	PriceAt(asset Asset, fiat Asset, at time.Time) (Price, error)
+}
@@ -0,0 +1,8 @@
+package core
+
+// A SolanaAddress is the base58-encoded public key of a Solana account.
+type SolanaAddress string
+
+func (a SolanaAddress) String() string {
+	return string(a)
+}
@@ -0,0 +1,11 @@
+package core
+
+// A UtxoWalletScheme is the kind of address a UTXO wallet derives from its
+// extended public key.
+type UtxoWalletScheme string
+
+const (
+	P2pkh      UtxoWalletScheme = "p2pkh"       // Legacy addresses (1...)
+	P2shP2wpkh UtxoWalletScheme = "p2sh-p2wpkh" // Nested segwit addresses (3...)
+	P2wpkh     UtxoWalletScheme = "p2wpkh"      // Native segwit addresses (bc1q...)
+)
@@ -0,0 +1,245 @@
+package cosmos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+const LCD_RPS = 5
+const TX_SEARCH_PAGE_SIZE = 100
+
+// Queries used to find every transaction involving an address. Rewards and
+// IBC sends are signed by the address, and receives (including over IBC) emit
+// a transfer to it.
+var TX_SEARCH_QUERIES = []string{
+	"message.sender='%s'",
+	"transfer.recipient='%s'",
+}
+
+type Client struct {
+	Network  Network // The network the client is for
+	http     *http.Client
+	throttle <-chan time.Time
+
+	// Chains before SDK v0.50 search with `events` instead of `query`
+	legacySearch   bool
+	legacySearchMu sync.Mutex
+}
+
+type txSearchResponse struct {
+	TxResponses []TxResponse `json:"tx_responses"`
+	Total       string       `json:"total"`
+}
+
+type statusError struct {
+	code int
+	body string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("Unexpected status %d: %s", e.code, e.body)
+}
+
+func (e statusError) badRequest() bool {
+	return e.code >= 400 && e.code < 500 && e.code != http.StatusTooManyRequests
+}
+
+func NewClient(network Network) (*Client, error) {
+	if len(network.LCDs) == 0 {
+		return nil, fmt.Errorf("No LCDs configured for %s", network.Name)
+	}
+
+	rps := network.RPS
+	if rps == 0 {
+		rps = LCD_RPS
+	}
+
+	return &Client{
+		Network:  network,
+		http:     &http.Client{Timeout: 30 * time.Second},
+		throttle: time.Tick(time.Second / time.Duration(rps)),
+	}, nil
+}
+
+func NewIndexer(network Network) (core.Indexer, error) {
+	return NewClient(network)
+}
+
+func (c *Client) LatestBlock() (uint64, error) {
+	var latest struct {
+		Block struct {
+			Header struct {
+				Height string `json:"height"`
+			} `json:"header"`
+		} `json:"block"`
+	}
+
+	err := c.get("/cosmos/base/tendermint/v1beta1/blocks/latest", &latest)
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := strconv.ParseUint(latest.Block.Header.Height, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid latest height from %s: %w", c.Network.Name, err)
+	}
+
+	return height, nil
+}
+
+func (c *Client) GetTransaction(hash string) (*TxResponse, error) {
+	var result struct {
+		TxResponse *TxResponse `json:"tx_response"`
+	}
+
+	err := c.get("/cosmos/tx/v1beta1/txs/"+hash, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.TxResponse == nil {
+		return nil, fmt.Errorf("Transaction %s not found on %s", hash, c.Network.Name)
+	}
+
+	return result.TxResponse, nil
+}
+
+// SearchTransactions returns a page of transactions matching an event query,
+// newest first. Pages start at 1.
+func (c *Client) SearchTransactions(query string, page int) ([]TxResponse, error) {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(TX_SEARCH_PAGE_SIZE))
+	params.Set("order_by", "ORDER_BY_DESC")
+
+	c.legacySearchMu.Lock()
+	legacy := c.legacySearch
+	c.legacySearchMu.Unlock()
+
+	var result txSearchResponse
+
+	if !legacy {
+		params.Set("query", query)
+		err := c.get("/cosmos/tx/v1beta1/txs?"+params.Encode(), &result)
+
+		var statusErr statusError
+		if err == nil || !errors.As(err, &statusErr) || !statusErr.badRequest() {
+			return result.TxResponses, err
+		}
+
+		util.Debugf("Falling back to legacy tx search on %s: %s\n", c.Network.Name, err.Error())
+		c.legacySearchMu.Lock()
+		c.legacySearch = true
+		c.legacySearchMu.Unlock()
+		params.Del("query")
+	}
+
+	params.Set("events", query)
+	err := c.get("/cosmos/tx/v1beta1/txs?"+params.Encode(), &result)
+	return result.TxResponses, err
+}
+
+// GetAllTransactionHashes pages through each search query for the address,
+// newest first, stopping once it is past the start block.
+func (c *Client) GetAllTransactionHashes(address string, startBlock, endBlock *int) ([]string, error) {
+	hashLists := make([][]string, 0, len(TX_SEARCH_QUERIES))
+
+	for _, queryFormat := range TX_SEARCH_QUERIES {
+		query := fmt.Sprintf(queryFormat, address)
+		hashes := make([]string, 0)
+
+	pages:
+		for page := 1; ; page++ {
+			txs, err := c.SearchTransactions(query, page)
+			if err != nil {
+				return nil, fmt.Errorf("Could not search %s: %w", query, err)
+			}
+
+			for _, tx := range txs {
+				height := tx.BlockHeight()
+				if endBlock != nil && height > *endBlock {
+					continue
+				}
+				if startBlock != nil && height < *startBlock {
+					break pages
+				}
+				hashes = append(hashes, tx.TxHash)
+			}
+
+			if len(txs) < TX_SEARCH_PAGE_SIZE {
+				break
+			}
+		}
+
+		hashLists = append(hashLists, hashes)
+	}
+
+	return util.UniqueItems(hashLists...), nil
+}
+
+func (c *Client) NativeAsset() (core.Asset, error) {
+	return c.Network.NativeAsset(), nil
+}
+
+func (c *Client) OpenTransactionInExplorer(hash string, wait ...bool) {
+	c.Network.OpenTransactionInExplorer(hash, wait...)
+}
+
+// Tries each LCD in turn, returning the first successful result
+func (c *Client) get(path string, result any) error {
+	errs := make([]error, 0)
+
+	for _, lcd := range c.Network.LCDs {
+		err := c.getFrom(strings.TrimSuffix(lcd, "/"), path, result)
+		if err == nil {
+			return nil
+		}
+
+		// The request itself is bad, so other LCDs won't do any better
+		var statusErr statusError
+		if errors.As(err, &statusErr) && statusErr.badRequest() {
+			return err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", lcd, err))
+	}
+
+	return fmt.Errorf("Could not get %s on %s: %w", path, c.Network.Name, errors.Join(errs...))
+}
+
+func (c *Client) getFrom(lcd, path string, result any) error {
+	<-c.throttle
+
+	util.Debugf("GET %s%s\n", lcd, path)
+	resp, err := c.http.Get(lcd + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError{resp.StatusCode, strings.TrimSpace(string(body))}
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("Could not decode response from %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package cosmos_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/cosmos"
+	"github.com/stretchr/testify/assert"
+)
+
+const ME = "cosmos1me"
+const OSMO_ON_HUB = "ibc/14F9BC3E44B8A9C1BE1FB08980FAB87034C9905EF17CF2F5008FC085218811CC"
+
+var NETWORK = cosmos.Network{
+	Name:              "cosmoshub",
+	ChainID:           "cosmoshub-4",
+	NativeDenom:       "uatom",
+	NativeAssetSymbol: "ATOM",
+	Denoms: map[string]cosmos.Denom{
+		strings.ToLower(OSMO_ON_HUB): {Symbol: "OSMO", Decimals: 6},
+	},
+}
+
+// A stand-in for an LCD, serving transactions recorded in testdata
+type fixtureLCD struct {
+	t        *testing.T
+	legacy   bool // Only supports `events` for tx search, like SDK < v0.50
+	txs      map[string]json.RawMessage
+	searches map[string][]string
+}
+
+func newFixtureLCD(t *testing.T, legacy bool) *httptest.Server {
+	var txs []json.RawMessage
+	readFixture(t, "txs.json", &txs)
+
+	lcd := fixtureLCD{t: t, legacy: legacy, txs: make(map[string]json.RawMessage)}
+	for _, tx := range txs {
+		var hash struct {
+			TxHash string `json:"txhash"`
+		}
+		assert.Nil(t, json.Unmarshal(tx, &hash))
+		lcd.txs[hash.TxHash] = tx
+	}
+	readFixture(t, "search.json", &lcd.searches)
+
+	return httptest.NewServer(lcd)
+}
+
+func readFixture(t *testing.T, name string, result any) {
+	data, err := os.ReadFile("testdata/" + name)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(data, result))
+}
+
+func (l fixtureLCD) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/cosmos/base/tendermint/v1beta1/blocks/latest":
+		data, err := os.ReadFile("testdata/latest_block.json")
+		assert.Nil(l.t, err)
+		_, _ = w.Write(data)
+
+	case r.URL.Path == "/cosmos/tx/v1beta1/txs":
+		query := r.URL.Query().Get("query")
+		if l.legacy {
+			query = r.URL.Query().Get("events")
+		}
+		if query == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":3,"message":"must declare at least one event to search"}`))
+			return
+		}
+		assert.Equal(l.t, "ORDER_BY_DESC", r.URL.Query().Get("order_by"))
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		responses := make([]json.RawMessage, 0)
+		if page == 1 {
+			for _, hash := range l.searches[query] {
+				responses = append(responses, l.txs[hash])
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"tx_responses": responses,
+			"total":        strconv.Itoa(len(responses)),
+		})
+
+	case strings.HasPrefix(r.URL.Path, "/cosmos/tx/v1beta1/txs/"):
+		tx, found := l.txs[strings.TrimPrefix(r.URL.Path, "/cosmos/tx/v1beta1/txs/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":5,"message":"tx not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"tx_response": tx})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestClient(t *testing.T, url string) *cosmos.Client {
+	network := NETWORK
+	network.LCDs = []string{url}
+	network.RPS = 1000
+
+	client, err := cosmos.NewClient(network)
+	assert.Nil(t, err)
+	return client
+}
+
+func getEntries(t *testing.T, client *cosmos.Client, hash string) ([]cosmos.Entry, []string) {
+	tx, err := client.GetTransaction(hash)
+	assert.Nil(t, err)
+
+	entries, unsupported, err := cosmos.DecodeEntries(NETWORK, tx)
+	assert.Nil(t, err)
+	return entries, unsupported
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Indexer tests
+
+func TestGetAllTransactionHashes(t *testing.T) {
+	for _, legacy := range []bool{false, true} {
+		server := newFixtureLCD(t, legacy)
+		client := newTestClient(t, server.URL)
+
+		block, err := client.LatestBlock()
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(700), block)
+
+		hashes, err := client.GetAllTransactionHashes(ME, nil, nil)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"H1", "H2", "H3", "H4", "H5", "H6", "H7", "H8"}, hashes)
+
+		start, end := 250, 550
+		hashes, err = client.GetAllTransactionHashes(ME, &start, &end)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"H3", "H4", "H5", "H7", "H8"}, hashes)
+
+		server.Close()
+	}
+}
+
+func TestGetTransactionNotFound(t *testing.T) {
+	server := newFixtureLCD(t, false)
+	defer server.Close()
+
+	_, err := newTestClient(t, server.URL).GetTransaction("nope")
+	assert.NotNil(t, err)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Decoding tests
+
+func TestDecodeFailed(t *testing.T) {
+	server := newFixtureLCD(t, false)
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	entries, _ := getEntries(t, client, "H1")
+	assert.Empty(t, entries)
+
+	tx, err := client.GetTransaction("H1")
+	assert.Nil(t, err)
+	fees, payer, err := cosmos.Fee(NETWORK, tx)
+	assert.Nil(t, err)
+	assert.Equal(t, ME, payer)
+	assert.Len(t, fees, 1)
+	assert.Equal(t, "0.001", fees[0].Value.String())
+}
+
+func TestDecodeSend(t *testing.T) {
+	server := newFixtureLCD(t, false)
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	entries, unsupported := getEntries(t, client, "H2")
+	assert.Equal(t, []string{"/cosmos.gov.v1beta1.MsgVote"}, unsupported)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, cosmos.Transfer, entries[0].Kind)
+	assert.Equal(t, ME, entries[0].From)
+	assert.Equal(t, "cosmos1friend", entries[0].To)
+	assert.Equal(t, "1", entries[0].Amount.Value.String())
+	assert.Equal(t, "ATOM", entries[0].Amount.Asset.Symbol)
+
+	entries, _ = getEntries(t, client, "H8")
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "OSMO", entries[1].Amount.Asset.Symbol)
+	assert.Equal(t, "0.000042", entries[1].Amount.Value.String())
+}
+
+func TestDecodeStaking(t *testing.T) {
+	server := newFixtureLCD(t, false)
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	// Delegating also withdraws pending rewards (events in logs)
+	entries, _ := getEntries(t, client, "H3")
+	assert.Len(t, entries, 2)
+	assert.Equal(t, cosmos.Delegate, entries[0].Kind)
+	assert.Equal(t, "10", entries[0].Amount.Value.String())
+	assert.Equal(t, "cosmosvaloper1val", entries[0].To)
+	assert.Equal(t, cosmos.StakingReward, entries[1].Kind)
+	assert.Equal(t, ME, entries[1].To)
+	assert.Equal(t, "0.001", entries[1].Amount.Value.String())
+
+	// Rewards from tx-level events (SDK v0.50+)
+	entries, _ = getEntries(t, client, "H4")
+	assert.Len(t, entries, 1)
+	assert.Equal(t, cosmos.StakingReward, entries[0].Kind)
+	assert.Equal(t, "0.123456", entries[0].Amount.Value.String())
+}
+
+func TestDecodeIbc(t *testing.T) {
+	server := newFixtureLCD(t, false)
+	defer server.Close()
+	client := newTestClient(t, server.URL)
+
+	entries, _ := getEntries(t, client, "H5")
+	assert.Len(t, entries, 1)
+	assert.Equal(t, cosmos.BridgeOut, entries[0].Kind)
+	assert.Equal(t, "osmo1me", entries[0].To)
+	assert.Equal(t, "channel-141", entries[0].Channel)
+	assert.Equal(t, "3", entries[0].Amount.Value.String())
+
+	// Foreign tokens arrive as an ibc/ voucher
+	entries, unsupported := getEntries(t, client, "H6")
+	assert.Equal(t, []string{"/ibc.core.client.v1.MsgUpdateClient"}, unsupported)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, cosmos.BridgeIn, entries[0].Kind)
+	assert.Equal(t, OSMO_ON_HUB, entries[0].Amount.Asset.Identifier)
+	assert.Equal(t, "OSMO", entries[0].Amount.Asset.Symbol)
+	assert.Equal(t, ME, entries[0].To)
+
+	// Native tokens returning home are unwrapped
+	entries, _ = getEntries(t, client, "H7")
+	assert.Len(t, entries, 1)
+	assert.Equal(t, NETWORK.NativeAsset(), entries[0].Amount.Asset)
+	assert.Equal(t, "1", entries[0].Amount.Value.String())
+}
+
+func TestParseCoins(t *testing.T) {
+	coins, err := cosmos.ParseCoins("1000uatom," + "25" + OSMO_ON_HUB)
+	assert.Nil(t, err)
+	assert.Equal(t, []cosmos.Coin{
+		{Denom: "uatom", Amount: "1000"},
+		{Denom: OSMO_ON_HUB, Amount: "25"},
+	}, coins)
+
+	coins, err = cosmos.ParseCoins("")
+	assert.Nil(t, err)
+	assert.Empty(t, coins)
+
+	_, err = cosmos.ParseCoins("uatom")
+	assert.NotNil(t, err)
+}
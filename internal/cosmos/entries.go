@@ -0,0 +1,307 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+const (
+	MSG_SEND                      = "/cosmos.bank.v1beta1.MsgSend"
+	MSG_DELEGATE                  = "/cosmos.staking.v1beta1.MsgDelegate"
+	MSG_UNDELEGATE                = "/cosmos.staking.v1beta1.MsgUndelegate"
+	MSG_WITHDRAW_DELEGATOR_REWARD = "/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward"
+	MSG_TRANSFER                  = "/ibc.applications.transfer.v1.MsgTransfer"
+	MSG_RECV_PACKET               = "/ibc.core.channel.v1.MsgRecvPacket"
+)
+
+type EntryKind string
+
+const (
+	Transfer      EntryKind = "transfer"       // From sends to To
+	Delegate      EntryKind = "delegate"       // From stakes with validator To
+	Undelegate    EntryKind = "undelegate"     // To unstakes from validator From
+	StakingReward EntryKind = "staking-reward" // To claims rewards from validator From
+	BridgeOut     EntryKind = "bridge-out"     // From sends over IBC to To on another chain
+	BridgeIn      EntryKind = "bridge-in"      // To receives over IBC from From on another chain
+)
+
+// An Entry is a single movement of value decoded from a message.
+type Entry struct {
+	Kind    EntryKind
+	Hash    string
+	Time    time.Time
+	Amount  core.Amount
+	From    string
+	To      string
+	Channel string // IBC channel on this chain, for bridge entries
+}
+
+type typedMessage struct {
+	Type string `json:"@type"`
+}
+
+type msgSend struct {
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	Amount      []Coin `json:"amount"`
+}
+
+type msgDelegation struct {
+	DelegatorAddress string `json:"delegator_address"`
+	ValidatorAddress string `json:"validator_address"`
+	Amount           *Coin  `json:"amount"`
+}
+
+type msgTransfer struct {
+	SourcePort    string `json:"source_port"`
+	SourceChannel string `json:"source_channel"`
+	Token         Coin   `json:"token"`
+	Sender        string `json:"sender"`
+	Receiver      string `json:"receiver"`
+}
+
+type msgRecvPacket struct {
+	Packet struct {
+		SourcePort         string `json:"source_port"`
+		SourceChannel      string `json:"source_channel"`
+		DestinationPort    string `json:"destination_port"`
+		DestinationChannel string `json:"destination_channel"`
+		Data               string `json:"data"` // base64 JSON of the packet data
+	} `json:"packet"`
+}
+
+type fungibleTokenPacketData struct {
+	Denom    string `json:"denom"`
+	Amount   string `json:"amount"`
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+}
+
+// Fee returns the fee paid for a transaction and who paid it (the explicit
+// payer, or the signer of the first message).
+func Fee(network Network, tx *TxResponse) ([]core.Amount, string, error) {
+	fees := make([]core.Amount, 0)
+	for _, coin := range tx.Tx.AuthInfo.Fee.Amount {
+		if coin.IsZero() {
+			continue
+		}
+		amount, err := coin.ToAmount(network)
+		if err != nil {
+			return nil, "", err
+		}
+		fees = append(fees, amount)
+	}
+
+	payer := tx.Tx.AuthInfo.Fee.Payer
+	if payer == "" && len(tx.Tx.Body.Messages) > 0 {
+		payer = messageSigner(tx.Tx.Body.Messages[0])
+	}
+
+	return fees, payer, nil
+}
+
+// DecodeEntries decodes the supported messages of a successful transaction.
+// Unsupported messages are skipped, and returned by type so callers can
+// report them.
+func DecodeEntries(network Network, tx *TxResponse) ([]Entry, []string, error) {
+	entries := make([]Entry, 0)
+	unsupported := make([]string, 0)
+
+	if !tx.Success() {
+		return entries, unsupported, nil
+	}
+
+	timestamp, err := tx.Time()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	add := func(kind EntryKind, coin Coin, from, to, channel string) error {
+		if coin.IsZero() {
+			return nil
+		}
+		amount, err := coin.ToAmount(network)
+		if err != nil {
+			return fmt.Errorf("Invalid amount in %s: %w", tx.TxHash, err)
+		}
+		entries = append(entries, Entry{
+			Kind:    kind,
+			Hash:    tx.TxHash,
+			Time:    timestamp,
+			Amount:  amount,
+			From:    from,
+			To:      to,
+			Channel: channel,
+		})
+		return nil
+	}
+
+	for i, raw := range tx.Tx.Body.Messages {
+		var typed typedMessage
+		err := json.Unmarshal(raw, &typed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid message %d in %s: %w", i, tx.TxHash, err)
+		}
+
+		switch typed.Type {
+		case MSG_SEND:
+			var msg msgSend
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				return nil, nil, fmt.Errorf("Invalid MsgSend in %s: %w", tx.TxHash, err)
+			}
+			for _, coin := range msg.Amount {
+				if err := add(Transfer, coin, msg.FromAddress, msg.ToAddress, ""); err != nil {
+					return nil, nil, err
+				}
+			}
+
+		case MSG_DELEGATE, MSG_UNDELEGATE, MSG_WITHDRAW_DELEGATOR_REWARD:
+			var msg msgDelegation
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				return nil, nil, fmt.Errorf("Invalid %s in %s: %w", typed.Type, tx.TxHash, err)
+			}
+
+			if msg.Amount != nil {
+				if typed.Type == MSG_DELEGATE {
+					err = add(Delegate, *msg.Amount, msg.DelegatorAddress, msg.ValidatorAddress, "")
+				} else if typed.Type == MSG_UNDELEGATE {
+					err = add(Undelegate, *msg.Amount, msg.ValidatorAddress, msg.DelegatorAddress, "")
+				}
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+
+			// Delegating and undelegating also withdraw any pending rewards, so
+			// rewards come from the events of all three messages.
+			for _, event := range tx.messageEvents(i) {
+				if event.Type != "withdraw_rewards" {
+					continue
+				}
+				if delegator := event.Attribute("delegator"); delegator != "" && delegator != msg.DelegatorAddress {
+					continue
+				}
+
+				coins, err := ParseCoins(event.Attribute("amount"))
+				if err != nil {
+					return nil, nil, fmt.Errorf("Invalid reward in %s: %w", tx.TxHash, err)
+				}
+				for _, coin := range coins {
+					if err := add(StakingReward, coin, event.Attribute("validator"), msg.DelegatorAddress, ""); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+
+		case MSG_TRANSFER:
+			var msg msgTransfer
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				return nil, nil, fmt.Errorf("Invalid MsgTransfer in %s: %w", tx.TxHash, err)
+			}
+			if err := add(BridgeOut, msg.Token, msg.Sender, msg.Receiver, msg.SourceChannel); err != nil {
+				return nil, nil, err
+			}
+
+		case MSG_RECV_PACKET:
+			var msg msgRecvPacket
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				return nil, nil, fmt.Errorf("Invalid MsgRecvPacket in %s: %w", tx.TxHash, err)
+			}
+
+			data, err := base64.StdEncoding.DecodeString(msg.Packet.Data)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid packet data in %s: %w", tx.TxHash, err)
+			}
+
+			var packet fungibleTokenPacketData
+			if err := json.Unmarshal(data, &packet); err != nil || packet.Denom == "" {
+				// Not a token transfer (ICA, oracle, etc)
+				continue
+			}
+
+			if !packetSucceeded(tx.messageEvents(i)) {
+				continue
+			}
+
+			denom := receivedDenom(
+				msg.Packet.SourcePort,
+				msg.Packet.SourceChannel,
+				msg.Packet.DestinationPort,
+				msg.Packet.DestinationChannel,
+				packet.Denom,
+			)
+			coin := Coin{Denom: denom, Amount: packet.Amount}
+			if err := add(BridgeIn, coin, packet.Sender, packet.Receiver, msg.Packet.DestinationChannel); err != nil {
+				return nil, nil, err
+			}
+
+		default:
+			unsupported = append(unsupported, typed.Type)
+		}
+	}
+
+	return entries, unsupported, nil
+}
+
+// A packet can be received in a successful transaction but still fail, in
+// which case the funds are refunded on the source chain.
+func packetSucceeded(events []Event) bool {
+	for _, event := range events {
+		if event.Type != "fungible_token_packet" {
+			continue
+		}
+		success := event.Attribute("success")
+		if success == "false" {
+			return false
+		}
+		if event.Attribute("error") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// receivedDenom works out the denom of tokens received over IBC, following
+// ICS-20: tokens returning to the chain they came from are unwrapped, and
+// anything else becomes an ibc/<hash> voucher of its full trace.
+func receivedDenom(sourcePort, sourceChannel, destPort, destChannel, denom string) string {
+	prefix := sourcePort + "/" + sourceChannel + "/"
+
+	var trace string
+	if strings.HasPrefix(denom, prefix) {
+		trace = strings.TrimPrefix(denom, prefix)
+	} else {
+		trace = destPort + "/" + destChannel + "/" + denom
+	}
+
+	if !strings.Contains(trace, "/") {
+		return trace
+	}
+
+	hash := sha256.Sum256([]byte(trace))
+	return "ibc/" + strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+func messageSigner(raw json.RawMessage) string {
+	var signers struct {
+		FromAddress      string `json:"from_address"`
+		DelegatorAddress string `json:"delegator_address"`
+		Sender           string `json:"sender"`
+		Signer           string `json:"signer"`
+	}
+	_ = json.Unmarshal(raw, &signers)
+
+	for _, addr := range []string{signers.FromAddress, signers.DelegatorAddress, signers.Sender, signers.Signer} {
+		if addr != "" {
+			return addr
+		}
+	}
+	return ""
+}
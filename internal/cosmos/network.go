@@ -0,0 +1,99 @@
+package cosmos
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+// Most Cosmos SDK coins use micro-units (uatom, uosmo, ...)
+const DEFAULT_DECIMALS = 6
+
+type Denom struct {
+	Symbol   string `mapstructure:"symbol"`
+	Decimals uint8  `mapstructure:"decimals"`
+}
+
+type Network struct {
+	Name              core.CosmosNetwork `mapstructure:"name"`
+	ChainID           string             `mapstructure:"chain_id"`
+	NativeDenom       string             `mapstructure:"native_denom"`
+	NativeAssetSymbol string             `mapstructure:"native_asset"`
+	NativeDecimals    uint8              `mapstructure:"native_decimals"`
+	Denoms            map[string]Denom   `mapstructure:"denoms"` // Other denoms (like ibc/...), keys are lowercased by viper
+	LCDs              []string           `mapstructure:"lcds"`
+	RPS               uint               `mapstructure:"rps"`
+	Deprecated        bool               `mapstructure:"deprecated"`
+	ExplorerURLs      struct {
+		Tx   string `mapstructure:"tx"`
+		Addr string `mapstructure:"addr"`
+	} `mapstructure:"explorer_urls"`
+}
+
+func (n Network) GetKind() core.NetworkKind {
+	return core.CosmosNetworkKind
+}
+
+func (n Network) GetName() string {
+	return n.Name.String()
+}
+
+func (n Network) GetDeprecated() bool {
+	return n.Deprecated
+}
+
+func (n Network) NativeAsset() core.Asset {
+	decimals := n.NativeDecimals
+	if decimals == 0 {
+		decimals = DEFAULT_DECIMALS
+	}
+
+	return core.Asset{
+		NetworkKind: core.CosmosNetworkKind,
+		NetworkName: n.Name.String(),
+		Kind:        core.CosmosCoin,
+		Identifier:  n.NativeDenom,
+		Symbol:      n.NativeAssetSymbol,
+		Decimals:    decimals,
+	}
+}
+
+// DenomAsset returns the asset for a denom on this chain. Denoms that aren't
+// configured use the denom itself as the symbol.
+func (n Network) DenomAsset(denom string) core.Asset {
+	if denom == n.NativeDenom {
+		return n.NativeAsset()
+	}
+
+	info, found := n.Denoms[strings.ToLower(denom)]
+	if !found {
+		info = Denom{Symbol: denom, Decimals: DEFAULT_DECIMALS}
+	}
+
+	return core.Asset{
+		NetworkKind: core.CosmosNetworkKind,
+		NetworkName: n.Name.String(),
+		Kind:        core.CosmosCoin,
+		Identifier:  denom,
+		Symbol:      info.Symbol,
+		Decimals:    info.Decimals,
+	}
+}
+
+func (n Network) OpenTransactionInExplorer(hash string, wait ...bool) {
+	url := strings.Replace(n.ExplorerURLs.Tx, "TX", hash, 1)
+	time.Sleep(time.Millisecond * 2000)
+	cmd := exec.Command("/usr/bin/open", "-u", url, "-a", "Google Chrome")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Println(cmd.String())
+		fmt.Println(string(output))
+		fmt.Println(err.Error())
+	}
+	if len(wait) > 0 && wait[0] {
+		_, _ = fmt.Scanln()
+	}
+}
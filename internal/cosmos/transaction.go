@@ -0,0 +1,135 @@
+package cosmos
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/shopspring/decimal"
+)
+
+// A TxResponse is a transaction as returned by the LCD, keeping only the
+// fields needed for decoding.
+type TxResponse struct {
+	Height    string  `json:"height"`
+	TxHash    string  `json:"txhash"`
+	Code      int     `json:"code"`
+	Timestamp string  `json:"timestamp"`
+	Logs      []TxLog `json:"logs"`
+	Events    []Event `json:"events"`
+	Tx        struct {
+		Body struct {
+			Messages []json.RawMessage `json:"messages"`
+			Memo     string            `json:"memo"`
+		} `json:"body"`
+		AuthInfo struct {
+			Fee struct {
+				Amount []Coin `json:"amount"`
+				Payer  string `json:"payer"`
+			} `json:"fee"`
+		} `json:"auth_info"`
+	} `json:"tx"`
+}
+
+type TxLog struct {
+	MsgIndex int     `json:"msg_index"`
+	Events   []Event `json:"events"`
+}
+
+type Event struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+func (t *TxResponse) Success() bool {
+	return t.Code == 0
+}
+
+func (t *TxResponse) BlockHeight() int {
+	height, _ := strconv.Atoi(t.Height)
+	return height
+}
+
+func (t *TxResponse) Time() (time.Time, error) {
+	timestamp, err := time.Parse(time.RFC3339, t.Timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid timestamp '%s' for %s: %w", t.Timestamp, t.TxHash, err)
+	}
+	return timestamp.UTC(), nil
+}
+
+// Events emitted by the message at the given index. Older chains group them by
+// message in the logs, while SDK v0.50+ only has tx-level events tagged with
+// msg_index.
+func (t *TxResponse) messageEvents(index int) []Event {
+	if len(t.Logs) > 0 {
+		for _, log := range t.Logs {
+			if log.MsgIndex == index {
+				return log.Events
+			}
+		}
+		return nil
+	}
+
+	events := make([]Event, 0)
+	for _, event := range t.Events {
+		if event.Attribute("msg_index") == strconv.Itoa(index) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (e Event) Attribute(key string) string {
+	for _, attr := range e.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (c Coin) ToAmount(network Network) (core.Amount, error) {
+	return network.DenomAsset(c.Denom).WithAtomicStringValue(c.Amount)
+}
+
+var COIN_REGEX = regexp.MustCompile(`^([0-9]+)([a-zA-Z][a-zA-Z0-9/:._-]{1,127})$`)
+
+// ParseCoins parses the coin list format used in events, like
+// "1000uatom,25ibc/27394FB0...".
+func ParseCoins(s string) ([]Coin, error) {
+	coins := make([]Coin, 0)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		match := COIN_REGEX.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("Invalid coin '%s'", part)
+		}
+
+		coins = append(coins, Coin{Denom: match[2], Amount: match[1]})
+	}
+
+	return coins, nil
+}
+
+func (c Coin) IsZero() bool {
+	amount, err := decimal.NewFromString(c.Amount)
+	return err != nil || amount.IsZero()
+}
@@ -0,0 +1,349 @@
+package cost_basis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/shopspring/decimal"
+)
+
+type Method string
+
+const (
+	FIFO        Method = "fifo"
+	LIFO        Method = "lifo"
+	HIFO        Method = "hifo"
+	AverageCost Method = "average"
+)
+
+func ParseMethod(s string) (Method, error) {
+	switch m := Method(s); m {
+	case FIFO, LIFO, HIFO, AverageCost:
+		return m, nil
+	default:
+		return "", fmt.Errorf("Unknown cost basis method '%s'", s)
+	}
+}
+
+type EventKind string
+
+const (
+	Acquisition EventKind = "acquisition"
+	Disposal    EventKind = "disposal"
+)
+
+// An Event is a single acquisition or disposal of an asset. For acquisitions,
+// Value is the total fiat cost of the amount acquired. For disposals, it is the
+// total fiat proceeds.
+type Event struct {
+	Kind   EventKind
+	ID     string
+	Time   time.Time
+	Amount core.Amount
+	Value  decimal.Decimal
+}
+
+// A Lot is the remaining portion of a single acquisition.
+type Lot struct {
+	ID       string
+	Acquired time.Time
+	Amount   core.Amount     // Amount of the acquisition not yet disposed of
+	Cost     decimal.Decimal // Cost basis of the remaining amount
+}
+
+func (l Lot) UnitCost() decimal.Decimal {
+	if l.Amount.Value.IsZero() {
+		return decimal.Zero
+	}
+	return l.Cost.Div(l.Amount.Value)
+}
+
+// A LotUsage records how much of a lot was consumed by a disposal.
+type LotUsage struct {
+	LotID    string
+	Acquired time.Time
+	Amount   core.Amount
+	Cost     decimal.Decimal
+}
+
+// A Realization is the realized gain or loss for a single disposal. Any amount
+// disposed of beyond the known lots is reported as Unmatched, with a cost basis
+// of zero.
+type Realization struct {
+	Disposal  Event
+	Lots      []LotUsage
+	Unmatched core.Amount
+	Proceeds  decimal.Decimal
+	CostBasis decimal.Decimal
+	Gain      decimal.Decimal
+}
+
+type Engine struct {
+	method Method
+	lots   map[string][]*Lot // Keyed by canonical ID, so fungible assets share lots
+}
+
+func NewEngine(method Method) (*Engine, error) {
+	if _, err := ParseMethod(string(method)); err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		method: method,
+		lots:   make(map[string][]*Lot),
+	}, nil
+}
+
+// Process applies a stream of events in chronological order, returning the
+// realizations for every disposal. Events with the same timestamp are applied
+// in the order given.
+func (e *Engine) Process(events []Event) ([]Realization, error) {
+	sorted := make([]Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	realizations := make([]Realization, 0)
+
+	for _, event := range sorted {
+		switch event.Kind {
+		case Acquisition:
+			err := e.Acquire(event)
+			if err != nil {
+				return nil, err
+			}
+		case Disposal:
+			realization, err := e.Dispose(event)
+			if err != nil {
+				return nil, err
+			}
+			realizations = append(realizations, realization)
+		default:
+			return nil, fmt.Errorf("Unknown event kind '%s' for %s", event.Kind, event.ID)
+		}
+	}
+
+	return realizations, nil
+}
+
+func (e *Engine) Acquire(event Event) error {
+	if event.Amount.Value.IsNegative() || event.Amount.Value.IsZero() {
+		return fmt.Errorf("Acquisition %s must have a positive amount, got %s", event.ID, event.Amount)
+	}
+	if event.Value.IsNegative() {
+		return fmt.Errorf("Acquisition %s cannot have a negative cost", event.ID)
+	}
+
+	key := event.Amount.Asset.CanonicalID()
+	e.lots[key] = append(e.lots[key], &Lot{
+		ID:       event.ID,
+		Acquired: event.Time,
+		Amount:   event.Amount,
+		Cost:     event.Value,
+	})
+
+	return nil
+}
+
+func (e *Engine) Dispose(event Event) (Realization, error) {
+	if event.Amount.Value.IsNegative() || event.Amount.Value.IsZero() {
+		return Realization{}, fmt.Errorf("Disposal %s must have a positive amount, got %s", event.ID, event.Amount)
+	}
+
+	asset := event.Amount.Asset
+	realization := Realization{
+		Disposal:  event,
+		Lots:      make([]LotUsage, 0),
+		Unmatched: asset.WithAtomicValue(0),
+		Proceeds:  event.Value,
+		CostBasis: decimal.Zero,
+	}
+
+	var usages []LotUsage
+	var remaining core.Amount
+	var err error
+
+	if e.method == AverageCost {
+		usages, remaining, err = e.consumeProportionally(asset, event.Amount)
+	} else {
+		usages, remaining, err = e.consumeInOrder(asset, event.Amount)
+	}
+	if err != nil {
+		return Realization{}, fmt.Errorf("Could not dispose of %s for %s: %w", event.Amount, event.ID, err)
+	}
+
+	for _, usage := range usages {
+		realization.CostBasis = realization.CostBasis.Add(usage.Cost)
+	}
+	realization.Lots = usages
+	realization.Unmatched = remaining
+	realization.Gain = realization.Proceeds.Sub(realization.CostBasis)
+
+	e.removeEmptyLots(asset)
+
+	return realization, nil
+}
+
+// Lots returns a copy of the open lots for an asset and anything fungible with
+// it, in the order they were acquired.
+func (e *Engine) Lots(asset core.Asset) []Lot {
+	key := asset.CanonicalID()
+	lots := make([]Lot, 0, len(e.lots[key]))
+	for _, lot := range e.lots[key] {
+		lots = append(lots, *lot)
+	}
+	return lots
+}
+
+// Holdings returns the total amount and cost basis of the open lots for an
+// asset and anything fungible with it.
+func (e *Engine) Holdings(asset core.Asset) (core.Amount, decimal.Decimal) {
+	total := asset.WithAtomicValue(0)
+	cost := decimal.Zero
+	for _, lot := range e.lots[asset.CanonicalID()] {
+		total.Value = total.Value.Add(lot.Amount.Value)
+		cost = cost.Add(lot.Cost)
+	}
+	return total, cost
+}
+
+func (e *Engine) consumeInOrder(asset core.Asset, amount core.Amount) ([]LotUsage, core.Amount, error) {
+	lots := e.lots[asset.CanonicalID()]
+	ordered := make([]*Lot, len(lots))
+	copy(ordered, lots)
+
+	switch e.method {
+	case FIFO:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].Acquired.Before(ordered[j].Acquired)
+		})
+	case LIFO:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].Acquired.After(ordered[j].Acquired)
+		})
+	case HIFO:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].UnitCost().GreaterThan(ordered[j].UnitCost())
+		})
+	}
+
+	usages := make([]LotUsage, 0)
+	remaining := amount
+
+	for _, lot := range ordered {
+		if remaining.Value.IsZero() {
+			break
+		}
+
+		taken := lot.Amount
+		if lot.Amount.Value.GreaterThan(remaining.Value) {
+			taken = remaining
+		}
+
+		usage, err := takeFromLot(lot, taken)
+		if err != nil {
+			return nil, remaining, err
+		}
+		usages = append(usages, usage)
+
+		remaining, err = remaining.Sub(taken)
+		if err != nil {
+			return nil, remaining, err
+		}
+	}
+
+	return usages, remaining, nil
+}
+
+func (e *Engine) consumeProportionally(asset core.Asset, amount core.Amount) ([]LotUsage, core.Amount, error) {
+	held, _ := e.Holdings(asset)
+	if held.Value.IsZero() {
+		return make([]LotUsage, 0), amount, nil
+	}
+
+	fraction := decimal.NewFromInt(1)
+	remaining := asset.WithAtomicValue(0)
+	if amount.Value.LessThan(held.Value) {
+		fraction = amount.Value.Div(held.Value)
+	} else {
+		var err error
+		remaining, err = amount.Sub(held)
+		if err != nil {
+			return nil, amount, err
+		}
+	}
+
+	// Take the same fraction from every lot, truncated to the asset's precision.
+	// What truncation left over is then taken from the last lot back, each up
+	// to what it still has, so the total taken is exact.
+	lots := e.lots[asset.CanonicalID()]
+	shares := make([]decimal.Decimal, len(lots))
+	leftover := amount.Value
+	if !fraction.LessThan(decimal.NewFromInt(1)) {
+		leftover = decimal.Zero
+	}
+	for i, lot := range lots {
+		shares[i] = lot.Amount.Value
+		if fraction.LessThan(decimal.NewFromInt(1)) {
+			shares[i] = lot.Amount.Value.Mul(fraction).Truncate(int32(asset.Decimals))
+			leftover = leftover.Sub(shares[i])
+		}
+	}
+	for i := len(lots) - 1; i >= 0 && leftover.IsPositive(); i-- {
+		extra := decimal.Min(leftover, lots[i].Amount.Value.Sub(shares[i]))
+		shares[i] = shares[i].Add(extra)
+		leftover = leftover.Sub(extra)
+	}
+
+	usages := make([]LotUsage, 0, len(lots))
+	for i, lot := range lots {
+		if shares[i].IsZero() {
+			continue
+		}
+
+		taken := lot.Amount
+		taken.Value = shares[i]
+		usage, err := takeFromLot(lot, taken)
+		if err != nil {
+			return nil, amount, err
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, remaining, nil
+}
+
+func takeFromLot(lot *Lot, taken core.Amount) (LotUsage, error) {
+	cost := lot.Cost
+	if taken.Value.LessThan(lot.Amount.Value) {
+		cost = lot.Cost.Mul(taken.Value).Div(lot.Amount.Value)
+	}
+
+	newAmount, err := lot.Amount.Sub(taken)
+	if err != nil {
+		return LotUsage{}, err
+	}
+	lot.Amount = newAmount
+	lot.Cost = lot.Cost.Sub(cost)
+
+	return LotUsage{
+		LotID:    lot.ID,
+		Acquired: lot.Acquired,
+		Amount:   taken,
+		Cost:     cost,
+	}, nil
+}
+
+func (e *Engine) removeEmptyLots(asset core.Asset) {
+	key := asset.CanonicalID()
+	open := make([]*Lot, 0, len(e.lots[key]))
+	for _, lot := range e.lots[key] {
+		if lot.Amount.Value.IsPositive() {
+			open = append(open, lot)
+		}
+	}
+	e.lots[key] = open
+}
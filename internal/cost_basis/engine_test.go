@@ -0,0 +1,211 @@
+package cost_basis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cost_basis"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var eth core.Asset = core.Asset{
+	NetworkKind: core.EvmNetworkKind,
+	NetworkName: "ethereum",
+	Kind:        core.EvmNative,
+	Identifier:  "0x0000000000000000000000000000000000000000",
+	Symbol:      "ETH",
+	Decimals:    18,
+}
+
+func day(n int) time.Time {
+	return time.Date(2024, time.January, n, 0, 0, 0, 0, time.UTC)
+}
+
+func acquire(id string, t time.Time, amount, cost string) cost_basis.Event {
+	a, _ := eth.WithDecimalStringValue(amount)
+	return cost_basis.Event{
+		Kind:   cost_basis.Acquisition,
+		ID:     id,
+		Time:   t,
+		Amount: a,
+		Value:  decimal.RequireFromString(cost),
+	}
+}
+
+func dispose(id string, t time.Time, amount, proceeds string) cost_basis.Event {
+	a, _ := eth.WithDecimalStringValue(amount)
+	return cost_basis.Event{
+		Kind:   cost_basis.Disposal,
+		ID:     id,
+		Time:   t,
+		Amount: a,
+		Value:  decimal.RequireFromString(proceeds),
+	}
+}
+
+// Three lots at $1000, $3000 and $2000 per ETH, then a sale of 1.5 ETH for $3750
+var events = []cost_basis.Event{
+	acquire("a", day(1), "1", "1000"),
+	acquire("b", day(2), "1", "3000"),
+	acquire("c", day(3), "1", "2000"),
+	dispose("d", day(4), "1.5", "3750"),
+}
+
+func process(t *testing.T, method cost_basis.Method) cost_basis.Realization {
+	engine, err := cost_basis.NewEngine(method)
+	assert.Nil(t, err)
+	realizations, err := engine.Process(events)
+	assert.Nil(t, err)
+	assert.Len(t, realizations, 1)
+	return realizations[0]
+}
+
+func TestFIFO(t *testing.T) {
+	r := process(t, cost_basis.FIFO)
+	assert.Equal(t, "2500", r.CostBasis.String())
+	assert.Equal(t, "1250", r.Gain.String())
+	assert.Equal(t, "a", r.Lots[0].LotID)
+	assert.Equal(t, "b", r.Lots[1].LotID)
+}
+
+func TestLIFO(t *testing.T) {
+	r := process(t, cost_basis.LIFO)
+	assert.Equal(t, "3500", r.CostBasis.String())
+	assert.Equal(t, "250", r.Gain.String())
+	assert.Equal(t, "c", r.Lots[0].LotID)
+	assert.Equal(t, "b", r.Lots[1].LotID)
+}
+
+func TestHIFO(t *testing.T) {
+	r := process(t, cost_basis.HIFO)
+	assert.Equal(t, "4000", r.CostBasis.String())
+	assert.Equal(t, "-250", r.Gain.String())
+	assert.Equal(t, "b", r.Lots[0].LotID)
+	assert.Equal(t, "c", r.Lots[1].LotID)
+}
+
+func TestAverageCost(t *testing.T) {
+	r := process(t, cost_basis.AverageCost)
+	assert.Equal(t, "3000", r.CostBasis.String())
+	assert.Equal(t, "750", r.Gain.String())
+	assert.Len(t, r.Lots, 3)
+}
+
+func TestRemainingLots(t *testing.T) {
+	engine, _ := cost_basis.NewEngine(cost_basis.FIFO)
+	_, err := engine.Process(events)
+	assert.Nil(t, err)
+
+	held, cost := engine.Holdings(eth)
+	assert.Equal(t, "1.5", held.Value.String())
+	assert.Equal(t, "3500", cost.String())
+
+	lots := engine.Lots(eth)
+	assert.Len(t, lots, 2)
+	assert.Equal(t, "b", lots[0].ID)
+	assert.Equal(t, "0.5", lots[0].Amount.Value.String())
+	assert.Equal(t, "1500", lots[0].Cost.String())
+}
+
+func TestDisposeMoreThanHeld(t *testing.T) {
+	engine, _ := cost_basis.NewEngine(cost_basis.FIFO)
+	realizations, err := engine.Process([]cost_basis.Event{
+		acquire("a", day(1), "1", "1000"),
+		dispose("b", day(2), "1.25", "2500"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.25", realizations[0].Unmatched.Value.String())
+	assert.Equal(t, "1000", realizations[0].CostBasis.String())
+	assert.Equal(t, "1500", realizations[0].Gain.String())
+}
+
+func TestProcessSortsByTime(t *testing.T) {
+	engine, _ := cost_basis.NewEngine(cost_basis.FIFO)
+	realizations, err := engine.Process([]cost_basis.Event{
+		dispose("c", day(3), "1", "500"),
+		acquire("a", day(1), "1", "1000"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "-500", realizations[0].Gain.String())
+}
+
+func TestUnknownMethod(t *testing.T) {
+	_, err := cost_basis.NewEngine(cost_basis.Method("random"))
+	assert.EqualError(t, err, "Unknown cost basis method 'random'")
+}
+
+func TestFungibleAssetsShareLots(t *testing.T) {
+	registry, err := core.ParseAssetRegistry([]byte(`
+assets:
+  - id: usdc
+    symbol: USDC
+    members:
+      - network: ethereum
+        identifier: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+      - network: polygon
+        identifier: "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+        relation: bridged
+`))
+	assert.Nil(t, err)
+	previous := core.ASSET_REGISTRY
+	core.ASSET_REGISTRY = registry
+	defer func() { core.ASSET_REGISTRY = previous }()
+
+	usdc := core.Asset{
+		NetworkKind: core.EvmNetworkKind,
+		NetworkName: "ethereum",
+		Kind:        core.Erc20Token,
+		Identifier:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		Symbol:      "USDC",
+		Decimals:    6,
+	}
+	bridged := usdc
+	bridged.NetworkName = "polygon"
+	bridged.Identifier = "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+	bridged.Symbol = "USDC.e"
+
+	bought, _ := usdc.WithDecimalStringValue("100")
+	sold, _ := bridged.WithDecimalStringValue("40")
+
+	engine, _ := cost_basis.NewEngine(cost_basis.FIFO)
+	realizations, err := engine.Process([]cost_basis.Event{
+		{Kind: cost_basis.Acquisition, ID: "buy", Time: day(1), Amount: bought, Value: decimal.RequireFromString("99")},
+		{Kind: cost_basis.Disposal, ID: "sell", Time: day(2), Amount: sold, Value: decimal.RequireFromString("40")},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, realizations, 1)
+	assert.True(t, realizations[0].Unmatched.IsZero())
+	assert.Equal(t, "39.6", realizations[0].CostBasis.String())
+
+	held, _ := engine.Holdings(bridged)
+	assert.Equal(t, "60", held.Value.String())
+}
+
+func TestAverageCostRoundingStaysWithinLots(t *testing.T) {
+	whole := eth
+	whole.Decimals = 0
+
+	event := func(kind cost_basis.EventKind, id string, n int, amount string) cost_basis.Event {
+		a, _ := whole.WithDecimalStringValue(amount)
+		return cost_basis.Event{Kind: kind, ID: id, Time: day(n), Amount: a, Value: decimal.RequireFromString("30")}
+	}
+
+	engine, _ := cost_basis.NewEngine(cost_basis.AverageCost)
+	realizations, err := engine.Process([]cost_basis.Event{
+		event(cost_basis.Acquisition, "a", 1, "1"),
+		event(cost_basis.Acquisition, "b", 2, "1"),
+		event(cost_basis.Acquisition, "c", 3, "1"),
+		event(cost_basis.Disposal, "d", 4, "2"),
+	})
+	assert.Nil(t, err)
+	assert.True(t, realizations[0].Unmatched.IsZero())
+	assert.Equal(t, "60", realizations[0].CostBasis.String())
+
+	held, _ := engine.Holdings(whole)
+	assert.Equal(t, "1", held.Value.String())
+	for _, lot := range engine.Lots(whole) {
+		assert.False(t, lot.Amount.IsNegative())
+	}
+}
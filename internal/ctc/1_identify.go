@@ -2,7 +2,6 @@ package ctc
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"sync"
 
@@ -19,17 +18,13 @@ type cachedTxs struct {
 	Txs     []string `json:"txs"`
 }
 
-func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
+func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients, opts Options) error {
 	cfg := config.Config
+	networks := opts.FilterNetworks(cfg.AllNetworks())
 
 	txHashesDB := db.NewCollection("evm_tx_hashes")
 
-	if os.Getenv("SKIP_IDENTIFY") != "" {
-		fmt.Println("Skipping transaction identification step")
-		return
-	}
-
-	indexers := generic.NewAllIndexers(cfg.AllNetworks())
+	indexers := generic.NewAllIndexers(networks)
 	latestBlocks := clients.LatestBlocks()
 
 	fmt.Println("Getting transaction hashes for each address...")
@@ -37,15 +32,20 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 	var mu sync.Mutex
 	txHashes := make(map[string]map[string][]string, 0) // address -> network -> list of tx hashes
 	errors := make(map[string]map[string]error, 0)      // address -> network -> error
-	for name, addr := range cfg.Ownership.Ethereum.Addresses {
-		label := fmt.Sprintf("%s (%s)", addr.Hex(), name)
-		txHashes[label] = make(map[string][]string, 0)
-		errors[label] = make(map[string]error, 0)
+	for _, network := range networks {
+		for name, addr := range cfg.OwnedAddresses(network) {
+			if !opts.IncludesLabel(name) {
+				continue
+			}
+			label := fmt.Sprintf("%s (%s)", addr, name)
+			txHashes[label] = make(map[string][]string, 0)
+			errors[label] = make(map[string]error, 0)
+		}
 	}
 
 	var wg sync.WaitGroup
 
-	for _, network := range cfg.AllNetworks() {
+	for _, network := range networks {
 		wg.Add(1)
 		go func(network core.Network) {
 			defer wg.Done()
@@ -67,9 +67,13 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 				return
 			}
 
-			for name, addr := range cfg.Ownership.Ethereum.Addresses {
-				label := fmt.Sprintf("%s (%s)", addr.Hex(), name)
-				cacheKey := fmt.Sprintf("%s-%s", network.GetName(), addr.Hex())
+			for name, addr := range cfg.OwnedAddresses(network) {
+				if !opts.IncludesLabel(name) {
+					continue
+				}
+
+				label := fmt.Sprintf("%s (%s)", addr, name)
+				cacheKey := fmt.Sprintf("%s-%s", network.GetName(), addr)
 
 				var firstBlock *int
 				knownTxs := make([]string, 0)
@@ -79,7 +83,7 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 				if err != nil {
 					fmt.Printf("Error reading cache for %s: %s\n", cacheKey, err.Error())
 				} else if cacheFound {
-					if cached.Address == addr.Hex() && cached.Network == network.GetName() {
+					if cached.Address == addr && cached.Network == network.GetName() {
 						firstBlock = &cached.Block
 						knownTxs = cached.Txs
 					} else {
@@ -100,7 +104,7 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 				}
 
 				latestBlockInt := int(latestBlock)
-				txs, err := indexer.GetAllTransactionHashes(addr.Hex(), firstBlock, &latestBlockInt)
+				txs, err := indexer.GetAllTransactionHashes(addr, firstBlock, &latestBlockInt)
 				if err != nil {
 					fmt.Printf("%s - %s: Error getting transactions: %s\n", label, network.GetName(), err.Error())
 					mu.Lock()
@@ -121,7 +125,7 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 				)
 				err = txHashesDB.Write(cacheKey, cachedTxs{
 					Network: network.GetName(),
-					Address: addr.Hex(),
+					Address: addr,
 					Block:   latestBlockInt,
 					Txs:     allTxs,
 				})
@@ -158,6 +162,7 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 
 	fmt.Printf("\n------------------------------------------------------------\n\n")
 
+	totalErrors := 0
 	for addrLabel, errorsByNetwork := range errors {
 		errCount := 0
 		for _, err := range errorsByNetwork {
@@ -166,8 +171,9 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 			}
 		}
 		if errCount == 0 {
-			break
+			continue
 		}
+		totalErrors += errCount
 		fmt.Printf("%s: %d total errors\n", addrLabel, errCount)
 		for network, err := range errorsByNetwork {
 			if err != nil {
@@ -176,4 +182,10 @@ func IdentifyTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 		}
 		fmt.Println()
 	}
+
+	if totalErrors > 0 {
+		return fmt.Errorf("Could not identify transactions for %d address/network pairs", totalErrors)
+	}
+
+	return nil
 }
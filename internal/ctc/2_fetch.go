@@ -2,72 +2,77 @@ package ctc
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/cosmos"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
 	"github.com/ksmithbaylor/gohodl/internal/generic"
+	"github.com/ksmithbaylor/gohodl/internal/solana"
 	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/ksmithbaylor/gohodl/internal/utxo"
 )
 
-func FetchTransactions(db *util.FileDB, clients generic.AllNodeClients) map[string][]string {
+func FetchTransactions(db *util.FileDB, clients generic.AllNodeClients, opts Options) error {
 	txsDB := db.NewCollection("txs")
 	receiptsDB := db.NewCollection("receipts")
 	blocksDB := db.NewCollection("blocks")
+	verifiedDB := db.NewCollection("verified_txs")
+	deadDB := db.NewCollection("dead_txs")
+	utxoTxsDB := db.NewCollection("utxo_txs")
+	solanaTxsDB := db.NewCollection("solana_txs")
+	cosmosTxsDB := db.NewCollection("cosmos_txs")
 
-	txHashesDB, found := db.Collections["evm_tx_hashes"]
-	if !found || txHashesDB == nil {
-		fmt.Println("Cannot fetch transactions without first running identification step")
-		return nil
-	}
-
-	txHashesKeys, err := txHashesDB.List()
+	txsToFetch, err := readTransactionHashes(db, opts)
 	if err != nil {
-		fmt.Printf("Error listing keys in tx hashes collection: %s\n", err.Error())
-		return nil
+		return err
 	}
+	txsToFetch = filterDeadTransactions(deadDB, txsToFetch, opts.RetryDead)
 
-	txsToFetch := make(map[string][]string)
-
-	// Get a list of transaction hashes to fetch for each network
-	for _, key := range txHashesKeys {
-		// Only actual cache keys
-		if !strings.Contains(key, "-") {
+	// Fetch all transactions in parallel across networks
+	var wg sync.WaitGroup
+	for network, txs := range txsToFetch {
+		client := clients[network]
+		if client == nil {
+			fmt.Printf("No client found for %s\n", network)
 			continue
 		}
 
-		var entry cachedTxs
-		cacheFound, err := txHashesDB.Read(key, &entry)
-		if err != nil {
-			fmt.Printf("Error reading tx hash cache for %s: %s\n", key, err.Error())
-			continue
-		}
-		if !cacheFound {
-			fmt.Printf("Tx hash cache disappeared for %s\n", key)
+		if utxoClient, ok := client.(*utxo.Client); ok {
+			if utxoClient.Network.GetDeprecated() {
+				fmt.Printf("Skipping fetch step for deprecated network %s\n", network)
+				continue
+			}
+
+			wg.Add(1)
+			go fetchWhole(&wg, utxoTxsDB, deadDB, network, txs, utxoClient.GetTransaction)
 			continue
 		}
 
-		if len(entry.Txs) == 0 {
+		if solanaClient, ok := client.(*solana.Client); ok {
+			if solanaClient.Network.GetDeprecated() {
+				fmt.Printf("Skipping fetch step for deprecated network %s\n", network)
+				continue
+			}
+
+			wg.Add(1)
+			go fetchWhole(&wg, solanaTxsDB, deadDB, network, txs, solanaClient.GetTransaction)
 			continue
 		}
 
-		txsToFetch[entry.Network] = util.UniqueItems(txsToFetch[entry.Network], entry.Txs)
-	}
-
-	if os.Getenv("SKIP_FETCH") != "" {
-		fmt.Println("Skipping transaction fetching step")
-		return txsToFetch
-	}
+		if cosmosClient, ok := client.(*cosmos.Client); ok {
+			if cosmosClient.Network.GetDeprecated() {
+				fmt.Printf("Skipping fetch step for deprecated network %s\n", network)
+				continue
+			}
 
-	// Fetch all transactions in parallel across networks
-	var wg sync.WaitGroup
-	for network, txs := range txsToFetch {
-		client := clients[network]
-		if client == nil {
-			fmt.Printf("No client found for %s\n", network)
+			wg.Add(1)
+			go fetchWhole(&wg, cosmosTxsDB, deadDB, network, txs, cosmosClient.GetTransaction)
 			continue
 		}
 
@@ -83,13 +88,117 @@ func FetchTransactions(db *util.FileDB, clients generic.AllNodeClients) map[stri
 		}
 
 		wg.Add(1)
-		go fetch(&wg, evmClient, txsDB, receiptsDB, blocksDB, network, txs)
+		go fetch(&wg, evmClient, txsDB, receiptsDB, blocksDB, verifiedDB, deadDB, network, txs)
 	}
 
 	wg.Wait()
 	fmt.Println("Done fetching all transactions!")
 
-	return txsToFetch
+	for _, client := range clients {
+		if evmClient, ok := client.(*evm.Client); ok {
+			err := evmClient.SaveHealth()
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// filterDeadTransactions leaves out the transactions that ran out of fetch
+// attempts before, or keeps only those when retrying them.
+func filterDeadTransactions(deadDB *util.FileDBCollection, txsToFetch map[string][]string, retryDead bool) map[string][]string {
+	filtered := make(map[string][]string, len(txsToFetch))
+
+	for network, txs := range txsToFetch {
+		kept := make([]string, 0, len(txs))
+		dead := 0
+		for _, txHash := range txs {
+			isDead := deadDB.Has(fmt.Sprintf("%s-%s", network, txHash))
+			if isDead {
+				dead++
+			}
+			if isDead == retryDead {
+				kept = append(kept, txHash)
+			}
+		}
+
+		if dead > 0 && !retryDead {
+			fmt.Printf("Skipping %d dead transactions on %s (retry with fetch -retry-dead)\n", dead, network)
+		}
+		if len(kept) > 0 {
+			filtered[network] = kept
+		}
+	}
+
+	return filtered
+}
+
+// readTransactionHashes returns the hashes found by the identify step for each
+// network.
+func readTransactionHashes(db *util.FileDB, opts Options) (map[string][]string, error) {
+	txHashesDB, found := db.OpenCollection("evm_tx_hashes")
+	if !found {
+		return nil, fmt.Errorf("Cannot read transaction hashes without first running identify step")
+	}
+
+	txHashesKeys, err := txHashesDB.List()
+	if err != nil {
+		return nil, fmt.Errorf("Error listing keys in tx hashes collection: %w", err)
+	}
+
+	txHashes := make(map[string][]string)
+
+	for _, key := range txHashesKeys {
+		// Only actual cache keys
+		if !strings.Contains(key, "-") {
+			continue
+		}
+
+		var entry cachedTxs
+		cacheFound, err := txHashesDB.Read(key, &entry)
+		if err != nil {
+			fmt.Printf("Error reading tx hash cache for %s: %s\n", key, err.Error())
+			continue
+		}
+		if !cacheFound {
+			fmt.Printf("Tx hash cache disappeared for %s\n", key)
+			continue
+		}
+
+		if len(entry.Txs) == 0 || !opts.IncludesNetwork(entry.Network) {
+			continue
+		}
+
+		txHashes[entry.Network] = util.UniqueItems(txHashes[entry.Network], entry.Txs)
+	}
+
+	return txHashes, nil
+}
+
+// Requests in flight at once per network, and how many receipts have to be
+// needed from one block before fetching all of its receipts at once
+const (
+	FETCH_WORKERS      = 4
+	BLOCK_RECEIPTS_MIN = 2
+)
+
+// Passes over a transaction before giving up on it, and the wait before the
+// second pass, which doubles for each one after
+const (
+	FETCH_ATTEMPTS = 5
+	FETCH_BACKOFF  = 2 * time.Second
+)
+
+// A transaction that ran out of fetch attempts or failed verification, kept in
+// the dead_txs collection until fetch -retry-dead gets it
+type deadTx struct {
+	Network  string    `json:"network"`
+	Hash     string    `json:"hash"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"` // From the last attempt
+	Time     time.Time `json:"time"`
 }
 
 func fetch(
@@ -98,6 +207,8 @@ func fetch(
 	txsDB *util.FileDBCollection,
 	receiptsDB *util.FileDBCollection,
 	blocksDB *util.FileDBCollection,
+	verifiedDB *util.FileDBCollection,
+	deadDB *util.FileDBCollection,
 	network string,
 	txs []string,
 ) {
@@ -105,70 +216,516 @@ func fetch(
 
 	fmt.Printf("Fetching %d transactions on %s\n", len(txs), network)
 
+	// Not being able to reach the network says nothing about the transactions,
+	// so it doesn't count against their attempts
+	err := client.Connect()
+	if err != nil {
+		fmt.Printf("Error connecting to %s, its transactions will be fetched next time: %s\n", network, err.Error())
+		return
+	}
+
+	fetchWithRetries(deadDB, network, txs, func(unfetched []string) map[string]string {
+		return fetchPass(client, txsDB, receiptsDB, blocksDB, network, unfetched)
+	})
+
+	if len(client.Network.Checkpoints) > 0 {
+		verifyFetched(client, txsDB, receiptsDB, blocksDB, verifiedDB, deadDB, network, txs)
+	}
+
+	fmt.Printf("Done fetching transactions for %s\n", network)
+}
+
+// fetchWithRetries runs passes over the transactions until all of them are
+// fetched or out of attempts, backing off between passes. Transactions that
+// still fail after FETCH_ATTEMPTS go to the dead-letter collection with their
+// last error, and ones that succeed are taken out of it.
+func fetchWithRetries(
+	deadDB *util.FileDBCollection,
+	network string,
+	txs []string,
+	pass func(unfetched []string) map[string]string,
+) {
 	unfetched := txs
+	backoff := FETCH_BACKOFF
 
-	for {
-		retryTx := make([]string, 0)
-		retryReceipt := make([]string, 0)
-		retryBlock := make([]string, 0)
-		retryInternal := make([]string, 0)
+	for attempt := 1; ; attempt++ {
+		failures := pass(unfetched)
 
 		for _, txHash := range unfetched {
-			cacheKey := fmt.Sprintf("%s-%s", network, txHash)
+			if _, failed := failures[txHash]; failed {
+				continue
+			}
+			err := deadDB.Delete(fmt.Sprintf("%s-%s", network, txHash))
+			if err != nil {
+				fmt.Printf("Error removing %s tx %s from dead transactions: %s\n", network, txHash, err.Error())
+			}
+		}
 
-			txSuccess := fetchTransaction(client, txsDB, cacheKey, network, txHash)
-			receiptSuccess, blockHash := fetchTransactionReceipt(client, receiptsDB, cacheKey, network, txHash)
-			blockSuccess := fetchBlock(client, blocksDB, network, blockHash)
-			internalSuccess := fetchInternalTxs(client, network, txHash)
+		retry := make([]string, 0, len(failures))
+		for _, txHash := range unfetched {
+			if _, failed := failures[txHash]; failed {
+				retry = append(retry, txHash)
+			}
+		}
+		if len(retry) == 0 {
+			return
+		}
 
-			if !txSuccess {
-				retryTx = append(retryTx, txHash)
+		if attempt == FETCH_ATTEMPTS {
+			for _, txHash := range retry {
+				err := deadDB.Write(fmt.Sprintf("%s-%s", network, txHash), deadTx{
+					Network:  network,
+					Hash:     txHash,
+					Attempts: attempt,
+					Error:    failures[txHash],
+					Time:     time.Now().UTC(),
+				})
+				if err != nil {
+					fmt.Printf("Error recording dead %s tx %s: %s\n", network, txHash, err.Error())
+				}
 			}
-			if !receiptSuccess {
-				retryReceipt = append(retryReceipt, txHash)
+			fmt.Printf("Gave up on %d transactions on %s after %d attempts, see %s (retry with fetch -retry-dead)\n", len(retry), network, attempt, deadDB.Folder())
+			return
+		}
+
+		fmt.Printf("Retrying %d transactions on %s in %s\n", len(retry), network, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		unfetched = retry
+	}
+}
+
+// fetchPass fetches whatever isn't cached yet for each transaction, and
+// returns the last error of each one that needs another pass. Transactions, receipts and blocks
+// are fetched in JSON-RPC batches, and receipts a whole block at a time where
+// several are in the same block. Internal transactions come from the explorer
+// at the same time.
+func fetchPass(
+	client *evm.Client,
+	txsDB *util.FileDBCollection,
+	receiptsDB *util.FileDBCollection,
+	blocksDB *util.FileDBCollection,
+	network string,
+	hashes []string,
+) map[string]string {
+	retry := make(map[string]string)
+
+	var mu sync.Mutex
+	failed := func(reason string, txHashes ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, txHash := range txHashes {
+			retry[txHash] = reason
+		}
+	}
+
+	internalDone := make(chan bool)
+	go func() {
+		jobs := make([]func(), len(hashes))
+		for i, txHash := range hashes {
+			jobs[i] = func() {
+				if err := fetchInternalTxs(client, network, txHash); err != nil {
+					failed("Internal transactions: "+err.Error(), txHash)
+				}
+			}
+		}
+		util.RunPool(FETCH_WORKERS, jobs)
+		close(internalDone)
+	}()
+
+	needTxs := make([]string, 0)
+	needReceipts := make([]string, 0)
+	txsInBlock := make(map[string][]string)
+
+	for _, txHash := range hashes {
+		cacheKey := fmt.Sprintf("%s-%s", network, txHash)
+
+		var cachedTx types.Transaction
+		cacheFound, err := txsDB.Read(cacheKey, &cachedTx)
+		if err != nil {
+			fmt.Printf("Error reading tx cache for %s: %s\n", cacheKey, err.Error())
+			failed("Cache: "+err.Error(), txHash)
+		} else if cacheFound {
+			checkTransaction(&cachedTx, network, txHash)
+		} else {
+			needTxs = append(needTxs, txHash)
+		}
+
+		var cachedReceipt types.Receipt
+		cacheFound, err = receiptsDB.Read(cacheKey, &cachedReceipt)
+		if err != nil {
+			fmt.Printf("Error reading receipt cache for %s: %s\n", cacheKey, err.Error())
+			failed("Cache: "+err.Error(), txHash)
+		} else if cacheFound {
+			checkReceipt(&cachedReceipt, network, txHash)
+			blockHash := cachedReceipt.BlockHash.String()
+			txsInBlock[blockHash] = append(txsInBlock[blockHash], txHash)
+		} else {
+			needReceipts = append(needReceipts, txHash)
+		}
+	}
+
+	// Transactions also tell which block to get receipts from
+	blockOf := make(map[string]string)
+	inBatches(needTxs, func(batch []string) error {
+		fetched, err := client.GetTransactions(batch)
+		if err != nil {
+			return err
+		}
+		for i, f := range fetched {
+			if !cacheTransaction(txsDB, network, batch[i], f.Tx) {
+				failed("Transaction could not be cached", batch[i])
+				continue
+			}
+			mu.Lock()
+			blockOf[batch[i]] = f.BlockHash.String()
+			mu.Unlock()
+		}
+		return nil
+	}, func(txHash string, err error) {
+		fmt.Printf("Error fetching %s tx %s: %s\n", network, txHash, err.Error())
+		failed("Transaction: "+err.Error(), txHash)
+	})
+
+	cacheReceipt := func(txHash string, receipt *types.Receipt) {
+		checkReceipt(receipt, network, txHash)
+
+		cacheKey := fmt.Sprintf("%s-%s", network, txHash)
+		err := receiptsDB.Write(cacheKey, receipt)
+		if err != nil {
+			fmt.Printf("Error caching transaction %s receipt: %s\n", cacheKey, err.Error())
+		}
+
+		mu.Lock()
+		blockHash := receipt.BlockHash.String()
+		txsInBlock[blockHash] = append(txsInBlock[blockHash], txHash)
+		mu.Unlock()
+		fmt.Printf("Fetched %s transaction %s receipt\n", network, txHash)
+	}
+
+	receiptsByBlock := make(map[string][]string)
+	oneByOne := make([]string, 0)
+	for _, txHash := range needReceipts {
+		if blockHash, found := blockOf[txHash]; found {
+			receiptsByBlock[blockHash] = append(receiptsByBlock[blockHash], txHash)
+		} else {
+			oneByOne = append(oneByOne, txHash)
+		}
+	}
+
+	blockJobs := make([]func(), 0)
+	for blockHash, txHashes := range receiptsByBlock {
+		if len(txHashes) < BLOCK_RECEIPTS_MIN {
+			oneByOne = append(oneByOne, txHashes...)
+			continue
+		}
+
+		blockJobs = append(blockJobs, func() {
+			receipts, err := client.GetBlockReceipts(blockHash)
+			if err != nil {
+				util.Debugf("Falling back to single receipts for %s block %s: %s\n", network, blockHash, err.Error())
 			}
-			if !blockSuccess {
-				retryBlock = append(retryBlock, txHash)
+
+			byHash := make(map[string]*types.Receipt, len(receipts))
+			for _, receipt := range receipts {
+				byHash[strings.ToLower(receipt.TxHash.String())] = receipt
 			}
-			if !internalSuccess {
-				retryInternal = append(retryInternal, txHash)
+			for _, txHash := range txHashes {
+				if receipt, found := byHash[strings.ToLower(txHash)]; found {
+					cacheReceipt(txHash, receipt)
+				} else {
+					mu.Lock()
+					oneByOne = append(oneByOne, txHash)
+					mu.Unlock()
+				}
 			}
+		})
+	}
+	util.RunPool(FETCH_WORKERS, blockJobs)
+
+	inBatches(oneByOne, func(batch []string) error {
+		receipts, err := client.GetTransactionReceipts(batch)
+		if err != nil {
+			return err
+		}
+		for i, receipt := range receipts {
+			cacheReceipt(batch[i], receipt)
 		}
+		return nil
+	}, func(txHash string, err error) {
+		fmt.Printf("Error fetching %s tx %s receipt: %s\n", network, txHash, err.Error())
+		failed("Receipt: "+err.Error(), txHash)
+	})
+
+	needBlocks := make([]string, 0)
+	for blockHash := range txsInBlock {
+		cacheKey := fmt.Sprintf("%s-%s", network, blockHash)
 
-		if len(retryTx) > 0 || len(retryReceipt) > 0 || len(retryBlock) > 0 || len(retryInternal) > 0 {
-			unfetched = util.UniqueItems(retryTx, retryReceipt, retryBlock, retryInternal)
+		var cachedBlock types.Header
+		cacheFound, err := blocksDB.Read(cacheKey, &cachedBlock)
+		if err != nil {
+			fmt.Printf("Error reading cache for block %s: %s\n", cacheKey, err.Error())
+		} else if cacheFound {
+			checkBlock(&cachedBlock, network, blockHash)
 		} else {
-			break
+			needBlocks = append(needBlocks, blockHash)
 		}
 	}
 
-	fmt.Printf("Done fetching transactions for %s\n", network)
+	inBatches(needBlocks, func(batch []string) error {
+		blocks, err := client.GetBlocks(batch)
+		if err != nil {
+			return err
+		}
+		for i, block := range blocks {
+			checkBlock(block, network, batch[i])
+
+			cacheKey := fmt.Sprintf("%s-%s", network, batch[i])
+			err = blocksDB.Write(cacheKey, block)
+			if err != nil {
+				fmt.Printf("Error caching block %s: %s\n", cacheKey, err.Error())
+			}
+			fmt.Printf("Fetched %s block %s\n", network, batch[i])
+		}
+		return nil
+	}, func(blockHash string, err error) {
+		fmt.Printf("Error fetching %s block %s: %s\n", network, blockHash, err.Error())
+		failed("Block: "+err.Error(), txsInBlock[blockHash]...)
+	})
+
+	<-internalDone
+
+	return retry
+}
+
+// inBatches runs fetch over batches of the hashes on a pool of workers. A
+// batch that fails is tried again one hash at a time, so that one bad hash
+// doesn't hold back the rest of its batch.
+func inBatches(hashes []string, fetch func(batch []string) error, failed func(hash string, err error)) {
+	jobs := make([]func(), 0, len(hashes)/evm.BATCH_SIZE+1)
+
+	for start := 0; start < len(hashes); start += evm.BATCH_SIZE {
+		batch := hashes[start:min(start+evm.BATCH_SIZE, len(hashes))]
+		jobs = append(jobs, func() {
+			err := fetch(batch)
+			if err == nil {
+				return
+			}
+			if len(batch) == 1 {
+				failed(batch[0], err)
+				return
+			}
+			for _, hash := range batch {
+				err = fetch([]string{hash})
+				if err != nil {
+					failed(hash, err)
+				}
+			}
+		})
+	}
+
+	util.RunPool(FETCH_WORKERS, jobs)
 }
 
-func fetchTransaction(
+// verifyFetched ties the cached transactions and receipts of a network to its
+// checkpoints, through the hash chain and the roots of the blocks they are in.
+// Verified transactions are recorded so later fetches skip them. Ones in a
+// block that is below a checkpoint but not on its chain, or that don't match
+// their block, are taken out of the cache and set aside as dead with the
+// reason, so nothing downstream uses them. Blocks the RPCs couldn't serve are
+// left for the next fetch to try again.
+func verifyFetched(
 	client *evm.Client,
 	txsDB *util.FileDBCollection,
-	cacheKey string,
+	receiptsDB *util.FileDBCollection,
+	blocksDB *util.FileDBCollection,
+	verifiedDB *util.FileDBCollection,
+	deadDB *util.FileDBCollection,
 	network string,
-	txHash string,
-) bool {
-	var cachedTx types.Transaction
-	cacheFound, err := txsDB.Read(cacheKey, &cachedTx)
-	if err != nil {
-		fmt.Printf("Error reading tx cache for %s: %s\n", cacheKey, err.Error())
-		return true
+	txs []string,
+) {
+	type fetched struct {
+		hash    string
+		tx      types.Transaction
+		receipt types.Receipt
 	}
 
-	if cacheFound {
-		checkTransaction(&cachedTx, network, txHash)
-		return true
+	byBlock := make(map[common.Hash][]*fetched)
+	blocks := make([]evm.BlockRef, 0)
+
+	for _, txHash := range txs {
+		cacheKey := fmt.Sprintf("%s-%s", network, txHash)
+		if verifiedDB.Has(cacheKey) {
+			continue
+		}
+
+		f := &fetched{hash: txHash}
+		txFound, txErr := txsDB.Read(cacheKey, &f.tx)
+		receiptFound, receiptErr := receiptsDB.Read(cacheKey, &f.receipt)
+		if !txFound || !receiptFound || txErr != nil || receiptErr != nil {
+			fmt.Printf("--- Transaction %s on %s is not cached, so it can't be verified\n", txHash, network)
+			continue
+		}
+
+		blockHash := f.receipt.BlockHash
+		if _, found := byBlock[blockHash]; !found {
+			blocks = append(blocks, evm.BlockRef{Number: f.receipt.BlockNumber.Uint64(), Hash: blockHash})
+		}
+		byBlock[blockHash] = append(byBlock[blockHash], f)
+	}
+
+	if len(blocks) == 0 {
+		return
 	}
 
-	tx, err := client.GetTransaction(txHash)
+	fmt.Printf("Verifying %d blocks on %s against checkpoints\n", len(blocks), network)
+
+	headers, err := client.TrustedHeaders(blocks)
 	if err != nil {
-		fmt.Printf("Error fetching %s tx %s: %s\n", network, txHash, err.Error())
-		return false
+		fmt.Printf("--- Could not verify %s blocks: %s\n", network, err.Error())
+		return
+	}
+
+	verified, rejected, unverified, unverifiable := 0, 0, 0, 0
+	reject := func(reason string, bad ...*fetched) {
+		fmt.Printf("--- %s\n", reason)
+		rejected += len(bad)
+		for _, f := range bad {
+			cacheKey := fmt.Sprintf("%s-%s", network, f.hash)
+			for _, err := range []error{
+				txsDB.Delete(cacheKey),
+				receiptsDB.Delete(cacheKey),
+				deadDB.Write(cacheKey, deadTx{
+					Network: network,
+					Hash:    f.hash,
+					Error:   "Verification: " + reason,
+					Time:    time.Now().UTC(),
+				}),
+			} {
+				if err != nil {
+					fmt.Printf("Error setting aside unverified %s tx %s: %s\n", network, f.hash, err.Error())
+				}
+			}
+		}
+	}
+
+	for _, block := range blocks {
+		fetchedTxs := byBlock[block.Hash]
+
+		header, found := headers[block.Hash]
+		if !found {
+			if !client.Network.Covers(block.Number) {
+				unverifiable += len(fetchedTxs)
+				continue
+			}
+			reject(fmt.Sprintf("Block %s on %s is not on the chain of its checkpoint", block.Hash, network), fetchedTxs...)
+			err := blocksDB.Delete(fmt.Sprintf("%s-%s", network, block.Hash.Hex()))
+			if err != nil {
+				fmt.Printf("Error removing unverified %s block %s: %s\n", network, block.Hash, err.Error())
+			}
+			continue
+		}
+
+		verifiedBlock, receipts, err := client.VerifiedBlock(header)
+		if errors.Is(err, evm.BLOCK_MISMATCH) {
+			reject(err.Error(), fetchedTxs...)
+			continue
+		}
+		if err != nil {
+			fmt.Printf("--- %s\n", err.Error())
+			unverified += len(fetchedTxs)
+			continue
+		}
+
+		for _, f := range fetchedTxs {
+			err = evm.VerifyTransaction(verifiedBlock, receipts, f.hash, &f.tx, &f.receipt)
+			if err != nil {
+				reject(fmt.Sprintf("Transaction %s on %s failed verification: %s", f.hash, network, err.Error()), f)
+				continue
+			}
+
+			err = verifiedDB.Write(fmt.Sprintf("%s-%s", network, f.hash), block.Hash.Hex())
+			if err != nil {
+				fmt.Printf("Error recording verified transaction %s: %s\n", f.hash, err.Error())
+				continue
+			}
+			verified++
+		}
 	}
+
+	fmt.Printf("Verified %d transactions on %s\n", verified, network)
+	if rejected > 0 {
+		fmt.Printf("--- Set aside %d transactions on %s that failed verification, see %s (retry with fetch -retry-dead)\n", rejected, network, deadDB.Folder())
+	}
+	if unverified > 0 {
+		fmt.Printf("--- Could not verify %d transactions on %s for now, they will be retried on the next fetch\n", unverified, network)
+	}
+	if unverifiable > 0 {
+		fmt.Printf("--- %d transactions on %s are above every checkpoint, add a later one to verify them\n", unverifiable, network)
+	}
+}
+
+// For networks where a single request returns everything needed about a
+// transaction, so there are no receipts or blocks to fetch separately
+func fetchWhole[T any](
+	wg *sync.WaitGroup,
+	txsDB *util.FileDBCollection,
+	deadDB *util.FileDBCollection,
+	network string,
+	txs []string,
+	getTransaction func(hash string) (*T, error),
+) {
+	defer wg.Done()
+
+	fmt.Printf("Fetching %d transactions on %s\n", len(txs), network)
+
+	fetchWithRetries(deadDB, network, txs, func(unfetched []string) map[string]string {
+		retry := make(map[string]string)
+
+		for _, txid := range unfetched {
+			cacheKey := fmt.Sprintf("%s-%s", network, txid)
+
+			var cachedTx T
+			cacheFound, err := txsDB.Read(cacheKey, &cachedTx)
+			if err != nil {
+				fmt.Printf("Error reading tx cache for %s: %s\n", cacheKey, err.Error())
+				retry[txid] = "Cache: " + err.Error()
+				continue
+			}
+			if cacheFound {
+				continue
+			}
+
+			tx, err := getTransaction(txid)
+			if err != nil {
+				fmt.Printf("Error fetching %s tx %s: %s\n", network, txid, err.Error())
+				retry[txid] = err.Error()
+				continue
+			}
+
+			err = txsDB.Write(cacheKey, tx)
+			if err != nil {
+				fmt.Printf("Error writing tx cache for %s: %s\n", cacheKey, err.Error())
+			}
+		}
+
+		return retry
+	})
+
+	fmt.Printf("Done fetching transactions for %s\n", network)
+}
+
+// cacheTransaction writes a fetched transaction to the cache. Deposit
+// transactions are rewritten as plain transactions with the deposit fields
+// added back, so they can be read without the deposit type.
+func cacheTransaction(
+	txsDB *util.FileDBCollection,
+	network string,
+	txHash string,
+	tx *types.Transaction,
+) bool {
+	cacheKey := fmt.Sprintf("%s-%s", network, txHash)
+
 	if tx == nil {
 		fmt.Printf("Nil response for %s tx %s\n", network, txHash)
 		return false
@@ -216,7 +773,7 @@ func fetchTransaction(
 
 	checkTransaction(tx, network, txHash)
 
-	err = txsDB.Write(cacheKey, tx)
+	err := txsDB.Write(cacheKey, tx)
 	if err != nil {
 		fmt.Printf("Error caching transaction %s: %s\n", cacheKey, err.Error())
 	}
@@ -225,99 +782,17 @@ func fetchTransaction(
 	return true
 }
 
-func fetchTransactionReceipt(
-	client *evm.Client,
-	receiptsDB *util.FileDBCollection,
-	cacheKey string,
-	network string,
-	txHash string,
-) (bool, string) {
-	var cachedReceipt types.Receipt
-	cacheFound, err := receiptsDB.Read(cacheKey, &cachedReceipt)
-	if err != nil {
-		fmt.Printf("Error reading receipt cache for %s: %s\n", cacheKey, err.Error())
-		fmt.Printf("%#v\n", cachedReceipt)
-		return true, "<invalid-cache>"
-	}
-
-	if cacheFound {
-		checkReceipt(&cachedReceipt, network, txHash)
-		return true, cachedReceipt.BlockHash.String()
-	}
-
-	receipt, err := client.GetTransactionReceipt(txHash)
-	if err != nil {
-		fmt.Printf("Error fetching %s tx %s receipt: %s\n", network, txHash, err.Error())
-		return false, ""
-	}
-	if receipt == nil {
-		fmt.Printf("Nil response for %s tx %s receipt\n", network, txHash)
-		return false, ""
-	}
-
-	checkReceipt(receipt, network, txHash)
-
-	err = receiptsDB.Write(cacheKey, receipt)
-	if err != nil {
-		fmt.Printf("Error caching transaction %s receipt: %s\n", cacheKey, err.Error())
-	}
-
-	fmt.Printf("Fetched %s transaction %s receipt\n", network, txHash)
-	return true, receipt.BlockHash.String()
-}
-
-func fetchBlock(
-	client *evm.Client,
-	blocksDB *util.FileDBCollection,
-	network string,
-	blockHash string,
-) bool {
-	cacheKey := fmt.Sprintf("%s-%s", network, blockHash)
-
-	var cachedBlock types.Header
-	cacheFound, err := blocksDB.Read(cacheKey, &cachedBlock)
-	if err != nil {
-		fmt.Printf("Error reading cache for block %s: %s\n", cacheKey, err.Error())
-		return true
-	}
-
-	if cacheFound {
-		checkBlock(&cachedBlock, network, blockHash)
-		return true
-	}
-
-	block, err := client.GetBlock(blockHash)
-	if err != nil {
-		fmt.Printf("Error fetching %s block %s: %s\n", network, blockHash, err.Error())
-		return false
-	}
-	if block == nil {
-		fmt.Printf("Nil response for %s block %s\n", network, blockHash)
-		return false
-	}
-
-	checkBlock(block, network, blockHash)
-
-	err = blocksDB.Write(cacheKey, block)
-	if err != nil {
-		fmt.Printf("Error caching block %s: %s\n", cacheKey, err.Error())
-	}
-
-	fmt.Printf("Fetched %s block %s\n", network, blockHash)
-	return true
-}
-
-func fetchInternalTxs(client *evm.Client, network, txHash string) bool {
+func fetchInternalTxs(client *evm.Client, network, txHash string) error {
 	_, cached, err := client.GetInternalTransactions(txHash)
 	if err != nil {
 		fmt.Printf("Error fetching internal txs for %s tx %s: %s\n", network, txHash, err.Error())
-		return false
+		return err
 	}
 
 	if !cached {
 		fmt.Printf("Fetched internal txs for %s tx %s\n", network, txHash)
 	}
-	return true
+	return nil
 }
 
 func checkTransaction(tx *types.Transaction, network string, txHash string) {
@@ -2,59 +2,86 @@ package ctc
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/config"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
 	"github.com/ksmithbaylor/gohodl/internal/util"
 )
 
-func AnalyzeTransactions(db *util.FileDB, txHashes map[string][]string) {
-	if os.Getenv("SKIP_ANALYZE") != "" {
-		fmt.Println("Skipping transaction analyze step")
-		return
+var TXS_CSV_HEADERS = []string{
+	"timestamp",
+	"network",
+	"hash",
+	"blockhash",
+	"from",
+	"to",
+	"method",
+	"value",
+	"success",
+}
+
+func AnalyzeTransactions(db *util.FileDB, opts Options) error {
+	txHashes, err := readTransactionHashes(db, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, txsFound := db.OpenCollection("txs"); !txsFound {
+		return fmt.Errorf("Cannot analyze transactions without first fetching them")
+	}
+
+	// Transactions set aside by fetch have nothing trustworthy cached to analyze
+	if deadDB, found := db.OpenCollection("dead_txs"); found {
+		txHashes = filterDeadTransactions(deadDB, txHashes, false)
 	}
 
-	txsDB, txsFound := db.Collections["txs"]
-	if !txsFound || txsDB == nil {
-		fmt.Println("Cannot analyze transactions without first fetching them")
-		return
+	// When only analyzing some networks, keep what was there for the others
+	keptRows, err := readTxsCsvRows(db, func(network string) bool {
+		return !opts.IncludesNetwork(network)
+	})
+	if err != nil {
+		return err
 	}
 
 	txCsvFile, err := os.Create(getTxsCsvPath(db))
 	if err != nil {
-		fmt.Printf("Error creating csv file: %s\n", err.Error())
-		return
+		return fmt.Errorf("Error creating csv file: %w", err)
 	}
 	defer txCsvFile.Close()
 
 	txCsvWriter := csv.NewWriter(txCsvFile)
 	defer txCsvWriter.Flush()
 
-	err = txCsvWriter.Write([]string{
-		"timestamp",
-		"network",
-		"hash",
-		"blockhash",
-		"from",
-		"to",
-		"method",
-		"value",
-		"success",
-	})
+	err = txCsvWriter.Write(TXS_CSV_HEADERS)
 	if err != nil {
-		fmt.Printf("Error writing csv header row: %s\n", err.Error())
-		return
+		return fmt.Errorf("Error writing csv header row: %w", err)
 	}
 
+	err = txCsvWriter.WriteAll(keptRows)
+	if err != nil {
+		return fmt.Errorf("Error writing kept csv rows: %w", err)
+	}
+
+	failed := 0
+
 	for network, networkTxHashes := range txHashes {
+		if config.Config.EvmNetworkByName(network).Name == "" {
+			fmt.Printf("Non-EVM networks (like %s) not implemented yet\n", network)
+			continue
+		}
+
 		for _, txHash := range networkTxHashes {
 			tx, receipt, block, err := readTransactionBundle(db, network, txHash)
 			if err != nil {
 				fmt.Printf("Could not read %s transaction %s: %s\n", network, txHash, err.Error())
+				failed++
 				continue
 			}
 
@@ -62,44 +89,103 @@ func AnalyzeTransactions(db *util.FileDB, txHashes map[string][]string) {
 				panic("nil block for " + network + " / " + receipt.BlockHash.Hex())
 			}
 
-			from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			err = txCsvWriter.Write(txsCsvRow(network, txHash, tx, receipt, block))
 			if err != nil {
-				from = common.HexToAddress(evm.ZERO_ADDRESS)
+				fmt.Printf("Error writing csv row for %s tx %s: %s\n", network, txHash, err.Error())
+				failed++
 			}
+		}
+	}
 
-			method := ""
-			if len(tx.Data()) >= 4 {
-				method = "0x" + common.Bytes2Hex(tx.Data()[:4])
-			}
+	if failed > 0 {
+		return fmt.Errorf("Could not analyze %d transactions", failed)
+	}
 
-			status := "success"
-			if receipt.Status == 0 {
-				status = "failed"
-			}
+	fmt.Println("Done analyzing transactions!")
 
-			to := evm.ZERO_ADDRESS
-			if tx.To() != nil {
-				to = tx.To().Hex()
-			}
+	return nil
+}
 
-			err = txCsvWriter.Write([]string{
-				strconv.Itoa(int(block.Time)),
-				network,
-				txHash,
-				block.Hash().Hex(),
-				from.Hex(),
-				to,
-				method,
-				tx.Value().String(),
-				status,
-			})
-			if err != nil {
-				fmt.Printf("Error writing csv row for %s tx %s: %s\n", network, txHash, err.Error())
-			}
+// txsCsvRow summarizes a fetched transaction as a row of the transactions CSV.
+func txsCsvRow(network, txHash string, tx *types.Transaction, receipt *types.Receipt, block *types.Header) []string {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		from = common.HexToAddress(evm.ZERO_ADDRESS)
+	}
+
+	method := ""
+	if len(tx.Data()) >= 4 {
+		method = "0x" + common.Bytes2Hex(tx.Data()[:4])
+	}
+
+	status := "success"
+	if receipt.Status == 0 {
+		status = "failed"
+	}
+
+	to := evm.ZERO_ADDRESS
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
+	return []string{
+		strconv.Itoa(int(block.Time)),
+		network,
+		txHash,
+		block.Hash().Hex(),
+		from.Hex(),
+		to,
+		method,
+		tx.Value().String(),
+		status,
+	}
+}
+
+func txInfoFromRow(row []string) (evm.TxInfo, error) {
+	timestamp, err := strconv.Atoi(row[0])
+	if err != nil {
+		return evm.TxInfo{}, fmt.Errorf("Invalid timestamp in txs csv: %s", row[0])
+	}
+
+	return evm.TxInfo{
+		Time:      timestamp,
+		Network:   row[1],
+		Hash:      row[2],
+		BlockHash: row[3],
+		From:      row[4],
+		To:        row[5],
+		Method:    row[6],
+		Value:     row[7],
+		Success:   row[8] == "success",
+	}, nil
+}
+
+// readTxsCsvRows returns the rows of the transactions CSV for the networks
+// matching the filter, without the header. A missing CSV has no rows.
+func readTxsCsvRows(db *util.FileDB, includeNetwork func(network string) bool) ([][]string, error) {
+	txCsvFile, err := os.Open(getTxsCsvPath(db))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error opening txs csv: %w", err)
+	}
+	defer txCsvFile.Close()
+
+	rows, err := csv.NewReader(txCsvFile).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading txs csv: %w", err)
+	}
+
+	kept := make([][]string, 0)
+	for _, row := range rows {
+		if row[0] == TXS_CSV_HEADERS[0] || !includeNetwork(row[1]) {
+			continue
 		}
+		kept = append(kept, row)
 	}
 
-	fmt.Println("Done analyzing transactions!")
+	return kept, nil
 }
 
 func getTxsCsvPath(db *util.FileDB) string {
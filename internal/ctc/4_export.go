@@ -5,79 +5,177 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"sort"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 
+	"github.com/ksmithbaylor/gohodl/internal/config"
 	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
 	"github.com/ksmithbaylor/gohodl/internal/generic"
+	handler_types "github.com/ksmithbaylor/gohodl/internal/handlers"
 	handlers "github.com/ksmithbaylor/gohodl/internal/handlers/kevin"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
 	"github.com/ksmithbaylor/gohodl/internal/util"
 )
 
-// https://www.unixtimestamp.com/
-var END_OF_2023 = 1704067199
-var END_OF_2024 = 1735689599
-var END_OF_2025 = 1767225599
+// ExportTransactions writes a file in each format for each of the periods to
+// export.
+func ExportTransactions(db *util.FileDB, clients generic.AllNodeClients, opts Options) error {
+	periods, err := opts.ExportPeriods()
+	if err != nil {
+		return err
+	}
 
-func ExportTransactions(db *util.FileDB, clients generic.AllNodeClients) {
-	if os.Getenv("SKIP_EXPORT") != "" {
-		fmt.Println("Skipping transaction export step")
-		return
+	books, err := ledgerBooks(db)
+	if err != nil {
+		return err
+	}
+	exporters, err := ledger.Exporters(opts.Formats, books)
+	if err != nil {
+		return err
 	}
 
-	txCsvFile, err := os.Open(getTxsCsvPath(db))
-	if txCsvFile == nil || err != nil {
-		fmt.Println("Transactions CSV not written yet, please run analyze step")
-		return
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+
+	for _, period := range periods {
+		fmt.Printf("Exporting %s\n", period)
+		periodFailed, err := exportPeriod(db, clients, opts, period, overrides, exporters)
+		if err != nil {
+			return fmt.Errorf("Could not export %s: %w", period.Name, err)
+		}
+		failed += periodFailed
 	}
-	defer txCsvFile.Close()
 
-	ctcCsvFile, err := os.Create(getCtcCsvPath(db))
+	if opts.Strict && failed > 0 {
+		return fmt.Errorf("%d transactions could not be handled or verified (strict mode)", failed)
+	}
+
+	return nil
+}
+
+// loadOverrides reads the overrides file from the config, if there is one.
+func loadOverrides() (*ctc_util.Overrides, error) {
+	if config.Config.Overrides == "" {
+		return nil, nil
+	}
+	return ctc_util.LoadOverrides(config.Config.Overrides)
+}
+
+func exportPeriod(
+	db *util.FileDB,
+	clients generic.AllNodeClients,
+	opts Options,
+	period tax_period.Period,
+	overrides *ctc_util.Overrides,
+	exporters []ledger.Exporter,
+) (int, error) {
+	result, err := handlePeriod(db, clients, opts, period, overrides)
 	if err != nil {
-		fmt.Printf("Error creating CTC CSV file: %s\n", err.Error())
-		return
+		return 0, err
 	}
-	defer ctcCsvFile.Close()
 
-	txCsvReader := csv.NewReader(txCsvFile)
-	ctcCsvWriter := csv.NewWriter(ctcCsvFile)
-	defer ctcCsvWriter.Flush()
+	for _, warning := range ctc_util.CheckSequence(result.ctcTxs) {
+		fmt.Println(warning)
+	}
 
-	err = ctcCsvWriter.Write(ctc_util.CTC_HEADERS)
+	entries, err := ledger.FromCTCAll(result.ctcTxs)
 	if err != nil {
-		fmt.Printf("Error writing CTC CSV headers: %s\n", err.Error())
-		return
+		return 0, err
+	}
+	for _, exporter := range exporters {
+		err = writeLedger(getLedgerPath(db, period, exporter), exporter, entries)
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	rowsToWrite := make([][]string, 0)
+	fmt.Printf("%d transactions handled out of %d (%.2f%%), %d remaining\n", result.handled, result.total, 100.0*float32(result.handled)/float32(result.total), result.total-result.handled)
+	if result.unhandled > 0 {
+		fmt.Printf("%d transactions temporarily not handled (will be %.2f%% when done)\n", result.unhandled, 100.0*float32(result.handled+result.unhandled)/float32(result.total))
+	}
+	if len(result.overridden) > 0 {
+		fmt.Printf("%d overrides applied:\n", len(result.overridden))
+		for _, summary := range result.overridden {
+			fmt.Println("  " + summary)
+		}
+	}
+	err = writeFailures(db, period, result.failures)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.failures) > 0 {
+		fmt.Printf("%d transactions failed, see %s\n", len(result.failures), getFailuresCsvPath(db, period))
+	}
+	if result.unverified > 0 {
+		fmt.Printf("%d transactions are not verified against a checkpoint, run fetch again or add a later checkpoint\n", result.unverified)
+	}
+	fmt.Println("Finished exporting transactions!")
 
-	privateImplementation := handlers.Implementation
-	ctcWriter := func(rows ...[]string) error {
-		rowsToWrite = append(rowsToWrite, rows...)
-		return nil
+	return len(result.failures) + result.unverified, nil
+}
+
+// What handling the transactions of a period produced
+type periodResult struct {
+	ctcTxs     []ctc_util.CTCTransaction // Sorted
+	total      int
+	handled    int
+	unhandled  int // Handled for now with NOT_HANDLED
+	unverified int // On networks with checkpoints, but not verified against one
+	overridden []string
+	failures   []*handler_types.HandlerError
+}
+
+// handlePeriod runs every transaction of a period through its handler or
+// exporter, collecting the sorted CTC transactions.
+func handlePeriod(
+	db *util.FileDB,
+	clients generic.AllNodeClients,
+	opts Options,
+	period tax_period.Period,
+	overrides *ctc_util.Overrides,
+) (*periodResult, error) {
+	txCsvFile, err := os.Open(getTxsCsvPath(db))
+	if txCsvFile == nil || err != nil {
+		return nil, fmt.Errorf("Transactions CSV not written yet, please run analyze step")
 	}
-	txReader := func(network, hash string) (
-		*types.Transaction,
-		*types.Receipt,
-		*types.Header,
-		error,
-	) {
-		return readTransactionBundle(db, network, hash)
+	defer txCsvFile.Close()
+
+	txCsvReader := csv.NewReader(txCsvFile)
+	verifiedDB, _ := db.OpenCollection("verified_txs")
+
+	result := &periodResult{
+		ctcTxs:     make([]ctc_util.CTCTransaction, 0),
+		overridden: make([]string, 0),
+		failures:   make([]*handler_types.HandlerError, 0),
 	}
 
-	totalTxs := 0
-	handledTxs := 0
-	unhandled := 0
+	ctcWriter := func(exported ...ctc_util.CTCTransaction) error {
+		for _, ctcTx := range exported {
+			if err := ctcTx.Validate(); err != nil {
+				return err
+			}
+			// Rows with their own timestamp (like overrides) can fall outside the
+			// period of their transaction
+			if period.Contains(ctcTx.Timestamp) {
+				result.ctcTxs = append(result.ctcTxs, ctcTx)
+			}
+		}
+		return nil
+	}
 
 	for {
 		row, err := txCsvReader.Read()
 		if err != nil {
 			if err != io.EOF {
-				fmt.Printf("Error reading txs CSV row: %s\n", err.Error())
+				return nil, fmt.Errorf("Error reading txs CSV row: %w", err)
 			}
 			break
 		}
@@ -87,97 +185,183 @@ func ExportTransactions(db *util.FileDB, clients generic.AllNodeClients) {
 			continue
 		}
 
-		timestamp, err := strconv.Atoi(row[0])
+		info, err := txInfoFromRow(row)
 		if err != nil {
-			panic("Invalid timestamp: " + row[0])
+			return nil, err
 		}
 
-		if timestamp <= END_OF_2024 || timestamp > END_OF_2025 {
+		if !period.Contains(time.Unix(int64(info.Time), 0)) || !opts.IncludesNetwork(info.Network) {
 			continue
 		}
 
-		totalTxs++
-		info := evm.TxInfo{
-			Time:      timestamp,
-			Network:   row[1],
-			Hash:      row[2],
-			BlockHash: row[3],
-			From:      row[4],
-			To:        row[5],
-			Method:    row[6],
-			Value:     row[7],
-			Success:   row[8] == "success",
-		}
+		result.total++
 
 		client, ok := clients[info.Network]
 		if !ok {
-			fmt.Printf("No client for network %s\n", info.Network)
-			return
+			return nil, fmt.Errorf("No client for network %s", info.Network)
 		}
 		evmClient, ok := client.(*evm.Client)
 		if !ok {
-			fmt.Printf("Non-EVM networks (like %s) not implemented yet\n", info.Network)
-			return
+			return nil, fmt.Errorf("Non-EVM networks (like %s) not implemented yet", info.Network)
+		}
+
+		if len(evmClient.Network.Checkpoints) > 0 && (verifiedDB == nil || !verifiedDB.Has(fmt.Sprintf("%s-%s", info.Network, info.Hash))) {
+			fmt.Println("UNVERIFIED:", info.Network, info.Hash)
+			result.unverified++
 		}
 
-		handled, err := privateImplementation.HandleTransaction(&info, evmClient, txReader, ctcWriter)
+		handled, override, err := handleEvmTransaction(db, &info, evmClient, overrides, ctcWriter)
+		if override != "" {
+			result.overridden = append(result.overridden, override)
+		}
 		if handled {
 			if err == nil {
-				handledTxs++
+				result.handled++
 			} else if err == handlers.NOT_HANDLED {
 				fmt.Println("NOT_HANDLED:", info.Network, info.Hash)
-				unhandled++
+				result.unhandled++
 			}
 		}
 		if err != nil && err != handlers.NOT_HANDLED {
-			fmt.Println(err.Error())
-			return
+			// Errors from outside a handler, like writing its rows, have no
+			// handler to name
+			handlerErr := handler_types.AsHandlerError(err, &info, "export")
+			fmt.Println("FAILED:", handlerErr.Error())
+			result.failures = append(result.failures, handlerErr)
 		}
 	}
 
-	usedIds := make(map[string]struct{})
+	solanaTxs, solanaHandled, solanaFailures := exportSolanaTransactions(db, opts, period, ctcWriter)
+	result.total += solanaTxs
+	result.handled += solanaHandled
+	result.failures = append(result.failures, solanaFailures...)
 
-	sort.Slice(rowsToWrite, func(i, j int) bool {
-		return rowsToWrite[i][0] < rowsToWrite[j][0]
-	})
+	cosmosTxs, cosmosHandled, cosmosFailures := exportCosmosTransactions(db, opts, period, ctcWriter)
+	result.total += cosmosTxs
+	result.handled += cosmosHandled
+	result.failures = append(result.failures, cosmosFailures...)
 
-	for i, row := range rowsToWrite {
-		id := row[11]
+	utxoTxs, utxoHandled, utxoFailures := exportUtxoTransactions(db, opts, period, ctcWriter)
+	result.total += utxoTxs
+	result.handled += utxoHandled
+	result.failures = append(result.failures, utxoFailures...)
 
-		// Check for duplicates
-		if _, alreadyUsed := usedIds[id]; alreadyUsed {
-			fmt.Printf("DUPLICATE: %s\n", id)
-		}
-		usedIds[id] = struct{}{}
+	ctc_util.SortTransactions(result.ctcTxs)
 
-		// Check for interleaving
-		if i > 0 {
-			if idx := strings.LastIndex(id, "-"); idx != -1 {
-				suffix := id[idx+1:]
-				if num, err := strconv.Atoi(suffix); err == nil && num >= 2 {
-					baseID := id[:idx]
-					expectedPrevID := baseID + "-" + strconv.Itoa(num-1)
-					prevID := rowsToWrite[i-1][11]
-					if prevID != expectedPrevID {
-						fmt.Printf("INTERLEAVED: %s should be preceded by %s, but was preceded by %s\n", id, expectedPrevID, prevID)
-					}
-				}
-			}
+	return result, nil
+}
+
+// handleEvmTransaction exports the rows of one EVM transaction, from its
+// override or its handler. It also returns the summary of the override
+// applied, if any.
+func handleEvmTransaction(
+	db *util.FileDB,
+	info *evm.TxInfo,
+	client *evm.Client,
+	overrides *ctc_util.Overrides,
+	export handler_types.CTCWriter,
+) (bool, string, error) {
+	override, hasOverride := overrides.Find(info.Network, info.Hash)
+	if hasOverride && override.SkipsHandler() {
+		rows := override.ReplacementRows(time.Unix(int64(info.Time), 0))
+		return true, override.Summary(), export(rows...)
+	}
+
+	rows := make([]ctc_util.CTCTransaction, 0)
+	collect := func(handled ...ctc_util.CTCTransaction) error {
+		rows = append(rows, handled...)
+		return nil
+	}
+	txReader := func(network, hash string) (
+		*types.Transaction,
+		*types.Receipt,
+		*types.Header,
+		error,
+	) {
+		return readTransactionBundle(db, network, hash)
+	}
+
+	handled, err := handlers.Implementation.HandleTransaction(info, client, txReader, collect)
+
+	summary := ""
+	if hasOverride && handled && err == nil {
+		override.Patch(rows)
+		summary = override.Summary()
+	}
+
+	// Partial rows from a failed handler would be misleading
+	if err != nil && err != handlers.NOT_HANDLED {
+		return handled, summary, err
+	}
+	for _, row := range rows {
+		if invalid := row.Validate(); invalid != nil {
+			return handled, summary, handler_types.AsHandlerError(invalid, info, "validation")
 		}
 	}
 
-	err = ctcCsvWriter.WriteAll(rowsToWrite)
+	if exportErr := export(rows...); exportErr != nil {
+		return handled, summary, exportErr
+	}
+
+	return handled, summary, err
+}
+
+// writeLedger serializes the checked and sorted entries of a period in one
+// format, which only happens once everything has been handled.
+func writeLedger(path string, exporter ledger.Exporter, entries []ledger.Entry) error {
+	file, err := os.Create(path)
 	if err != nil {
-		fmt.Printf("Error writing CTC CSV: %s\n", err.Error())
+		return fmt.Errorf("Error creating %s export file: %w", exporter.Name(), err)
 	}
+	defer file.Close()
 
-	fmt.Printf("%d transactions handled out of %d (%.2f%%), %d remaining\n", handledTxs, totalTxs, 100.0*float32(handledTxs)/float32(totalTxs), totalTxs-handledTxs)
-	if unhandled > 0 {
-		fmt.Printf("%d transactions temporarily not handled (will be %.2f%% when done)\n", unhandled, 100.0*float32(handledTxs+unhandled)/float32(totalTxs))
+	err = exporter.Write(file, entries)
+	if err != nil {
+		return fmt.Errorf("Error writing %s export: %w", exporter.Name(), err)
 	}
-	fmt.Println("Finished exporting transactions!")
+
+	return nil
+}
+
+func getLedgerPath(db *util.FileDB, period tax_period.Period, exporter ledger.Exporter) string {
+	return fmt.Sprintf("%s/%s-%s.%s", db.Path, exporter.Name(), period.Name, exporter.Extension())
+}
+
+var FAILURES_CSV_HEADERS = []string{"network", "hash", "handler", "reason", "panic"}
+
+// writeFailures writes the transactions that couldn't be handled next to the
+// CTC CSV, replacing any from an earlier export.
+func writeFailures(db *util.FileDB, period tax_period.Period, failures []*handler_types.HandlerError) error {
+	failuresCsvFile, err := os.Create(getFailuresCsvPath(db, period))
+	if err != nil {
+		return fmt.Errorf("Error creating failures CSV file: %w", err)
+	}
+	defer failuresCsvFile.Close()
+
+	failuresCsvWriter := csv.NewWriter(failuresCsvFile)
+	defer failuresCsvWriter.Flush()
+
+	err = failuresCsvWriter.Write(FAILURES_CSV_HEADERS)
+	if err != nil {
+		return fmt.Errorf("Error writing failures CSV headers: %w", err)
+	}
+
+	for _, failure := range failures {
+		err = failuresCsvWriter.Write([]string{
+			failure.Network,
+			failure.Hash,
+			failure.Handler,
+			failure.Reason,
+			strconv.FormatBool(failure.Panic),
+		})
+		if err != nil {
+			return fmt.Errorf("Error writing failures CSV: %w", err)
+		}
+	}
+
+	return nil
 }
 
-func getCtcCsvPath(db *util.FileDB) string {
-	return db.Path + "/ctc.csv"
+func getFailuresCsvPath(db *util.FileDB, period tax_period.Period) string {
+	return fmt.Sprintf("%s/failures-%s.csv", db.Path, period.Name)
 }
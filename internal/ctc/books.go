@@ -0,0 +1,82 @@
+package ctc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/ksmithbaylor/gohodl/internal/prices"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/shopspring/decimal"
+)
+
+// ledgerBooks builds what the plain-text accounting exports need from the
+// config: account names from the labels of owned addresses, and lot costs from
+// the configured price sources.
+func ledgerBooks(db *util.FileDB) (ledger.Books, error) {
+	location, err := config.Config.Tax.Location()
+	if err != nil {
+		return ledger.Books{}, err
+	}
+
+	labels := make(map[string]string)
+	for _, network := range config.Config.AllNetworks() {
+		for label, address := range config.Config.OwnedAddresses(network) {
+			labels[strings.ToLower(address)] = label
+		}
+	}
+
+	return ledger.Books{
+		Labels:   labels,
+		Fiat:     config.Config.Prices.FiatAsset().Symbol,
+		Location: location,
+		Price:    ledgerPrices(db),
+	}, nil
+}
+
+// ledgerPrices looks up the prices of native assets, which are the only ones a
+// ledger currency can be mapped back to. The price sources are only loaded
+// once a price is needed, so exports that don't need any work without them.
+func ledgerPrices(db *util.FileDB) ledger.PriceFunc {
+	var source prices.Chain
+	var sourceErr error
+	loaded := false
+	fiat := config.Config.Prices.FiatAsset()
+
+	return func(currency, network string, at time.Time) (decimal.Decimal, bool) {
+		if !loaded {
+			source, sourceErr = prices.NewSource(config.Config.Prices, db)
+			if sourceErr != nil {
+				fmt.Printf("Exporting without prices: %s\n", sourceErr.Error())
+			}
+			loaded = true
+		}
+		if sourceErr != nil {
+			return decimal.Zero, false
+		}
+
+		asset, found := nativeAsset(network, currency)
+		if !found {
+			return decimal.Zero, false
+		}
+
+		price, err := source.PriceAt(asset, fiat, at)
+		if err != nil {
+			return decimal.Zero, false
+		}
+		return price.Value, true
+	}
+}
+
+func nativeAsset(network, symbol string) (core.Asset, bool) {
+	if evmNetwork := config.Config.EvmNetworkByName(network); evmNetwork.Name != "" {
+		return evmNetwork.NativeAsset(), evmNetwork.NativeAssetSymbol == symbol
+	}
+	if solanaNetwork := config.Config.SolanaNetworkByName(network); solanaNetwork.Name != "" {
+		return solanaNetwork.NativeAsset(), solanaNetwork.NativeAssetSymbol == symbol
+	}
+	return core.Asset{}, false
+}
@@ -0,0 +1,225 @@
+package ctc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/k0kubun/pp/v3"
+	"github.com/ksmithbaylor/gohodl/internal/abis"
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/evm_util"
+	"github.com/ksmithbaylor/gohodl/internal/generic"
+	handlers "github.com/ksmithbaylor/gohodl/internal/handlers/kevin"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Explain prints everything known about a fetched EVM transaction, and how it
+// would be exported. If network is empty, every network is searched for the
+// hash.
+func Explain(db *util.FileDB, clients generic.AllNodeClients, opts Options, network, hash string) error {
+	network, err := findNetwork(db, opts, network, hash)
+	if err != nil {
+		return err
+	}
+
+	client, ok := clients[network]
+	if !ok {
+		return fmt.Errorf("No client for network %s", network)
+	}
+	evmClient, ok := client.(*evm.Client)
+	if !ok {
+		return fmt.Errorf("Non-EVM networks (like %s) can't be explained yet", network)
+	}
+
+	tx, receipt, block, err := readTransactionBundle(db, network, hash)
+	if err != nil {
+		return err
+	}
+
+	info, err := txInfoFromRow(txsCsvRow(network, hash, tx, receipt, block))
+	if err != nil {
+		return err
+	}
+
+	explainSection("Transaction")
+	pp.Println(info)
+	explainJSON(tx)
+
+	explainSection("Receipt")
+	explainJSON(receipt)
+
+	explainSection("Block")
+	explainJSON(block)
+
+	explainSection("Calldata")
+	explainCalldata(tx)
+
+	explainSection("Events")
+	err = explainEvents(network, receipt.Logs)
+	if err != nil {
+		return err
+	}
+
+	explainSection("Net transfers")
+	netTransfers, err := evm_util.NetTransfersWithNfts(evmClient, &info, receipt.Logs)
+	if err != nil {
+		return fmt.Errorf("Could not get net transfers: %w", err)
+	}
+	fmt.Print(netTransfers.String())
+
+	explainSection("Net transfers (only mine)")
+	fmt.Print(netTransfers.OnlyMine().String())
+
+	explainSection("Internal transactions")
+	internalTxs, _, err := evmClient.GetInternalTransactions(hash)
+	if err != nil {
+		fmt.Printf("Could not get internal transactions: %s\n", err.Error())
+	} else if len(internalTxs) == 0 {
+		fmt.Println("None")
+	} else {
+		pp.Println(internalTxs)
+	}
+
+	explainSection("Handler")
+	for _, explanation := range handlers.Implementation.Explain(&info) {
+		if explanation.Matched {
+			fmt.Println(explanation)
+		}
+	}
+
+	explainSection("CTC rows")
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+	rows := make([]ctc_util.CTCTransaction, 0)
+	collect := func(handled ...ctc_util.CTCTransaction) error {
+		rows = append(rows, handled...)
+		return nil
+	}
+	handled, override, err := handleEvmTransaction(db, &info, evmClient, overrides, collect)
+	if override != "" {
+		fmt.Println("Override applied:", override)
+	}
+	for _, row := range rows {
+		explainRow(row)
+	}
+	switch {
+	case err == handlers.NOT_HANDLED:
+		fmt.Println("Not handled yet")
+	case err != nil:
+		fmt.Printf("Could not handle: %s\n", err.Error())
+	case !handled:
+		fmt.Println("No handler matched")
+	case len(rows) == 0:
+		fmt.Println("Handled without any rows")
+	}
+
+	return nil
+}
+
+// findNetwork returns the network a fetched transaction is on, searching every
+// network if it isn't given.
+func findNetwork(db *util.FileDB, opts Options, network, hash string) (string, error) {
+	txsDB, found := db.OpenCollection("txs")
+	if !found {
+		return "", fmt.Errorf("Cannot read transactions without first fetching them")
+	}
+
+	if network != "" {
+		return network, nil
+	}
+
+	networks := make([]string, 0)
+	for _, evmNetwork := range config.Config.EvmNetworks {
+		name := string(evmNetwork.Name)
+		if opts.IncludesNetwork(name) && txsDB.Has(fmt.Sprintf("%s-%s", name, hash)) {
+			networks = append(networks, name)
+		}
+	}
+
+	switch len(networks) {
+	case 0:
+		return "", fmt.Errorf("Transaction %s not fetched on any network", hash)
+	case 1:
+		return networks[0], nil
+	default:
+		return "", fmt.Errorf("Transaction %s found on several networks, pick one of: %s", hash, strings.Join(networks, ", "))
+	}
+}
+
+func explainSection(title string) {
+	fmt.Printf("\n===== %s %s\n", title, strings.Repeat("=", max(0, 72-len(title))))
+}
+
+func explainJSON(value json.Marshaler) {
+	data, err := value.MarshalJSON()
+	if err != nil {
+		fmt.Printf("Could not marshal: %s\n", err.Error())
+		return
+	}
+
+	var indented any
+	if json.Unmarshal(data, &indented) == nil {
+		data, _ = json.MarshalIndent(indented, "", "  ")
+	}
+	fmt.Println(string(data))
+}
+
+func explainCalldata(tx *types.Transaction) {
+	if len(tx.Data()) < 4 {
+		fmt.Println("None")
+		return
+	}
+
+	calls := abis.DecodeCalldata(tx.Data())
+	if len(calls) == 0 {
+		fmt.Printf("No known ABI has method 0x%x\n", tx.Data()[:4])
+		return
+	}
+
+	for _, call := range calls {
+		fmt.Printf("%s: %s\n", call.Abi, call.Method)
+		if call.Err != nil {
+			fmt.Printf("  Could not decode arguments: %s\n", call.Err.Error())
+			continue
+		}
+		pp.Println(call.Args)
+	}
+}
+
+func explainEvents(network string, logs []*types.Log) error {
+	fmt.Printf("%d logs\n", len(logs))
+
+	for _, name := range abis.AllNames() {
+		events, err := evm.ParseKnownEvents(network, logs, *abis.ALL[name])
+		if err != nil {
+			return fmt.Errorf("Could not parse %s events: %w", name, err)
+		}
+		for _, event := range events {
+			fmt.Printf("%s: %s from %s\n", name, event.Name, event.Contract.Hex())
+			pp.Println(event.Data)
+		}
+	}
+
+	return nil
+}
+
+func explainRow(ctcTx ctc_util.CTCTransaction) {
+	if err := ctcTx.Validate(); err != nil {
+		fmt.Printf("%s (invalid: %s)\n", ctcTx.ID, err.Error())
+		return
+	}
+
+	values := ctcTx.ToCSV()
+	fmt.Println(ctcTx.ID)
+	for i, header := range ctc_util.CTC_HEADERS {
+		if values[i] != "" && header != ctc_util.CTC_ID_HEADER {
+			fmt.Printf("  %-36s %s\n", header+":", values[i])
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package ctc
+
+import (
+	"fmt"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/cosmos"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	handler_types "github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Like Solana, Cosmos transactions don't go through the EVM handlers, and are
+// exported straight from their decoded messages.
+func exportCosmosTransactions(
+	db *util.FileDB,
+	opts Options,
+	period tax_period.Period,
+	ctcWriter func(...ctc_util.CTCTransaction) error,
+) (total int, handled int, failures []*handler_types.HandlerError) {
+	cosmosTxsDB, found := db.OpenCollection("cosmos_txs")
+	if !found {
+		return 0, 0, nil
+	}
+
+	keys, err := cosmosTxsDB.List()
+	if err != nil {
+		fmt.Printf("Error listing keys in cosmos txs collection: %s\n", err.Error())
+		return 0, 0, nil
+	}
+
+	for _, key := range keys {
+		networkName, hash, found := splitCacheKey(key)
+		if !found || !opts.IncludesNetwork(networkName) {
+			continue
+		}
+
+		network := config.Config.CosmosNetworkByName(networkName)
+		if network.Name == "" {
+			fmt.Printf("No config for cosmos network %s\n", networkName)
+			continue
+		}
+
+		var tx cosmos.TxResponse
+		found, err := cosmosTxsDB.Read(key, &tx)
+		if err != nil || !found {
+			fmt.Printf("Could not read cosmos transaction %s\n", key)
+			continue
+		}
+
+		timestamp, err := tx.Time()
+		if err != nil {
+			fmt.Println(err.Error())
+			continue
+		}
+		if !period.Contains(timestamp) {
+			continue
+		}
+
+		total++
+		rows, err := cosmosTransactionRows(network, &tx)
+		if err == nil {
+			err = ctcWriter(rows...)
+		}
+		if err != nil {
+			failure := handler_types.AsHandlerError(err, &evm.TxInfo{Network: networkName, Hash: hash}, "cosmos")
+			fmt.Println("FAILED:", failure.Error())
+			failures = append(failures, failure)
+			continue
+		}
+		handled++
+	}
+
+	return total, handled, failures
+}
+
+func cosmosTransactionRows(network cosmos.Network, tx *cosmos.TxResponse) ([]ctc_util.CTCTransaction, error) {
+	isMine := func(addr string) bool {
+		return config.Config.IsMyCosmosAddress(network.Name, addr)
+	}
+
+	entries, unsupported, err := cosmos.DecodeEntries(network, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, msgType := range unsupported {
+		fmt.Printf("Unsupported cosmos message %s in %s\n", msgType, tx.TxHash)
+	}
+
+	ctcTxs := make([]*ctc_util.CTCTransaction, 0)
+
+	for _, entry := range entries {
+		fromMine, toMine := isMine(entry.From), isMine(entry.To)
+
+		var txType ctc_util.CTCTransactionType
+		switch entry.Kind {
+		case cosmos.Transfer:
+			if fromMine && toMine {
+				continue
+			} else if fromMine {
+				txType = ctc_util.CTCSend
+			} else if toMine {
+				txType = ctc_util.CTCReceive
+			}
+		case cosmos.Delegate:
+			if fromMine {
+				txType = ctc_util.CTCStakingDeposit
+			}
+		case cosmos.Undelegate:
+			if toMine {
+				txType = ctc_util.CTCStakingWithdrawal
+			}
+		case cosmos.StakingReward:
+			if toMine {
+				txType = ctc_util.CTCStaking
+			}
+		case cosmos.BridgeOut:
+			if fromMine {
+				txType = ctc_util.CTCBridgeOut
+			}
+		case cosmos.BridgeIn:
+			if toMine {
+				txType = ctc_util.CTCBridgeIn
+			}
+		}
+
+		if txType == "" {
+			continue
+		}
+
+		ctcTxs = append(ctcTxs, &ctc_util.CTCTransaction{
+			Timestamp:    entry.Time,
+			Type:         txType,
+			BaseCurrency: entry.Amount.Asset.Symbol,
+			BaseAmount:   entry.Amount.Value,
+			BaseAsset:    entry.Amount.Asset,
+			From:         entry.From,
+			To:           entry.To,
+			Blockchain:   network.Name.String(),
+			Description:  string(entry.Kind),
+		})
+	}
+
+	fees, payer, err := cosmos.Fee(network, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if isMine(payer) {
+		timestamp, err := tx.Time()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, fee := range fees {
+			if i == 0 && len(ctcTxs) > 0 {
+				ctcTxs[0].FeeCurrency = fee.Asset.Symbol
+				ctcTxs[0].FeeAmount = fee.Value
+				ctcTxs[0].FeeAsset = fee.Asset
+				continue
+			}
+
+			ctcTxs = append(ctcTxs, &ctc_util.CTCTransaction{
+				Timestamp:    timestamp,
+				Type:         ctc_util.CTCFee,
+				BaseCurrency: fee.Asset.Symbol,
+				BaseAmount:   fee.Value,
+				BaseAsset:    fee.Asset,
+				From:         payer,
+				Blockchain:   network.Name.String(),
+			})
+		}
+	}
+
+	rows := make([]ctc_util.CTCTransaction, len(ctcTxs))
+	for i, ctcTx := range ctcTxs {
+		ctcTx.ID = tx.TxHash
+		if len(ctcTxs) > 1 {
+			ctcTx.ID = fmt.Sprintf("%s-%d", tx.TxHash, i+1)
+		}
+		rows[i] = *ctcTx
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,161 @@
+package ctc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	handler_types "github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/ksmithbaylor/gohodl/internal/solana"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Solana transactions don't go through the EVM handlers, and are exported
+// straight from their balance changes as sends and receives.
+func exportSolanaTransactions(
+	db *util.FileDB,
+	opts Options,
+	period tax_period.Period,
+	ctcWriter func(...ctc_util.CTCTransaction) error,
+) (total int, handled int, failures []*handler_types.HandlerError) {
+	solanaTxsDB, found := db.OpenCollection("solana_txs")
+	if !found {
+		return 0, 0, nil
+	}
+
+	keys, err := solanaTxsDB.List()
+	if err != nil {
+		fmt.Printf("Error listing keys in solana txs collection: %s\n", err.Error())
+		return 0, 0, nil
+	}
+
+	for _, key := range keys {
+		networkName, hash, found := splitCacheKey(key)
+		if !found || !opts.IncludesNetwork(networkName) {
+			continue
+		}
+
+		network := config.Config.SolanaNetworkByName(networkName)
+		if network.Name == "" {
+			fmt.Printf("No config for solana network %s\n", networkName)
+			continue
+		}
+
+		var tx solana.Transaction
+		found, err := solanaTxsDB.Read(key, &tx)
+		if err != nil || !found {
+			fmt.Printf("Could not read solana transaction %s\n", key)
+			continue
+		}
+
+		if !period.Contains(time.Unix(tx.BlockTime, 0)) {
+			continue
+		}
+
+		total++
+		rows, err := solanaTransactionRows(network, &tx)
+		if err == nil {
+			err = ctcWriter(rows...)
+		}
+		if err != nil {
+			failure := handler_types.AsHandlerError(err, &evm.TxInfo{Network: networkName, Hash: hash}, "solana")
+			fmt.Println("FAILED:", failure.Error())
+			failures = append(failures, failure)
+			continue
+		}
+		handled++
+	}
+
+	return total, handled, failures
+}
+
+func solanaTransactionRows(network solana.Network, tx *solana.Transaction) ([]ctc_util.CTCTransaction, error) {
+	ctcTxs := make([]*ctc_util.CTCTransaction, 0)
+
+	timestamp := time.Unix(tx.BlockTime, 0).UTC()
+	hash := tx.Signature()
+
+	if tx.Success() {
+		netTransfers, err := solana.NetBalanceChanges(network, tx)
+		if err != nil {
+			return nil, err
+		}
+		netTransfers = netTransfers.OnlyMine(config.Config.IsMySolanaAddress)
+
+		type change struct {
+			owner  core.SolanaAddress
+			amount *core.Amount
+		}
+
+		changes := make([]change, 0)
+		for _, balanceChanges := range netTransfers {
+			for owner, amount := range balanceChanges {
+				changes = append(changes, change{owner, amount})
+			}
+		}
+
+		// Map iteration order is random, so sort to keep IDs stable across runs
+		sort.Slice(changes, func(i, j int) bool {
+			a, b := changes[i], changes[j]
+			if a.amount.Asset.Identifier != b.amount.Asset.Identifier {
+				return a.amount.Asset.Identifier < b.amount.Asset.Identifier
+			}
+			return a.owner < b.owner
+		})
+
+		for _, c := range changes {
+			ctcTx := &ctc_util.CTCTransaction{
+				Timestamp:    timestamp,
+				Blockchain:   network.Name,
+				BaseCurrency: c.amount.Asset.Symbol,
+				BaseAmount:   c.amount.Value.Abs(),
+				BaseAsset:    c.amount.Asset,
+			}
+			if c.amount.IsPositive() {
+				ctcTx.Type = ctc_util.CTCReceive
+				ctcTx.To = c.owner.String()
+			} else {
+				ctcTx.Type = ctc_util.CTCSend
+				ctcTx.From = c.owner.String()
+			}
+			ctcTxs = append(ctcTxs, ctcTx)
+		}
+	}
+
+	feePayer := core.SolanaAddress(tx.FeePayer())
+	if config.Config.IsMySolanaAddress(feePayer) && tx.Meta.Fee > 0 {
+		fee := network.NativeAsset().WithAtomicValue(tx.Meta.Fee)
+
+		if len(ctcTxs) == 0 {
+			ctcTxs = append(ctcTxs, &ctc_util.CTCTransaction{
+				Timestamp:    timestamp,
+				Blockchain:   network.Name,
+				Type:         ctc_util.CTCFee,
+				From:         feePayer.String(),
+				BaseCurrency: fee.Asset.Symbol,
+				BaseAmount:   fee.Value,
+				BaseAsset:    fee.Asset,
+			})
+		} else {
+			ctcTxs[0].FeeCurrency = fee.Asset.Symbol
+			ctcTxs[0].FeeAmount = fee.Value
+			ctcTxs[0].FeeAsset = fee.Asset
+		}
+	}
+
+	rows := make([]ctc_util.CTCTransaction, len(ctcTxs))
+	for i, ctcTx := range ctcTxs {
+		ctcTx.ID = hash
+		if len(ctcTxs) > 1 {
+			ctcTx.ID = fmt.Sprintf("%s-%d", hash, i+1)
+		}
+		rows[i] = *ctcTx
+	}
+
+	return rows, nil
+}
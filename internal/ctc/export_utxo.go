@@ -0,0 +1,191 @@
+package ctc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	handler_types "github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/ksmithbaylor/gohodl/internal/utxo"
+)
+
+// UTXO transactions are exported from how much of their inputs and outputs
+// belong to the configured wallets. Which addresses those are is worked out
+// from the transactions already fetched, so nothing is asked of the explorer.
+func exportUtxoTransactions(
+	db *util.FileDB,
+	opts Options,
+	period tax_period.Period,
+	ctcWriter func(...ctc_util.CTCTransaction) error,
+) (total int, handled int, failures []*handler_types.HandlerError) {
+	utxoTxsDB, found := db.OpenCollection("utxo_txs")
+	if !found {
+		return 0, 0, nil
+	}
+
+	keys, err := utxoTxsDB.List()
+	if err != nil {
+		fmt.Printf("Error listing keys in utxo txs collection: %s\n", err.Error())
+		return 0, 0, nil
+	}
+
+	txsByNetwork := make(map[string][]*utxo.EsploraTx)
+	for _, key := range keys {
+		networkName, _, found := splitCacheKey(key)
+		if !found || !opts.IncludesNetwork(networkName) {
+			continue
+		}
+
+		var tx utxo.EsploraTx
+		found, err := utxoTxsDB.Read(key, &tx)
+		if err != nil || !found {
+			fmt.Printf("Could not read utxo transaction %s\n", key)
+			continue
+		}
+
+		txsByNetwork[networkName] = append(txsByNetwork[networkName], &tx)
+	}
+
+	networkNames := make([]string, 0, len(txsByNetwork))
+	for networkName := range txsByNetwork {
+		networkNames = append(networkNames, networkName)
+	}
+	sort.Strings(networkNames)
+
+	for _, networkName := range networkNames {
+		txs := txsByNetwork[networkName]
+
+		network := config.Config.UtxoNetworkByName(networkName)
+		if network.Name == "" {
+			fmt.Printf("No config for utxo network %s\n", networkName)
+			continue
+		}
+
+		mine, err := ownedUtxoAddresses(network, txs)
+		if err != nil {
+			fmt.Printf("Could not find wallet addresses on %s: %s\n", networkName, err.Error())
+			continue
+		}
+
+		for _, tx := range txs {
+			if !tx.Status.Confirmed || !period.Contains(time.Unix(tx.Status.BlockTime, 0)) {
+				continue
+			}
+
+			total++
+			rows := utxoTransactionRows(network, tx, func(address string) bool {
+				return mine[address]
+			})
+			if err := ctcWriter(rows...); err != nil {
+				failure := handler_types.AsHandlerError(err, &evm.TxInfo{Network: networkName, Hash: tx.Txid}, "utxo")
+				fmt.Println("FAILED:", failure.Error())
+				failures = append(failures, failure)
+				continue
+			}
+			handled++
+		}
+	}
+
+	return total, handled, failures
+}
+
+func ownedUtxoAddresses(network utxo.Network, txs []*utxo.EsploraTx) (map[string]bool, error) {
+	seen := make(map[string]bool)
+	for _, tx := range txs {
+		for _, address := range tx.Addresses() {
+			seen[address] = true
+		}
+	}
+
+	mine := make(map[string]bool)
+	for label, descriptor := range config.Config.OwnedAddresses(network) {
+		wallet, ok := utxo.ParseWalletDescriptor(descriptor)
+		if !ok {
+			return nil, fmt.Errorf("Invalid wallet %s", label)
+		}
+
+		used, err := network.UsedAddresses(wallet, seen)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, address := range used {
+			mine[address.Address] = true
+		}
+	}
+
+	return mine, nil
+}
+
+func utxoTransactionRows(network utxo.Network, tx *utxo.EsploraTx, isMine func(string) bool) []ctc_util.CTCTransaction {
+	ctcTxs := make([]*ctc_util.CTCTransaction, 0)
+
+	timestamp := time.Unix(tx.Status.BlockTime, 0).UTC()
+	asset := network.NativeAsset()
+	change := tx.BalanceChange(isMine)
+
+	// The fee comes out of what was spent, and change comes back in what was
+	// received, so only the difference left or arrived in the wallets
+	spent := change.Spent - change.Fee
+	if spent > change.Received {
+		ctcTxs = append(ctcTxs, &ctc_util.CTCTransaction{
+			Timestamp:    timestamp,
+			Blockchain:   network.Name,
+			Type:         ctc_util.CTCSend,
+			From:         change.From,
+			BaseCurrency: asset.Symbol,
+			BaseAmount:   asset.WithAtomicValue(spent - change.Received).Value,
+			BaseAsset:    asset,
+		})
+	} else if change.Received > spent {
+		ctcTx := &ctc_util.CTCTransaction{
+			Timestamp:    timestamp,
+			Blockchain:   network.Name,
+			Type:         ctc_util.CTCReceive,
+			To:           change.To,
+			BaseCurrency: asset.Symbol,
+			BaseAmount:   asset.WithAtomicValue(change.Received - spent).Value,
+			BaseAsset:    asset,
+		}
+		if change.Coinbase {
+			ctcTx.Type = ctc_util.CTCMining
+		}
+		ctcTxs = append(ctcTxs, ctcTx)
+	}
+
+	if change.Fee > 0 {
+		fee := asset.WithAtomicValue(change.Fee)
+
+		if len(ctcTxs) == 0 {
+			ctcTxs = append(ctcTxs, &ctc_util.CTCTransaction{
+				Timestamp:    timestamp,
+				Blockchain:   network.Name,
+				Type:         ctc_util.CTCFee,
+				From:         change.From,
+				BaseCurrency: fee.Asset.Symbol,
+				BaseAmount:   fee.Value,
+				BaseAsset:    fee.Asset,
+			})
+		} else {
+			ctcTxs[0].FeeCurrency = fee.Asset.Symbol
+			ctcTxs[0].FeeAmount = fee.Value
+			ctcTxs[0].FeeAsset = fee.Asset
+		}
+	}
+
+	rows := make([]ctc_util.CTCTransaction, len(ctcTxs))
+	for i, ctcTx := range ctcTxs {
+		ctcTx.ID = tx.Txid
+		if len(ctcTxs) > 1 {
+			ctcTx.ID = fmt.Sprintf("%s-%d", tx.Txid, i+1)
+		}
+		rows[i] = *ctcTx
+	}
+
+	return rows
+}
@@ -0,0 +1,62 @@
+package ctc
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cost_basis"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+)
+
+// Options narrow down what each step works on. The zero value means every
+// network and address, and exporting the current tax year.
+type Options struct {
+	Networks []string            // Only these networks, or all of them if empty
+	Labels   []string            // Only addresses with these labels, or all of them if empty
+	Periods  []tax_period.Period // Periods to export, each to its own CSV
+	Strict   bool                // Fail the export if any transaction couldn't be handled
+	Formats  []string            // Export formats, or just CTC if empty
+	Method   cost_basis.Method   // How reports match disposals to lots (default FIFO)
+
+	RetryDead bool // Fetch only the transactions that ran out of attempts before
+}
+
+func (o Options) IncludesNetwork(name string) bool {
+	return len(o.Networks) == 0 || slices.Contains(o.Networks, name)
+}
+
+func (o Options) IncludesLabel(label string) bool {
+	return len(o.Labels) == 0 || slices.Contains(o.Labels, label)
+}
+
+func (o Options) FilterNetworks(networks []core.Network) []core.Network {
+	filtered := make([]core.Network, 0, len(networks))
+	for _, network := range networks {
+		if o.IncludesNetwork(network.GetName()) {
+			filtered = append(filtered, network)
+		}
+	}
+	return filtered
+}
+
+// ExportPeriods returns the periods to export, defaulting to the last complete
+// tax year, since the current one is still missing transactions.
+func (o Options) ExportPeriods() ([]tax_period.Period, error) {
+	if len(o.Periods) > 0 {
+		return o.Periods, nil
+	}
+
+	current, err := config.Config.Tax.YearOf(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	year, err := config.Config.Tax.YearOf(current.From.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	return []tax_period.Period{year}, nil
+}
@@ -2,6 +2,7 @@ package ctc
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ksmithbaylor/gohodl/internal/util"
@@ -13,12 +14,10 @@ func readTransactionBundle(db *util.FileDB, network, hash string) (
 	*types.Header,
 	error,
 ) {
-	txsDB, txsFound := db.Collections["txs"]
-	receiptsDB, receiptsFound := db.Collections["receipts"]
-	blocksDB, blocksFound := db.Collections["blocks"]
-	if !txsFound || txsDB == nil ||
-		!receiptsFound || receiptsDB == nil ||
-		!blocksFound || blocksDB == nil {
+	txsDB, txsFound := db.OpenCollection("txs")
+	receiptsDB, receiptsFound := db.OpenCollection("receipts")
+	blocksDB, blocksFound := db.OpenCollection("blocks")
+	if !txsFound || !receiptsFound || !blocksFound {
 		return nil, nil, nil, fmt.Errorf("Cannot read transactions without first fetching them")
 	}
 
@@ -55,3 +54,13 @@ func readTransactionBundle(db *util.FileDB, network, hash string) (
 
 	return &tx, &receipt, &block, nil
 }
+
+// splitCacheKey splits a network-hash cache key on its last dash, since
+// network names can have dashes but hashes and signatures can't.
+func splitCacheKey(key string) (network, hash string, ok bool) {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 {
+		return "", "", false
+	}
+	return key[:i], key[i+1:], true
+}
@@ -0,0 +1,93 @@
+package ctc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/cost_basis"
+	"github.com/ksmithbaylor/gohodl/internal/generic"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/tax_report"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Report writes Form 8949 rows and a Schedule D summary for each of the
+// periods to export. Lots come from every transaction before the end of the
+// period, so earlier years have to have been fetched and analyzed too.
+func Report(db *util.FileDB, clients generic.AllNodeClients, opts Options) error {
+	periods, err := opts.ExportPeriods()
+	if err != nil {
+		return err
+	}
+
+	overrides, err := loadOverrides()
+	if err != nil {
+		return err
+	}
+
+	method := opts.Method
+	if method == "" {
+		method = cost_basis.FIFO
+	}
+	price := ledgerPrices(db)
+
+	for _, period := range periods {
+		fmt.Printf("Reporting %s\n", period)
+
+		history := tax_period.Period{Name: "history", From: time.Time{}, To: period.To}
+		result, err := handlePeriod(db, clients, opts, history, overrides)
+		if err != nil {
+			return fmt.Errorf("Could not report %s: %w", period.Name, err)
+		}
+		if len(result.failures) > 0 {
+			fmt.Printf("%d transactions failed and are left out, run export to see them\n", len(result.failures))
+		}
+
+		entries, err := ledger.FromCTCAll(result.ctcTxs)
+		if err != nil {
+			return err
+		}
+
+		report, err := tax_report.Build(entries, period, method, config.Config.Prices.FiatAsset().Symbol, price)
+		if err != nil {
+			return fmt.Errorf("Could not report %s: %w", period.Name, err)
+		}
+
+		outputs := map[string]func(io.Writer, *tax_report.Report) error{
+			getReportPath(db, "form8949", period, "csv"):    tax_report.WriteForm8949,
+			getReportPath(db, "schedule-d", period, "txt"):  tax_report.WriteScheduleD,
+			getReportPath(db, "schedule-d", period, "html"): tax_report.WriteScheduleDHTML,
+		}
+		for path, write := range outputs {
+			err = writeReport(path, report, write)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tax_report.WriteScheduleD(os.Stdout, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeReport(path string, report *tax_report.Report, write func(io.Writer, *tax_report.Report) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Error creating report file: %w", err)
+	}
+	defer file.Close()
+
+	return write(file, report)
+}
+
+func getReportPath(db *util.FileDB, name string, period tax_period.Period, extension string) string {
+	return fmt.Sprintf("%s/%s-%s.%s", db.Path, name, period.Name, extension)
+}
@@ -0,0 +1,55 @@
+package ctc
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/generic"
+	"github.com/ksmithbaylor/gohodl/internal/handlers/fixtures"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Snapshot saves a fetched EVM transaction as a fixture in the given
+// directory, for the handler tests to replay. If network is empty, every
+// network is searched for the hash.
+func Snapshot(db *util.FileDB, clients generic.AllNodeClients, opts Options, network, hash, dir string) error {
+	network, err := findNetwork(db, opts, network, hash)
+	if err != nil {
+		return err
+	}
+
+	client, ok := clients[network]
+	if !ok {
+		return fmt.Errorf("No client for network %s", network)
+	}
+	evmClient, ok := client.(*evm.Client)
+	if !ok {
+		return fmt.Errorf("Non-EVM networks (like %s) can't be snapshotted yet", network)
+	}
+
+	tx, receipt, block, err := readTransactionBundle(db, network, hash)
+	if err != nil {
+		return err
+	}
+
+	info, err := txInfoFromRow(txsCsvRow(network, hash, tx, receipt, block))
+	if err != nil {
+		return err
+	}
+
+	fixture, err := fixtures.New(info, evmClient, tx, receipt, block)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fixture.Name()+".json")
+	err = fixture.Save(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved %s, run `go test ./internal/handlers/... -update` to write its golden CSV\n", path)
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package ctc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Collections holding the fetched transactions for each kind of network. For
+// EVM networks, a transaction counts as fetched once its receipt is cached.
+var FETCHED_COLLECTIONS = map[core.NetworkKind]string{
+	core.EvmNetworkKind:    "receipts",
+	core.UtxoNetworkKind:   "utxo_txs",
+	core.SolanaNetworkKind: "solana_txs",
+	core.CosmosNetworkKind: "cosmos_txs",
+}
+
+// Status prints how far each network has made it through the pipeline, without
+// making any network requests.
+func Status(db *util.FileDB, opts Options) error {
+	cfg := config.Config
+	networks := opts.FilterNetworks(cfg.AllNetworks())
+
+	txHashes, err := readTransactionHashes(db, opts)
+	if err != nil {
+		fmt.Println(err.Error())
+		txHashes = make(map[string][]string)
+	}
+
+	analyzedRows, err := readTxsCsvRows(db, opts.IncludesNetwork)
+	if err != nil {
+		return err
+	}
+	analyzed := make(map[string]int)
+	for _, row := range analyzedRows {
+		analyzed[row[1]]++
+	}
+
+	longestName := len("network")
+	for _, network := range networks {
+		longestName = max(longestName, len(network.GetName()))
+	}
+
+	fmt.Printf("%-*s  %9s  %10s  %7s  %8s\n", longestName, "network", "addresses", "identified", "fetched", "analyzed")
+
+	for _, network := range networks {
+		name := network.GetName()
+
+		addresses := 0
+		for label := range cfg.OwnedAddresses(network) {
+			if opts.IncludesLabel(label) {
+				addresses++
+			}
+		}
+
+		fetched := 0
+		if collection, found := db.OpenCollection(FETCHED_COLLECTIONS[network.GetKind()]); found {
+			for _, hash := range txHashes[name] {
+				if collection.Has(fmt.Sprintf("%s-%s", name, hash)) {
+					fetched++
+				}
+			}
+		}
+
+		deprecated := ""
+		if network.GetDeprecated() {
+			deprecated = " (deprecated)"
+		}
+
+		fmt.Printf("%-*s  %9d  %10d  %7d  %8d%s\n",
+			longestName,
+			name,
+			addresses,
+			len(txHashes[name]),
+			fetched,
+			analyzed[name],
+			deprecated,
+		)
+	}
+
+	if deadDB, found := db.OpenCollection("dead_txs"); found {
+		for _, network := range networks {
+			dead := 0
+			for _, hash := range txHashes[network.GetName()] {
+				if deadDB.Has(fmt.Sprintf("%s-%s", network.GetName(), hash)) {
+					dead++
+				}
+			}
+			if dead > 0 {
+				fmt.Printf("%d transactions on %s ran out of fetch attempts (retry with fetch -retry-dead)\n", dead, network.GetName())
+			}
+		}
+	}
+
+	err = printRPCHealth(db, networks)
+	if err != nil {
+		return err
+	}
+
+	exports, err := filepath.Glob(db.Path + "/ctc-*.csv")
+	if err != nil {
+		return fmt.Errorf("Could not list exported CSVs: %w", err)
+	}
+	if len(exports) > 0 {
+		fmt.Println()
+	}
+	for _, path := range exports {
+		exported, err := countCsvRows(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d rows exported to %s\n", exported, path)
+	}
+
+	return nil
+}
+
+// printRPCHealth shows how each RPC of the EVM networks has been doing, from
+// most to least healthy, and how many disagreements were recorded.
+func printRPCHealth(db *util.FileDB, networks []core.Network) error {
+	for _, network := range networks {
+		if network.GetKind() != core.EvmNetworkKind {
+			continue
+		}
+		name := network.GetName()
+
+		health, err := evm.ReadRPCHealth(db, name)
+		if err != nil {
+			return err
+		}
+		disagreements, err := evm.CountDisagreements(db, name)
+		if err != nil {
+			return err
+		}
+		if len(health) == 0 && disagreements == 0 {
+			continue
+		}
+
+		rpcs := make([]string, 0, len(health))
+		for rpc := range health {
+			rpcs = append(rpcs, rpc)
+		}
+		sort.Slice(rpcs, func(i, j int) bool {
+			return health[rpcs[i]].Score() > health[rpcs[j]].Score()
+		})
+
+		fmt.Printf("\nRPCs for %s (%d disagreements recorded):\n", name, disagreements)
+		for _, rpc := range rpcs {
+			h := health[rpc]
+			fmt.Printf("  %.2f  %5d calls  %4d errors  %4d disagreements  %6s  %s\n",
+				h.Score(),
+				h.Calls,
+				h.Errors,
+				h.Disagreements,
+				h.AverageLatency().Round(time.Millisecond),
+				rpc,
+			)
+		}
+	}
+
+	return nil
+}
+
+// countCsvRows counts the rows of a CSV file after the header, or zero if it
+// does not exist.
+func countCsvRows(path string) (int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Error opening %s: %w", path, err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("Error reading %s: %w", path, err)
+	}
+
+	return max(len(rows)-1, 0), nil
+}
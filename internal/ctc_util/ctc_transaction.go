@@ -1,6 +1,7 @@
 package ctc_util
 
 import (
+	"fmt"
 	"slices"
 	"strconv"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/k0kubun/pp/v3"
 	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/core"
 	"github.com/shopspring/decimal"
 )
 
@@ -28,6 +30,12 @@ type CTCTransaction struct {
 	Description            string
 	ReferencePricePerUnit  decimal.Decimal
 	ReferencePriceCurrency string
+
+	// The assets behind the currencies, where the handler knew them. They
+	// aren't part of the CSV, but tell apart assets that share a symbol.
+	BaseAsset  core.Asset
+	QuoteAsset core.Asset
+	FeeAsset   core.Asset
 }
 
 var freeTransactions = []string{
@@ -38,7 +46,7 @@ func NewFeeTransaction(
 	blockTime uint64,
 	network, id, from, description string,
 	receipt *types.Receipt,
-) *CTCTransaction {
+) (*CTCTransaction, error) {
 	ctcTx := CTCTransaction{
 		Timestamp:   time.Unix(int64(blockTime), 0).UTC(),
 		Blockchain:  network,
@@ -48,18 +56,24 @@ func NewFeeTransaction(
 		Description: description,
 	}
 
-	ctcTx.AddTransactionFeeIfMine(from, network, receipt)
+	err := ctcTx.AddTransactionFeeIfMine(from, network, receipt)
+	if err != nil {
+		return nil, err
+	}
 	if ctcTx.FeeAmount.IsZero() && !slices.Contains(freeTransactions, id) {
-		panic("fee tx but did not pay a fee")
+		return nil, fmt.Errorf("Fee transaction %s did not pay a fee", id)
 	}
 
 	// Since this is a transaction recording only the fee, put the fee as the base
 	ctcTx.BaseCurrency, ctcTx.FeeCurrency = ctcTx.FeeCurrency, ctcTx.BaseCurrency
 	ctcTx.BaseAmount, ctcTx.FeeAmount = ctcTx.FeeAmount, ctcTx.BaseAmount
+	ctcTx.BaseAsset, ctcTx.FeeAsset = ctcTx.FeeAsset, ctcTx.BaseAsset
 
-	return &ctcTx
+	return &ctcTx, nil
 }
 
+const CTC_ID_HEADER = "ID (Optional)"
+
 var CTC_HEADERS = []string{
 	"Timestamp (UTC)",
 	"Type",
@@ -72,17 +86,48 @@ var CTC_HEADERS = []string{
 	"From (Optional)",
 	"To (Optional)",
 	"Blockchain (Optional)",
-	"ID (Optional)",
+	CTC_ID_HEADER,
 	"Description (Optional)",
 	"Reference Price Per Unit (Optional)",
 	"Reference Price Currency (Optional)",
 }
 
-func (t *CTCTransaction) ToCSV() []string {
-	if t.Type == "" || t.Timestamp.IsZero() {
-		panic("Invalid transaction, missing type or timestamp or base currency")
+// Validate checks that the transaction has everything CTC requires.
+func (t *CTCTransaction) Validate() error {
+	if t.Timestamp.IsZero() {
+		return fmt.Errorf("Transaction %s is missing a timestamp", t.ID)
+	}
+	if t.Type == "" {
+		return fmt.Errorf("Transaction %s is missing a type", t.ID)
+	}
+	if !t.Type.IsValid() {
+		return fmt.Errorf("Transaction %s has unknown type '%s'", t.ID, t.Type)
+	}
+	return nil
+}
+
+// Sequence splits an ID like `0xabc-3` into the transaction hash and which of
+// its rows this is. Rows without a number are the first.
+func (t *CTCTransaction) Sequence() (string, int) {
+	if idx := strings.LastIndex(t.ID, "-"); idx != -1 {
+		if num, err := strconv.Atoi(t.ID[idx+1:]); err == nil && num >= 1 {
+			return t.ID[:idx], num
+		}
 	}
+	return t.ID, 1
+}
+
+// ExportTimestamp is the timestamp written to CTC. Each extra row of a
+// transaction is a second later than the previous one, so CTC keeps them in
+// order.
+func (t *CTCTransaction) ExportTimestamp() time.Time {
+	_, num := t.Sequence()
+	return t.Timestamp.Add(time.Duration(num-1) * time.Second).Truncate(time.Second)
+}
 
+// ToCSV formats the transaction as a CTC CSV row. It doesn't check it, so
+// anything writing rows out should Validate them first.
+func (t *CTCTransaction) ToCSV() []string {
 	blockchain := t.Blockchain
 	if blockchain == "arbitrumone" {
 		blockchain = "arbitrum"
@@ -90,16 +135,8 @@ func (t *CTCTransaction) ToCSV() []string {
 		blockchain = "eth"
 	}
 
-	timestamp := t.Timestamp
-	if idx := strings.LastIndex(t.ID, "-"); idx != -1 {
-		suffix := t.ID[idx+1:]
-		if num, err := strconv.Atoi(suffix); err == nil && num > 1 {
-			timestamp = timestamp.Add(time.Duration(num-1) * time.Second)
-		}
-	}
-
 	return []string{
-		timestamp.Format("2006-01-02 15:04:05"),
+		t.ExportTimestamp().Format("2006-01-02 15:04:05"),
 		string(t.Type),
 		t.BaseCurrency,
 		emptyIfZero(t.BaseAmount.String()),
@@ -132,13 +169,13 @@ func (t *CTCTransaction) Print() {
 	pp.Println(t.ToPrintable())
 }
 
-func (t *CTCTransaction) AddTransactionFeeIfMine(from, network string, receipt *types.Receipt) {
+func (t *CTCTransaction) AddTransactionFeeIfMine(from, network string, receipt *types.Receipt) error {
 	if config.Config.IsMyEvmAddressString(from) {
 		evmNetwork := config.Config.EvmNetworkByName(network)
 
 		gasPrice, err := decimal.NewFromString(receipt.EffectiveGasPrice.String())
 		if err != nil {
-			panic("Could not parse gas price")
+			return fmt.Errorf("Could not parse gas price of %s: %w", receipt.TxHash, err)
 		}
 		gasUsed := decimal.NewFromInt(int64(receipt.GasUsed))
 		networkFee := gasPrice.Mul(gasUsed)
@@ -152,7 +189,10 @@ func (t *CTCTransaction) AddTransactionFeeIfMine(from, network string, receipt *
 
 		t.FeeCurrency = transactionFee.Asset.Symbol
 		t.FeeAmount = transactionFee.Value
+		t.FeeAsset = transactionFee.Asset
 	}
+
+	return nil
 }
 
 func emptyIfZero(s string) string {
@@ -217,3 +257,58 @@ const (
 	CTCReceivePQ            CTCTransactionType = "receive-pq"
 	CTCSendPQ               CTCTransactionType = "send-pq"
 )
+
+// Every type CTC accepts
+var CTC_TRANSACTION_TYPES = []CTCTransactionType{
+	CTCBuy,
+	CTCSell,
+	CTCFiatDeposit,
+	CTCFiatWithdrawal,
+	CTCFee,
+	CTCApproval,
+	CTCReceive,
+	CTCSend,
+	CTCChainSplit,
+	CTCExpense,
+	CTCStolen,
+	CTCLost,
+	CTCBurn,
+	CTCIncome,
+	CTCInterest,
+	CTCMining,
+	CTCAirdrop,
+	CTCStaking,
+	CTCStakingDeposit,
+	CTCStakingWithdrawal,
+	CTCRebate,
+	CTCRoyalty,
+	CTCPersonalUse,
+	CTCIncomingGift,
+	CTCOutgoingGift,
+	CTCBorrow,
+	CTCLoanRepayment,
+	CTCLiquidate,
+	CTCBridgeIn,
+	CTCBridgeOut,
+	CTCMint,
+	CTCCollateralWithdrawal,
+	CTCCollateralDeposit,
+	CTCAddLiquidity,
+	CTCReceiveLPToken,
+	CTCRemoveLiquidity,
+	CTCReturnLPToken,
+	CTCFailedIn,
+	CTCFailedOut,
+	CTCSpam,
+	CTCSwapIn,
+	CTCSwapOut,
+	CTCBridgeTradeIn,
+	CTCBridgeTradeOut,
+	CTCRealizedProfit,
+	CTCRealizedLoss,
+	CTCMarginFee,
+	CTCOpenPosition,
+	CTCClosePosition,
+	CTCReceivePQ,
+	CTCSendPQ,
+}
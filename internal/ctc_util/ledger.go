@@ -0,0 +1,64 @@
+package ctc_util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// SortTransactions orders transactions the way they're written to CTC, keeping
+// the rows of each transaction in the order they were exported.
+func SortTransactions(txs []CTCTransaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].ExportTimestamp().Before(txs[j].ExportTimestamp())
+	})
+}
+
+// CheckSequence warns about sorted transactions that CTC would misread: IDs
+// used more than once, and rows of a transaction separated by another one.
+func CheckSequence(txs []CTCTransaction) []string {
+	warnings := make([]string, 0)
+	usedIds := make(map[string]struct{})
+
+	for i, tx := range txs {
+		if _, alreadyUsed := usedIds[tx.ID]; alreadyUsed {
+			warnings = append(warnings, fmt.Sprintf("DUPLICATE: %s", tx.ID))
+		}
+		usedIds[tx.ID] = struct{}{}
+
+		hash, num := tx.Sequence()
+		if i > 0 && num >= 2 {
+			expectedPrevID := hash + "-" + strconv.Itoa(num-1)
+			if prevID := txs[i-1].ID; prevID != expectedPrevID {
+				warnings = append(warnings, fmt.Sprintf("INTERLEAVED: %s should be preceded by %s, but was preceded by %s", tx.ID, expectedPrevID, prevID))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// WriteCSV serializes transactions in CTC's CSV format, headers included.
+func WriteCSV(w io.Writer, txs []CTCTransaction) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(CTC_HEADERS)
+	if err != nil {
+		return fmt.Errorf("Error writing CTC CSV headers: %w", err)
+	}
+
+	for _, tx := range txs {
+		if err := tx.Validate(); err != nil {
+			return err
+		}
+		err = writer.Write(tx.ToCSV())
+		if err != nil {
+			return fmt.Errorf("Error writing CTC CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
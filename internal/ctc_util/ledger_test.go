@@ -0,0 +1,103 @@
+package ctc_util_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var NOON = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func ledgerTx(id string, timestamp time.Time) ctc_util.CTCTransaction {
+	return ctc_util.CTCTransaction{
+		Timestamp:    timestamp,
+		Type:         ctc_util.CTCReceive,
+		BaseCurrency: "ETH",
+		BaseAmount:   decimal.NewFromInt(1),
+		Blockchain:   "ethereum",
+		ID:           id,
+	}
+}
+
+func ids(txs []ctc_util.CTCTransaction) []string {
+	result := make([]string, len(txs))
+	for i, tx := range txs {
+		result[i] = tx.ID
+	}
+	return result
+}
+
+// Transaction tests
+
+func TestSequence(t *testing.T) {
+	for id, expected := range map[string]struct {
+		hash string
+		num  int
+	}{
+		"0xaaaa":               {"0xaaaa", 1},
+		"0xaaaa-1":             {"0xaaaa", 1},
+		"0xaaaa-3":             {"0xaaaa", 3},
+		"0xaaaa-from-ethereum": {"0xaaaa-from-ethereum", 1},
+	} {
+		tx := ledgerTx(id, NOON)
+		hash, num := tx.Sequence()
+		assert.Equal(t, expected.hash, hash, id)
+		assert.Equal(t, expected.num, num, id)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tx := ledgerTx("0xaaaa", NOON)
+	assert.NoError(t, tx.Validate())
+
+	tx.Type = "gift"
+	assert.EqualError(t, tx.Validate(), "Transaction 0xaaaa has unknown type 'gift'")
+
+	tx = ledgerTx("0xaaaa", time.Time{})
+	assert.EqualError(t, tx.Validate(), "Transaction 0xaaaa is missing a timestamp")
+}
+
+// Ledger tests
+
+func TestSortTransactions(t *testing.T) {
+	txs := []ctc_util.CTCTransaction{
+		ledgerTx("0xbbbb-2", NOON),
+		ledgerTx("0xaaaa", NOON.Add(time.Second)),
+		ledgerTx("0xbbbb-1", NOON),
+	}
+
+	ctc_util.SortTransactions(txs)
+	assert.Equal(t, []string{"0xbbbb-1", "0xbbbb-2", "0xaaaa"}, ids(txs))
+}
+
+func TestCheckSequence(t *testing.T) {
+	txs := []ctc_util.CTCTransaction{
+		ledgerTx("0xbbbb-1", NOON),
+		ledgerTx("0xaaaa", NOON),
+		ledgerTx("0xbbbb-2", NOON),
+		ledgerTx("0xaaaa", NOON),
+	}
+
+	assert.Equal(t, []string{
+		"INTERLEAVED: 0xbbbb-2 should be preceded by 0xbbbb-1, but was preceded by 0xaaaa",
+		"DUPLICATE: 0xaaaa",
+	}, ctc_util.CheckSequence(txs))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := ctc_util.WriteCSV(&buf, []ctc_util.CTCTransaction{ledgerTx("0xaaaa-2", NOON)})
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "2024-03-01 12:00:01,receive,ETH,1,,,,,,,eth,0xaaaa-2,,,", lines[1])
+
+	err = ctc_util.WriteCSV(&buf, []ctc_util.CTCTransaction{{ID: "0xaaaa"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package ctc_util
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// An Override hand-tunes the CTC rows of one transaction. Ignoring it or
+// replacing its rows happens before the handler, which is then skipped.
+// Forcing a type or description happens after, on every row written for it.
+// See overrides.yml for the format.
+type Override struct {
+	Network     string             `yaml:"network"`
+	Hash        string             `yaml:"hash"`
+	Ignore      bool               `yaml:"ignore"`      // Export nothing for the transaction
+	Rows        []OverrideRow      `yaml:"rows"`        // Complete replacement rows
+	Type        CTCTransactionType `yaml:"type"`        // Forced type of every row
+	Description string             `yaml:"description"` // Replacement description of every row
+	Reason      string             `yaml:"reason"`      // Why the override is needed, for the export summary
+}
+
+// An OverrideRow is one replacement CTC row. The timestamp defaults to the
+// time of the transaction, and the ID is filled in like a handler would.
+type OverrideRow struct {
+	Timestamp     time.Time          `yaml:"timestamp"`
+	Type          CTCTransactionType `yaml:"type"`
+	BaseCurrency  string             `yaml:"base_currency"`
+	BaseAmount    decimal.Decimal    `yaml:"base_amount"`
+	QuoteCurrency string             `yaml:"quote_currency"`
+	QuoteAmount   decimal.Decimal    `yaml:"quote_amount"`
+	FeeCurrency   string             `yaml:"fee_currency"`
+	FeeAmount     decimal.Decimal    `yaml:"fee_amount"`
+	From          string             `yaml:"from"`
+	To            string             `yaml:"to"`
+	Description   string             `yaml:"description"`
+}
+
+type Overrides struct {
+	byTransaction map[string]Override
+}
+
+func NewOverrides() *Overrides {
+	return &Overrides{
+		byTransaction: make(map[string]Override),
+	}
+}
+
+func LoadOverrides(path string) (*Overrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read overrides %s: %w", path, err)
+	}
+
+	overrides, err := ParseOverrides(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid overrides %s: %w", path, err)
+	}
+
+	return overrides, nil
+}
+
+func ParseOverrides(data []byte) (*Overrides, error) {
+	var file struct {
+		Overrides []Override `yaml:"overrides"`
+	}
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := NewOverrides()
+	for _, override := range file.Overrides {
+		err := overrides.Add(override)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return overrides, nil
+}
+
+func (o *Overrides) Add(override Override) error {
+	if override.Network == "" || override.Hash == "" {
+		return fmt.Errorf("Override is missing a network or hash")
+	}
+
+	key := overrideKey(override.Network, override.Hash)
+	if _, exists := o.byTransaction[key]; exists {
+		return fmt.Errorf("Duplicate override for %s %s", override.Network, override.Hash)
+	}
+
+	err := override.validate()
+	if err != nil {
+		return fmt.Errorf("Override for %s %s: %w", override.Network, override.Hash, err)
+	}
+
+	o.byTransaction[key] = override
+	return nil
+}
+
+// Find returns the override for a transaction, if there is one. Hashes are
+// compared case-insensitively.
+func (o *Overrides) Find(network, hash string) (Override, bool) {
+	if o == nil {
+		return Override{}, false
+	}
+	override, found := o.byTransaction[overrideKey(network, hash)]
+	return override, found
+}
+
+func (o *Overrides) Len() int {
+	if o == nil {
+		return 0
+	}
+	return len(o.byTransaction)
+}
+
+func overrideKey(network, hash string) string {
+	return network + "-" + strings.ToLower(hash)
+}
+
+func (o Override) validate() error {
+	if o.Ignore && len(o.Rows) > 0 {
+		return fmt.Errorf("Can't both ignore and replace rows")
+	}
+	if !o.Ignore && len(o.Rows) == 0 && o.Type == "" && o.Description == "" {
+		return fmt.Errorf("Nothing to override")
+	}
+	if o.Type != "" && !o.Type.IsValid() {
+		return fmt.Errorf("Unknown type '%s'", o.Type)
+	}
+
+	for i, row := range o.Rows {
+		if !row.Type.IsValid() {
+			return fmt.Errorf("Row %d has unknown type '%s'", i+1, row.Type)
+		}
+		if row.BaseCurrency == "" {
+			return fmt.Errorf("Row %d is missing a base currency", i+1)
+		}
+	}
+
+	return nil
+}
+
+// SkipsHandler is whether the override replaces the handler entirely.
+func (o Override) SkipsHandler() bool {
+	return o.Ignore || len(o.Rows) > 0
+}
+
+// ReplacementRows returns the transactions replacing the handler's, with the
+// forced type and description applied.
+func (o Override) ReplacementRows(txTime time.Time) []CTCTransaction {
+	rows := make([]CTCTransaction, 0, len(o.Rows))
+
+	for i, row := range o.Rows {
+		id := o.Hash
+		if len(o.Rows) > 1 {
+			id = fmt.Sprintf("%s-%d", o.Hash, i+1)
+		}
+
+		timestamp := row.Timestamp
+		if timestamp.IsZero() {
+			timestamp = txTime
+		}
+
+		ctcTx := CTCTransaction{
+			Timestamp:     timestamp.UTC(),
+			Type:          row.Type,
+			BaseCurrency:  row.BaseCurrency,
+			BaseAmount:    row.BaseAmount,
+			QuoteCurrency: row.QuoteCurrency,
+			QuoteAmount:   row.QuoteAmount,
+			FeeCurrency:   row.FeeCurrency,
+			FeeAmount:     row.FeeAmount,
+			From:          row.From,
+			To:            row.To,
+			Blockchain:    o.Network,
+			ID:            id,
+			Description:   row.Description,
+		}
+		rows = append(rows, ctcTx)
+	}
+
+	o.Patch(rows)
+	return rows
+}
+
+// Patch forces the type and description of the transactions already exported
+// for the transaction, in place.
+func (o Override) Patch(rows []CTCTransaction) {
+	for i := range rows {
+		if o.Type != "" {
+			rows[i].Type = o.Type
+		}
+		if o.Description != "" {
+			rows[i].Description = o.Description
+		}
+	}
+}
+
+// Summary describes what the override did, for the export summary.
+func (o Override) Summary() string {
+	changes := make([]string, 0)
+	if o.Ignore {
+		changes = append(changes, "ignored")
+	}
+	if len(o.Rows) > 0 {
+		changes = append(changes, fmt.Sprintf("replaced with %d rows", len(o.Rows)))
+	}
+	if o.Type != "" {
+		changes = append(changes, "type "+string(o.Type))
+	}
+	if o.Description != "" {
+		changes = append(changes, fmt.Sprintf("description '%s'", o.Description))
+	}
+
+	summary := fmt.Sprintf("%s %s: %s", o.Network, o.Hash, strings.Join(changes, ", "))
+	if o.Reason != "" {
+		summary += " (" + o.Reason + ")"
+	}
+	return summary
+}
+
+func (t CTCTransactionType) IsValid() bool {
+	return slices.Contains(CTC_TRANSACTION_TYPES, t)
+}
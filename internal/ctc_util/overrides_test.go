@@ -0,0 +1,112 @@
+package ctc_util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/stretchr/testify/assert"
+)
+
+const OVERRIDES = `
+overrides:
+  - network: ethereum
+    hash: 0xAAAA
+    ignore: true
+    reason: duplicate of a bridge deposit
+  - network: base
+    hash: 0xbbbb
+    type: spam
+    description: fake airdrop
+  - network: base
+    hash: 0xcccc
+    description: refund
+    rows:
+      - type: rebate
+        base_currency: USDC
+        base_amount: 12.5
+      - type: fee
+        base_currency: ETH
+        base_amount: "0.0001"
+        timestamp: 2024-03-01T12:00:00Z
+`
+
+// ParseOverrides tests
+
+func TestParseOverrides(t *testing.T) {
+	overrides, err := ctc_util.ParseOverrides([]byte(OVERRIDES))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, overrides.Len())
+
+	ignored, found := overrides.Find("ethereum", "0xaaaa")
+	assert.True(t, found)
+	assert.True(t, ignored.SkipsHandler())
+	assert.Equal(t, "ethereum 0xAAAA: ignored (duplicate of a bridge deposit)", ignored.Summary())
+
+	_, found = overrides.Find("base", "0xaaaa")
+	assert.False(t, found)
+
+	var missing *ctc_util.Overrides
+	_, found = missing.Find("base", "0xaaaa")
+	assert.False(t, found)
+}
+
+func TestParseInvalidOverrides(t *testing.T) {
+	_, err := ctc_util.ParseOverrides([]byte(`
+overrides:
+  - network: base
+    hash: 0xbbbb
+    type: not-a-type
+`))
+	assert.ErrorContains(t, err, "Unknown type")
+
+	_, err = ctc_util.ParseOverrides([]byte(`
+overrides:
+  - network: base
+    hash: 0xbbbb
+`))
+	assert.ErrorContains(t, err, "Nothing to override")
+
+	_, err = ctc_util.ParseOverrides([]byte(`
+overrides:
+  - network: base
+    hash: 0xbbbb
+    ignore: true
+  - network: base
+    hash: 0xBBBB
+    ignore: true
+`))
+	assert.ErrorContains(t, err, "Duplicate override")
+}
+
+// Override tests
+
+func TestPatch(t *testing.T) {
+	overrides, _ := ctc_util.ParseOverrides([]byte(OVERRIDES))
+	override, _ := overrides.Find("base", "0xbbbb")
+	assert.False(t, override.SkipsHandler())
+
+	rows := []ctc_util.CTCTransaction{{Type: ctc_util.CTCReceive, Description: "airdrop"}}
+	override.Patch(rows)
+
+	assert.Equal(t, ctc_util.CTCSpam, rows[0].Type)
+	assert.Equal(t, "fake airdrop", rows[0].Description)
+}
+
+func TestReplacementRows(t *testing.T) {
+	overrides, _ := ctc_util.ParseOverrides([]byte(OVERRIDES))
+	override, _ := overrides.Find("base", "0xcccc")
+	assert.True(t, override.SkipsHandler())
+
+	rows := override.ReplacementRows(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, rows, 2)
+
+	assert.Equal(t, []string{
+		"2024-02-01 00:00:00", "rebate", "USDC", "12.5", "", "", "", "", "", "", "base", "0xcccc-1", "refund", "", "",
+	}, rows[0].ToCSV())
+
+	// Later rows are offset by a second each, like any other handler's
+	assert.Equal(t, "2024-03-01 12:00:01", rows[1].ToCSV()[0])
+	assert.Equal(t, "0xcccc-2", rows[1].ID)
+	assert.Equal(t, "0.0001", rows[1].BaseAmount.String())
+}
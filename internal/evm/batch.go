@@ -0,0 +1,187 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Most requests public RPCs accept in one JSON-RPC batch
+const BATCH_SIZE = 50
+
+// A FetchedTransaction is a transaction along with the block it was included
+// in, which the transaction itself doesn't keep.
+type FetchedTransaction struct {
+	Tx        *types.Transaction
+	BlockHash common.Hash
+}
+
+// GetTransactions fetches up to BATCH_SIZE transactions in one batch per RPC,
+// in the order of the hashes.
+func (c *Client) GetTransactions(hashes []string) ([]FetchedTransaction, error) {
+	return batchWithAgreement(c, "eth_getTransactionByHash", hashParams(hashes), func(raw json.RawMessage) (FetchedTransaction, string, error) {
+		var meta struct {
+			BlockHash *common.Hash `json:"blockHash"`
+		}
+		err := json.Unmarshal(raw, &meta)
+		if err != nil {
+			return FetchedTransaction{}, "", err
+		}
+		if meta.BlockHash == nil {
+			return FetchedTransaction{}, "", fmt.Errorf("Transaction is still pending")
+		}
+
+		tx := new(types.Transaction)
+		err = tx.UnmarshalJSON(raw)
+		if err != nil {
+			return FetchedTransaction{}, "", err
+		}
+
+		json, err := tx.MarshalJSON()
+		if err != nil {
+			return FetchedTransaction{}, "", fmt.Errorf("Unable to marshal tx to json: %w", err)
+		}
+
+		return FetchedTransaction{Tx: tx, BlockHash: *meta.BlockHash}, meta.BlockHash.Hex() + common.Bytes2Hex(json), nil
+	})
+}
+
+// GetTransactionReceipts fetches up to BATCH_SIZE receipts in one batch per
+// RPC, in the order of the hashes.
+func (c *Client) GetTransactionReceipts(hashes []string) ([]*types.Receipt, error) {
+	return batchWithAgreement(c, "eth_getTransactionReceipt", hashParams(hashes), decodeReceipt)
+}
+
+// GetBlocks fetches up to BATCH_SIZE block headers in one batch per RPC, in
+// the order of the hashes.
+func (c *Client) GetBlocks(hashes []string) ([]*types.Header, error) {
+	params := make([][]any, len(hashes))
+	for i, hash := range hashes {
+		params[i] = []any{common.HexToHash(hash), false}
+	}
+
+	return batchWithAgreement(c, "eth_getBlockByHash", params, func(raw json.RawMessage) (*types.Header, string, error) {
+		header := new(types.Header)
+		err := header.UnmarshalJSON(raw)
+		if err != nil {
+			return nil, "", err
+		}
+
+		json, err := header.MarshalJSON()
+		if err != nil {
+			return nil, "", fmt.Errorf("Unable to marshal block header to json: %w", err)
+		}
+
+		return header, common.Bytes2Hex(json), nil
+	})
+}
+
+// GetBlockReceipts fetches every receipt of a block at once, which is cheaper
+// than one at a time when several of them are needed. Not every RPC supports
+// it, so callers should fall back to GetTransactionReceipts. RPCs that don't
+// are only asked once, and once too few are left it fails straight away.
+func (c *Client) GetBlockReceipts(blockHash string) ([]*types.Receipt, error) {
+	err := c.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return ensureAgreementWithRetry(c, "eth_getBlockReceipts "+blockHash, func(client *ethclient.Client) ([]*types.Receipt, string, error) {
+		receipts, err := client.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(common.HexToHash(blockHash), false))
+		if err != nil {
+			return nil, "", err
+		}
+
+		keys := make([]string, len(receipts))
+		for i, receipt := range receipts {
+			json, err := receipt.MarshalJSON()
+			if err != nil {
+				return nil, "", fmt.Errorf("Unable to marshal tx receipt to json: %w", err)
+			}
+			keys[i] = common.Bytes2Hex(json)
+		}
+
+		return receipts, strings.Join(keys, ","), nil
+	})
+}
+
+// batchWithAgreement sends the same batch to each RPC asked, and only trusts
+// the results once a quorum of them agree on every one. Each result is decoded
+// into its value and the key it is compared by.
+func batchWithAgreement[T any](
+	c *Client,
+	method string,
+	params [][]any,
+	decode func(json.RawMessage) (T, string, error),
+) ([]T, error) {
+	if len(params) == 0 {
+		return make([]T, 0), nil
+	}
+
+	err := c.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	call := fmt.Sprintf("%s batch of %d starting with %v", method, len(params), params[0][0])
+
+	return ensureAgreementWithRetry(c, call, func(client *ethclient.Client) ([]T, string, error) {
+		raws := make([]json.RawMessage, len(params))
+		elems := make([]rpc.BatchElem, len(params))
+		for i := range params {
+			elems[i] = rpc.BatchElem{Method: method, Args: params[i], Result: &raws[i]}
+		}
+
+		err := client.Client().BatchCallContext(context.Background(), elems)
+		if err != nil {
+			return nil, "", err
+		}
+
+		values := make([]T, len(params))
+		keys := make([]string, len(params))
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return nil, "", fmt.Errorf("%s %v: %w", method, params[i][0], elem.Error)
+			}
+			if len(raws[i]) == 0 || string(raws[i]) == "null" {
+				return nil, "", fmt.Errorf("%s %v: not found", method, params[i][0])
+			}
+
+			values[i], keys[i], err = decode(raws[i])
+			if err != nil {
+				return nil, "", fmt.Errorf("%s %v: %w", method, params[i][0], err)
+			}
+		}
+
+		return values, strings.Join(keys, ","), nil
+	})
+}
+
+func decodeReceipt(raw json.RawMessage) (*types.Receipt, string, error) {
+	receipt := new(types.Receipt)
+	err := receipt.UnmarshalJSON(raw)
+	if err != nil {
+		return nil, "", err
+	}
+
+	json, err := receipt.MarshalJSON()
+	if err != nil {
+		return nil, "", fmt.Errorf("Unable to marshal tx receipt to json: %w", err)
+	}
+
+	return receipt, common.Bytes2Hex(json), nil
+}
+
+func hashParams(hashes []string) [][]any {
+	params := make([][]any, len(hashes))
+	for i, hash := range hashes {
+		params[i] = []any{common.HexToHash(hash)}
+	}
+	return params
+}
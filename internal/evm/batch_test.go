@@ -0,0 +1,160 @@
+package evm_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// batchServer answers single and batched requests from the given headers and
+// receipts, and counts the HTTP requests it gets. Like many public RPCs, it
+// doesn't have eth_getBlockReceipts.
+func batchServer(t *testing.T, headers []*types.Header, receipts map[common.Hash]*types.Receipt, requests *atomic.Int32) *httptest.Server {
+	byHash := make(map[common.Hash]*types.Header)
+	for _, header := range headers {
+		byHash[header.Hash()] = header
+	}
+
+	answer := func(request rpcRequest) map[string]any {
+		var result any
+		switch request.Method {
+		case "eth_chainId":
+			result = "0x1"
+		case "eth_getBlockByHash", "eth_getTransactionReceipt":
+			var hash common.Hash
+			assert.NoError(t, json.Unmarshal(request.Params[0], &hash))
+			if header, found := byHash[hash]; found {
+				result = header
+			}
+			if receipt, found := receipts[hash]; found {
+				result = receipt
+			}
+		}
+		if request.Method == "eth_getBlockReceipts" {
+			return map[string]any{"jsonrpc": "2.0", "id": request.ID, "error": map[string]any{"code": -32601, "message": "the method eth_getBlockReceipts does not exist"}}
+		}
+		return map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": result}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			var request rpcRequest
+			assert.NoError(t, json.Unmarshal(body, &request))
+			json.NewEncoder(w).Encode(answer(request))
+			return
+		}
+
+		var batch []rpcRequest
+		assert.NoError(t, json.Unmarshal(body, &batch))
+		responses := make([]map[string]any, len(batch))
+		for i, request := range batch {
+			responses[i] = answer(request)
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+}
+
+func batchClient(t *testing.T, server *httptest.Server) *evm.Client {
+	client := evm.NewOfflineClient(evm.Network{Name: "ethereum", ChainID: 1}, util.NewFileDB(t.TempDir()))
+	client.Network.RPCs = []string{server.URL + "/a", server.URL + "/b"}
+	client.Network.Consensus.Retries = 1
+	assert.NoError(t, client.Connect())
+	return client
+}
+
+// Batch tests
+
+func TestGetBlocks(t *testing.T) {
+	headers := headerChain(3)
+	var requests atomic.Int32
+	server := batchServer(t, headers, nil, &requests)
+	defer server.Close()
+	client := batchClient(t, server)
+
+	requests.Store(0)
+	blocks, err := client.GetBlocks([]string{headers[2].Hash().Hex(), headers[0].Hash().Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load()) // One batch to each of the quorum
+	assert.Equal(t, headers[2].Hash(), blocks[0].Hash())
+	assert.Equal(t, headers[0].Hash(), blocks[1].Hash())
+
+	_, err = client.GetBlocks([]string{headers[1].Hash().Hex(), common.Hash{}.Hex()})
+	assert.Error(t, err)
+}
+
+func TestGetTransactionReceipts(t *testing.T) {
+	hash := common.HexToHash("0x01")
+	receipts := map[common.Hash]*types.Receipt{
+		hash: {
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs:              []*types.Log{},
+			TxHash:            hash,
+			BlockHash:         common.HexToHash("0x02"),
+			GasUsed:           21000,
+		},
+	}
+	var requests atomic.Int32
+	server := batchServer(t, nil, receipts, &requests)
+	defer server.Close()
+	client := batchClient(t, server)
+
+	fetched, err := client.GetTransactionReceipts([]string{hash.Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(21000), fetched[0].GasUsed)
+	assert.Equal(t, common.HexToHash("0x02"), fetched[0].BlockHash)
+
+	empty, err := client.GetTransactionReceipts(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestGetBlockReceiptsUnsupported(t *testing.T) {
+	var requests atomic.Int32
+	server := batchServer(t, nil, nil, &requests)
+	defer server.Close()
+
+	db := util.NewFileDB(t.TempDir())
+	client := evm.NewOfflineClient(evm.Network{Name: "ethereum", ChainID: 1}, db)
+	client.Network.RPCs = []string{server.URL + "/a", server.URL + "/b"}
+	assert.NoError(t, client.Connect())
+
+	// Each RPC is asked once, without retrying
+	requests.Store(0)
+	_, err := client.GetBlockReceipts(common.HexToHash("0x02").Hex())
+	assert.ErrorIs(t, err, evm.METHOD_UNSUPPORTED)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// And not again
+	_, err = client.GetBlockReceipts(common.HexToHash("0x03").Hex())
+	assert.ErrorIs(t, err, evm.METHOD_UNSUPPORTED)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Without counting against either RPC
+	assert.NoError(t, client.SaveHealth())
+	health, err := evm.ReadRPCHealth(db, "ethereum")
+	assert.NoError(t, err)
+	for _, rpc := range client.Network.RPCs {
+		assert.Equal(t, 0, health[rpc].Errors)
+	}
+}
@@ -1,21 +1,68 @@
 package evm
 
 import (
+	"context"
 	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+// ParseSignature turns a function signature into an ABI method, with the
+// return types in a second set of parentheses, like
+// "getReserves()(uint112,uint112,uint32)" or "balanceOf(address)(uint256)".
+// Tuples are written as parenthesized lists of types. Without return types,
+// the method can be encoded but not decoded.
+func ParseSignature(sig string) (abi.Method, error) {
+	sig = strings.ReplaceAll(sig, " ", "")
+
+	open := strings.Index(sig, "(")
+	if open <= 0 {
+		return abi.Method{}, fmt.Errorf("Invalid signature '%s', expected name(types)", sig)
+	}
+	name := sig[:open]
+
+	inputsEnd, err := closingParen(sig, open)
+	if err != nil {
+		return abi.Method{}, fmt.Errorf("Invalid signature '%s': %w", sig, err)
+	}
+	inputs, err := parseArguments(sig[open+1 : inputsEnd])
+	if err != nil {
+		return abi.Method{}, fmt.Errorf("Invalid inputs in signature '%s': %w", sig, err)
+	}
+
+	outputs := abi.Arguments{}
+	if rest := sig[inputsEnd+1:]; rest != "" {
+		if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+			return abi.Method{}, fmt.Errorf("Invalid signature '%s', expected return types in parentheses", sig)
+		}
+		outputs, err = parseArguments(rest[1 : len(rest)-1])
+		if err != nil {
+			return abi.Method{}, fmt.Errorf("Invalid outputs in signature '%s': %w", sig, err)
+		}
+	}
+
+	return abi.NewMethod(name, name, abi.Function, "view", true, false, inputs, outputs), nil
+}
+
 func ethCall(to common.Address, sig string, args ...any) (ethereum.CallMsg, error) {
-	selector, err := encodeSelector(sig)
+	method, err := ParseSignature(sig)
 	if err != nil {
-		return ethereum.CallMsg{}, fmt.Errorf("Unable to encode selector for signature '%s': %w", sig, err)
+		return ethereum.CallMsg{}, err
 	}
+	return methodCall(to, method, args...)
+}
+
+func methodCall(to common.Address, method abi.Method, args ...any) (ethereum.CallMsg, error) {
+	selector := method.ID
 
-	encodedArgs, err := encodeArgs(sig, args)
+	encodedArgs, err := method.Inputs.Pack(args...)
 	if err != nil {
-		return ethereum.CallMsg{}, fmt.Errorf("Unable to encode args '%v': %w", args, err)
+		return ethereum.CallMsg{}, fmt.Errorf("Unable to encode args '%v' for %s: %w", args, method.Sig, err)
 	}
 
 	data := make([]byte, 0, len(selector)+len(encodedArgs))
@@ -28,45 +75,137 @@ func ethCall(to common.Address, sig string, args ...any) (ethereum.CallMsg, erro
 	}, nil
 }
 
-func encodeSelector(sig string) ([]byte, error) {
-	// TODO use something from geth to encode the signature to the 4-byte selector
-	return make([]byte, 0), nil
+// Call calls a view function by its signature, which has to include the
+// return types, at the given block (or the latest if nil). See CallMethod.
+func (c *Client) Call(to common.Address, sig string, block *big.Int, args ...any) ([]any, error) {
+	method, err := ParseSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return c.CallMethod(to, method, block, args...)
+}
+
+// CallMethod calls a view function at the given block (or the latest if nil)
+// once a quorum of RPCs agree on the result, and returns the decoded return
+// values, like *big.Int for uint256 or common.Address for address.
+func (c *Client) CallMethod(to common.Address, method abi.Method, block *big.Int, args ...any) ([]any, error) {
+	if len(method.Outputs) == 0 {
+		return nil, fmt.Errorf("No return types to decode %s with", method.Sig)
+	}
+
+	msg, err := methodCall(to, method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	call := fmt.Sprintf("%s on %s", method.Sig, to.Hex())
+	if block != nil {
+		call += fmt.Sprintf(" at block %s", block)
+	}
+
+	result, err := ensureAgreementWithRetry(c, call, func(client *ethclient.Client) ([]byte, string, error) {
+		result, e := client.CallContract(context.Background(), msg, block)
+		if e != nil {
+			return nil, "", e
+		}
+		return result, common.Bytes2Hex(result), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Could not call %s: %w", call, err)
+	}
+
+	return decodeOutputs(method, result)
 }
 
-func encodeArgs(sig string, args []any) ([]byte, error) {
-	// TODO use something from geth to encode the arguments
-	return make([]byte, 0), nil
+// closingParen finds the parenthesis closing the one at open.
+func closingParen(s string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("Unbalanced parentheses")
 }
 
-// TODO: replace with something like:
-// msg, err := ethCall(contract, "decimals()")
-func decimalsCall(to common.Address) ethereum.CallMsg {
-	return ethereum.CallMsg{
-		To:   &to,
-		Data: []byte{0x31, 0x3c, 0xe5, 0x67},
+// splitTypes splits a list of types on the commas outside any tuple.
+func splitTypes(list string) []string {
+	types := make([]string, 0)
+	depth, start := 0, 0
+	for i, ch := range list {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, list[start:i])
+				start = i + 1
+			}
+		}
 	}
+	return append(types, list[start:])
 }
 
-// TODO: replace with something like:
-// msg, err := ethCall(contract, "symbol()")
-func symbolCall(to common.Address) ethereum.CallMsg {
-	return ethereum.CallMsg{
-		To:   &to,
-		Data: []byte{0x95, 0xd8, 0x9b, 0x41},
+func parseArguments(list string) (abi.Arguments, error) {
+	arguments := abi.Arguments{}
+	if list == "" {
+		return arguments, nil
+	}
+
+	for _, typeName := range splitTypes(list) {
+		marshaling, err := parseType(typeName)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid type '%s': %w", typeName, err)
+		}
+		arguments = append(arguments, abi.Argument{Type: typ})
 	}
+
+	return arguments, nil
 }
 
-// TODO: replace with something like:
-// msg, err := ethCall(contract, "balanceOf(address)", addr)
-func balanceCall(to common.Address, a common.Address) ethereum.CallMsg {
-	data := make([]byte, 0, 36)
+// parseType turns a type into what abi.NewType takes, with tuple fields named
+// Field0, Field1 and so on since signatures don't name them.
+func parseType(typeName string) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(typeName, "(") {
+		if typeName == "" {
+			return abi.ArgumentMarshaling{}, fmt.Errorf("Empty type")
+		}
+		return abi.ArgumentMarshaling{Type: typeName}, nil
+	}
 
-	data = append(data, 0x70, 0xa0, 0x82, 0x31)
-	data = append(data, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0)
-	data = append(data, a.Bytes()...)
+	end, err := closingParen(typeName, 0)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("Invalid tuple '%s': %w", typeName, err)
+	}
 
-	return ethereum.CallMsg{
-		To:   &to,
-		Data: data,
+	components := make([]abi.ArgumentMarshaling, 0)
+	if fields := typeName[1:end]; fields != "" {
+		for i, field := range splitTypes(fields) {
+			component, err := parseType(field)
+			if err != nil {
+				return abi.ArgumentMarshaling{}, err
+			}
+			component.Name = fmt.Sprintf("Field%d", i)
+			components = append(components, component)
+		}
 	}
+
+	return abi.ArgumentMarshaling{Type: "tuple" + typeName[end+1:], Components: components}, nil
 }
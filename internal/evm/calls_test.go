@@ -0,0 +1,99 @@
+package evm_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// ParseSignature tests
+
+func TestParseSignatureSelectors(t *testing.T) {
+	for sig, selector := range map[string]string{
+		"decimals()":                            "0x313ce567",
+		"symbol()(string)":                      "0x95d89b41",
+		"balanceOf(address)(uint256)":           "0x70a08231",
+		"getReserves()(uint112,uint112,uint32)": "0x0902f1ac",
+		"transfer(address, uint256)":            "0xa9059cbb",
+	} {
+		method, err := evm.ParseSignature(sig)
+		assert.NoError(t, err, sig)
+		assert.Equal(t, selector, hexutil.Encode(method.ID), sig)
+	}
+}
+
+func TestParseSignatureTypes(t *testing.T) {
+	method, err := evm.ParseSignature("getPosition((address,uint24)[],bytes32)((uint128,int24),bool)")
+	assert.NoError(t, err)
+	assert.Equal(t, "getPosition((address,uint24)[],bytes32)", method.Sig)
+	assert.Len(t, method.Outputs, 2)
+	assert.Equal(t, "(uint128,int24)", method.Outputs[0].Type.String())
+
+	packed, err := method.Inputs.Pack([]struct {
+		Field0 common.Address
+		Field1 *big.Int
+	}{{alice, big.NewInt(3000)}}, [32]byte{})
+	assert.NoError(t, err)
+	assert.Len(t, packed, 5*32)
+
+	for _, sig := range []string{"", "noParens", "broken(uint256", "bad(notatype)", "double(uint256)uint256", "empty(uint256,)"} {
+		_, err := evm.ParseSignature(sig)
+		assert.Error(t, err, sig)
+	}
+}
+
+// Call tests
+
+func TestCall(t *testing.T) {
+	method, err := evm.ParseSignature("getReserves()(uint112,uint112,uint32)")
+	assert.NoError(t, err)
+	reserves, err := method.Outputs.Pack(big.NewInt(1000), big.NewInt(2000), uint32(1700000000))
+	assert.NoError(t, err)
+
+	blocks := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		var result any = "0x1"
+		if request.Method == "eth_call" {
+			var msg struct {
+				To   common.Address `json:"to"`
+				Data hexutil.Bytes  `json:"input"`
+			}
+			assert.NoError(t, json.Unmarshal(request.Params[0], &msg))
+			assert.Equal(t, token, msg.To)
+			assert.Equal(t, method.ID, []byte(msg.Data))
+
+			var block string
+			assert.NoError(t, json.Unmarshal(request.Params[1], &block))
+			blocks <- block
+			result = hexutil.Encode(reserves)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}))
+	defer server.Close()
+
+	client := evm.NewOfflineClient(evm.Network{Name: "ethereum", ChainID: 1}, util.NewFileDB(t.TempDir()))
+	client.Network.RPCs = []string{server.URL + "/a", server.URL + "/b"}
+
+	values, err := client.Call(token, "getReserves()(uint112,uint112,uint32)", big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Equal(t, []any{big.NewInt(1000), big.NewInt(2000), uint32(1700000000)}, values)
+	assert.Equal(t, "0x64", <-blocks)
+
+	_, err = client.Call(token, "getReserves()", nil)
+	assert.ErrorContains(t, err, "No return types")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -25,18 +26,26 @@ type Client struct {
 	Etherscan *EtherscanClient // A client for the etherscan-compatible explorer
 
 	connections     map[string]*ethclient.Client // Maps RPC URL to the corresponding eth client
+	connectMu       sync.Mutex                   // Held while connecting, since workers share the client
 	symbolCache     map[common.Address]string    // Caches token contract `symbol()` lookups
 	decimalCache    map[common.Address]uint8     // Caches token contract `decimals()` lookups
 	tokenDataCache  *util.FileDBCollection       // File cache for token data
 	internalTxCache *util.FileDBCollection       // File cache for etherscan internal txs
+	headerCache     *util.FileDBCollection       // File cache for headers tied to a checkpoint
+	ancestorCache   *util.FileDBCollection       // Lowest block walked down to from each checkpoint
+	health          *rpcHealth                   // How each RPC has been doing, to pick which to ask
+	unsupported     map[string]bool              // "<rpc> <method>" pairs the RPC said it doesn't have
+	unsupportedMu   sync.Mutex                   // Held while using unsupported
 }
 
-func NewClient(network Network) (*Client, error) {
+// NewClient returns a client for the RPCs and explorer of a network, which
+// keeps its caches in the given database.
+func NewClient(network Network, db *util.FileDB) (*Client, error) {
 	connections := make(map[string]*ethclient.Client, 0)
 	symbolCache := make(map[common.Address]string, 0)
 	decimalCache := make(map[common.Address]uint8, 0)
-	tokenDataCache := util.NewFileDB("data").NewCollection("token_data")
-	internalTxCache := util.NewFileDB("data").NewCollection("internal_txs")
+	tokenDataCache := db.NewCollection("token_data")
+	internalTxCache := db.NewCollection("internal_txs")
 	etherscanClient, err := NewEtherscanClient(network)
 	if err != nil {
 		return nil, err
@@ -50,11 +59,52 @@ func NewClient(network Network) (*Client, error) {
 		decimalCache:    decimalCache,
 		tokenDataCache:  tokenDataCache,
 		internalTxCache: internalTxCache,
+		headerCache:     db.NewCollection("trusted_headers"),
+		ancestorCache:   db.NewCollection("checkpoint_ancestors"),
+		health:          newRPCHealth(network.Name.String(), db),
+		unsupported:     make(map[string]bool),
 	}, nil
 }
 
+// NewOfflineClient returns a client that never connects to an RPC or explorer,
+// and keeps its caches in the given database. Anything not cached fails, which
+// is what replaying fixed transactions in tests needs.
+func NewOfflineClient(network Network, db *util.FileDB) *Client {
+	network.RPCs = nil
+
+	return &Client{
+		Network:         network,
+		connections:     make(map[string]*ethclient.Client, 0),
+		symbolCache:     make(map[common.Address]string, 0),
+		decimalCache:    make(map[common.Address]uint8, 0),
+		tokenDataCache:  db.NewCollection("token_data"),
+		internalTxCache: db.NewCollection("internal_txs"),
+		headerCache:     db.NewCollection("trusted_headers"),
+		ancestorCache:   db.NewCollection("checkpoint_ancestors"),
+		health:          newRPCHealth(network.Name.String(), db),
+		unsupported:     make(map[string]bool),
+	}
+}
+
+// CacheToken remembers the symbol and decimals of a token, as if they had
+// been looked up already.
+func (c *Client) CacheToken(token common.Address, symbol string, decimals uint8) {
+	c.symbolCache[token] = symbol
+	c.decimalCache[token] = decimals
+}
+
+// CacheInternalTransactions remembers the internal transactions of a
+// transaction, as if they had been fetched already.
+func (c *Client) CacheInternalTransactions(hash string, txs []etherscan.InternalTx) error {
+	return c.internalTxCache.Write(fmt.Sprintf("%s-%s", c.Network.Name, hash), txs)
+}
+
 func (c *Client) Connect() error {
-	if len(c.connections) >= QUORUM {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+
+	quorum := c.Network.QuorumSize()
+	if len(c.connections) >= quorum {
 		return nil
 	}
 
@@ -71,8 +121,8 @@ func (c *Client) Connect() error {
 		}
 	}
 
-	if len(c.connections) < QUORUM {
-		return fmt.Errorf("Connected to less than quorum of %d clients for chain ID %d (only found %d)", QUORUM, c.Network.ChainID, len(c.connections))
+	if len(c.connections) < quorum {
+		return fmt.Errorf("Connected to less than quorum of %d clients for chain ID %d (only found %d)", quorum, c.Network.ChainID, len(c.connections))
 	}
 
 	return nil
@@ -84,7 +134,7 @@ func (c *Client) LatestBlock() (uint64, error) {
 		return 0, err
 	}
 
-	return ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (uint64, uint64, error) {
+	return ensureAgreementWithRetry(c, "eth_blockNumber", func(client *ethclient.Client) (uint64, uint64, error) {
 		num, err := client.BlockNumber(context.Background())
 		return num, num, err
 	})
@@ -96,7 +146,7 @@ func (c *Client) GetTransaction(hash string) (*types.Transaction, error) {
 		return nil, err
 	}
 
-	return ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (*types.Transaction, string, error) {
+	return ensureAgreementWithRetry(c, "eth_getTransactionByHash "+hash, func(client *ethclient.Client) (*types.Transaction, string, error) {
 		tx, _, err := client.TransactionByHash(context.Background(), common.HexToHash(hash))
 		if err != nil {
 			return nil, "", err
@@ -117,7 +167,7 @@ func (c *Client) GetTransactionReceipt(hash string) (*types.Receipt, error) {
 		return nil, err
 	}
 
-	return ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (*types.Receipt, string, error) {
+	return ensureAgreementWithRetry(c, "eth_getTransactionReceipt "+hash, func(client *ethclient.Client) (*types.Receipt, string, error) {
 		receipt, err := client.TransactionReceipt(context.Background(), common.HexToHash(hash))
 		if err != nil {
 			return nil, "", err
@@ -144,6 +194,10 @@ func (c *Client) GetInternalTransactions(hash string) ([]etherscan.InternalTx, b
 		return txs, true, nil
 	}
 
+	if c.Etherscan == nil {
+		return nil, false, fmt.Errorf("No explorer to fetch internal transactions of %s from", hash)
+	}
+
 	txs, err = c.Etherscan.GetInternalTransfers(hash)
 	if err != nil {
 		return nil, false, err
@@ -163,7 +217,7 @@ func (c *Client) GetBlock(hash string) (*types.Header, error) {
 		return nil, err
 	}
 
-	return ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (*types.Header, string, error) {
+	return ensureAgreementWithRetry(c, "eth_getBlockByHash "+hash, func(client *ethclient.Client) (*types.Header, string, error) {
 		blockHeader, err := client.HeaderByHash(context.Background(), common.HexToHash(hash))
 		if err != nil {
 			return nil, "", err
@@ -184,7 +238,7 @@ func (c *Client) Balance(address common.Address) (core.Amount, error) {
 		return core.Amount{}, err
 	}
 
-	balance, err := ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (string, string, error) {
+	balance, err := ensureAgreementWithRetry(c, "eth_getBalance "+address.Hex(), func(client *ethclient.Client) (string, string, error) {
 		bal, e := client.BalanceAt(context.Background(), address, nil)
 		if e != nil {
 			return "", "", e
@@ -223,8 +277,13 @@ func (c *Client) Erc20Decimals(token common.Address) (uint8, error) {
 		return 0, err
 	}
 
-	decimals, err := ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (uint8, uint8, error) {
-		result, e := client.CallContract(context.Background(), decimalsCall(token), nil)
+	msg, err := ethCall(token, "decimals()")
+	if err != nil {
+		return 0, err
+	}
+
+	decimals, err := ensureAgreementWithRetry(c, "decimals() on "+token.Hex(), func(client *ethclient.Client) (uint8, uint8, error) {
+		result, e := client.CallContract(context.Background(), msg, nil)
 		if e != nil {
 			return 0, 0, e
 		}
@@ -270,8 +329,13 @@ func (c *Client) TokenSymbol(token common.Address) (string, error) {
 		return "", err
 	}
 
-	symbol, err := ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (string, string, error) {
-		result, e := client.CallContract(context.Background(), symbolCall(token), nil)
+	msg, err := ethCall(token, "symbol()")
+	if err != nil {
+		return "", err
+	}
+
+	symbol, err := ensureAgreementWithRetry(c, "symbol() on "+token.Hex(), func(client *ethclient.Client) (string, string, error) {
+		result, e := client.CallContract(context.Background(), msg, nil)
 		if e != nil {
 			return "", "", e
 		}
@@ -320,6 +384,25 @@ func (c *Client) TokenAsset(token common.Address, instadapp ...bool) (core.Asset
 	return c.Network.Erc20TokenAsset(token.Hex(), symbol, decimals), nil
 }
 
+// NftAsset returns the asset for the token ID of an NFT transfer. Many ERC-1155
+// contracts have no symbol, so those fall back to one based on the contract.
+func (c *Client) NftAsset(transfer NftTransfer) (core.Asset, error) {
+	symbol, err := c.TokenSymbol(transfer.Contract)
+	if err != nil || symbol == "" {
+		util.Debugf("No symbol for NFT contract %s on %s, using fallback\n", transfer.Contract, c.Network.Name)
+		symbol = "NFT-" + transfer.Contract.Hex()[2:8]
+	}
+
+	switch transfer.Kind {
+	case core.Erc721Nft:
+		return c.Network.Erc721NftAsset(transfer.Contract.Hex(), symbol, transfer.TokenID), nil
+	case core.Erc1155Nft:
+		return c.Network.Erc1155NftAsset(transfer.Contract.Hex(), symbol, transfer.TokenID), nil
+	default:
+		return core.Asset{}, fmt.Errorf("Unknown NFT kind '%s' for %s on %s", transfer.Kind, transfer.Contract, c.Network.Name)
+	}
+}
+
 func (c *Client) Erc20Balance(token common.Address, address common.Address) (core.Amount, error) {
 	err := c.Connect()
 	if err != nil {
@@ -338,8 +421,13 @@ func (c *Client) Erc20Balance(token common.Address, address common.Address) (cor
 
 	asset := c.Network.Erc20TokenAsset(token.String(), symbol, decimals)
 
-	balanceStr, err := ensureAgreementWithRetry(c.connections, func(client *ethclient.Client) (string, string, error) {
-		result, e := client.CallContract(context.Background(), balanceCall(token, address), nil)
+	msg, err := ethCall(token, "balanceOf(address)", address)
+	if err != nil {
+		return core.Amount{}, err
+	}
+
+	balanceStr, err := ensureAgreementWithRetry(c, "balanceOf("+address.Hex()+") on "+token.Hex(), func(client *ethclient.Client) (string, string, error) {
+		result, e := client.CallContract(context.Background(), msg, nil)
 		if e != nil {
 			return "", "", e
 		}
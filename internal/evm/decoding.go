@@ -2,14 +2,24 @@ package evm
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 )
 
-// TODO: consider replacing these with something more generic that decodes any
-// abi-encoded values
+// decodeOutputs decodes the return values of a call to a method, which is how
+// anything but the lenient cases below should be decoded.
+func decodeOutputs(method abi.Method, data []byte) ([]any, error) {
+	values, err := method.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decode result of %s: %w", method.Sig, err)
+	}
+
+	return values, nil
+}
 
+// Some tokens return nothing, or more than a uint8, for decimals()
 func decodeUint8(data []byte) uint8 {
 	if len(data) == 0 {
 		return 0
@@ -0,0 +1,259 @@
+package evm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// Calls remembered per RPC before the older ones start counting for less, so
+// an RPC that was flaky once can earn its place back
+const HEALTH_WINDOW = 200
+
+// How often the health is saved while calls are being made. Whatever is left
+// is saved by SaveHealth when a step is done.
+const HEALTH_SAVE_INTERVAL = 30 * time.Second
+
+// RPCHealth is how an RPC has done on the calls made to it.
+type RPCHealth struct {
+	Calls         int           `json:"calls"`
+	Errors        int           `json:"errors"`
+	Disagreements int           `json:"disagreements"` // Answers that differed from the agreed one
+	Latency       time.Duration `json:"latency"`       // Total of the calls that were answered
+}
+
+func (h RPCHealth) AverageLatency() time.Duration {
+	answered := h.Calls - h.Errors
+	if answered <= 0 {
+		return 0
+	}
+	return h.Latency / time.Duration(answered)
+}
+
+// Score ranks an RPC from 0 to 1: the share of calls it answered and agreed
+// on, discounted by how slow it is. RPCs that haven't been used yet get the
+// benefit of the doubt.
+func (h RPCHealth) Score() float64 {
+	if h.Calls == 0 {
+		return 1
+	}
+	good := float64(h.Calls-h.Errors-h.Disagreements) / float64(h.Calls)
+	return max(good, 0) / (1 + h.AverageLatency().Seconds())
+}
+
+// A Disagreement is a call that the RPCs asked answered differently, kept to
+// find out which one was wrong.
+type Disagreement struct {
+	Network string            `json:"network"`
+	Call    string            `json:"call"`
+	Time    time.Time         `json:"time"`
+	Agreed  string            `json:"agreed"`  // The answer that reached quorum, if any did
+	Answers map[string]string `json:"answers"` // RPC URL to what it answered
+	Errors  map[string]string `json:"errors"`  // RPC URL to the error it returned
+}
+
+// rpcHealth keeps the health of each RPC of a network, shared by every call on
+// the client and persisted between runs.
+type rpcHealth struct {
+	mu              sync.Mutex
+	network         string
+	rpcs            map[string]*RPCHealth
+	dirty           bool      // Changed since it was last saved
+	saved           time.Time // When it was last saved, or loaded
+	healthDB        *util.FileDBCollection
+	disagreementsDB *util.FileDBCollection
+}
+
+func newRPCHealth(network string, db *util.FileDB) *rpcHealth {
+	h := &rpcHealth{
+		network:         network,
+		rpcs:            make(map[string]*RPCHealth),
+		saved:           time.Now(),
+		healthDB:        db.NewCollection("rpc_health"),
+		disagreementsDB: db.NewCollection("disagreements"),
+	}
+
+	_, err := h.healthDB.Read(network, &h.rpcs)
+	if err != nil {
+		fmt.Printf("Error reading RPC health for %s: %s\n", network, err.Error())
+	}
+
+	return h
+}
+
+// SaveHealth saves how the RPCs have done since the health was last saved.
+func (c *Client) SaveHealth() error {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+
+	return c.health.save()
+}
+
+// ReadRPCHealth returns the health recorded for the RPCs of a network, keyed
+// by URL.
+func ReadRPCHealth(db *util.FileDB, network string) (map[string]RPCHealth, error) {
+	health := make(map[string]RPCHealth)
+
+	collection, found := db.OpenCollection("rpc_health")
+	if !found {
+		return health, nil
+	}
+
+	_, err := collection.Read(network, &health)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading RPC health for %s: %w", network, err)
+	}
+
+	return health, nil
+}
+
+// CountDisagreements returns how many disagreements were recorded for a
+// network.
+func CountDisagreements(db *util.FileDB, network string) (int, error) {
+	collection, found := db.OpenCollection("disagreements")
+	if !found {
+		return 0, nil
+	}
+
+	keys, err := collection.List()
+	if err != nil {
+		return 0, fmt.Errorf("Error listing disagreements: %w", err)
+	}
+
+	count := 0
+	for _, key := range keys {
+		if strings.HasPrefix(key, network+"-") {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ranked orders the RPCs from healthiest to least healthy.
+func (h *rpcHealth) ranked(rpcs []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	scores := make(map[string]float64, len(rpcs))
+	for _, rpc := range rpcs {
+		scores[rpc] = h.get(rpc).Score()
+	}
+
+	ranked := make([]string, len(rpcs))
+	copy(ranked, rpcs)
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	return ranked
+}
+
+func (h *rpcHealth) recordAnswer(rpc string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := h.get(rpc)
+	health.Calls++
+	health.Latency += latency
+	decay(health)
+}
+
+func (h *rpcHealth) recordError(rpc string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := h.get(rpc)
+	health.Calls++
+	health.Errors++
+	decay(health)
+}
+
+// settle counts against the RPCs that answered differently from the agreed
+// answer, reports the call if they didn't all agree, and saves the health if
+// it hasn't been for a while.
+func (h *rpcHealth) settle(call string, answers, failures map[string]string, agreed string, found bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	distinct := make(map[string]bool)
+	for _, answer := range answers {
+		distinct[answer] = true
+	}
+
+	if len(distinct) > 1 {
+		if found {
+			for rpc, answer := range answers {
+				if answer != agreed {
+					h.get(rpc).Disagreements++
+				}
+			}
+		}
+
+		disagreement := Disagreement{
+			Network: h.network,
+			Call:    call,
+			Time:    time.Now().UTC(),
+			Agreed:  agreed,
+			Answers: answers,
+			Errors:  failures,
+		}
+		key := fmt.Sprintf("%s-%d", h.network, disagreement.Time.UnixNano())
+		err := h.disagreementsDB.Write(key, disagreement)
+		if err != nil {
+			fmt.Printf("Error recording disagreement on %s: %s\n", call, err.Error())
+		}
+		util.Debugf("RPCs for %s disagreed on %s\n", h.network, call)
+	}
+
+	h.dirty = true
+	if time.Since(h.saved) >= HEALTH_SAVE_INTERVAL {
+		err := h.save()
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+}
+
+// save writes the health if anything changed since it was last saved. The
+// lock has to be held.
+func (h *rpcHealth) save() error {
+	if !h.dirty {
+		return nil
+	}
+
+	err := h.healthDB.Write(h.network, h.rpcs)
+	if err != nil {
+		return fmt.Errorf("Error saving RPC health for %s: %w", h.network, err)
+	}
+
+	h.dirty = false
+	h.saved = time.Now()
+	return nil
+}
+
+func (h *rpcHealth) get(rpc string) *RPCHealth {
+	health, found := h.rpcs[rpc]
+	if !found {
+		health = &RPCHealth{}
+		h.rpcs[rpc] = health
+	}
+	return health
+}
+
+// decay halves the counts once an RPC has been called HEALTH_WINDOW times,
+// keeping its score but letting newer calls move it faster.
+func decay(health *RPCHealth) {
+	if health.Calls < HEALTH_WINDOW {
+		return
+	}
+	health.Calls /= 2
+	health.Errors /= 2
+	health.Disagreements /= 2
+	health.Latency /= 2
+}
@@ -0,0 +1,101 @@
+package evm_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockNumberServer serves an RPC at each path, answering eth_blockNumber with
+// the number for that path, or with an error if it is empty.
+func blockNumberServer(t *testing.T, numbers map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		number := numbers[r.URL.Path]
+
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+		switch {
+		case request.Method == "eth_chainId":
+			response["result"] = "0x1"
+		case number == "":
+			response["error"] = map[string]any{"code": -32000, "message": "unavailable"}
+		default:
+			response["result"] = number
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+// Consensus tests
+
+func TestConsensusDemotesDisagreeingRPC(t *testing.T) {
+	// Untried RPCs are ranked by URL, so the liar is asked first
+	server := blockNumberServer(t, map[string]string{"/a": "0x1", "/b": "0x10", "/c": "0x10"})
+	defer server.Close()
+	liar, honest := server.URL+"/a", server.URL+"/b"
+
+	db := util.NewFileDB(t.TempDir())
+	client := evm.NewOfflineClient(evm.Network{Name: "ethereum", ChainID: 1}, db)
+	client.Network.RPCs = []string{liar, honest, server.URL + "/c"}
+
+	for i := 0; i < 3; i++ {
+		block, err := client.LatestBlock()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(16), block)
+	}
+
+	// Saved periodically, so nothing is written until asked
+	health, err := evm.ReadRPCHealth(db, "ethereum")
+	assert.NoError(t, err)
+	assert.Empty(t, health)
+
+	assert.NoError(t, client.SaveHealth())
+	health, err = evm.ReadRPCHealth(db, "ethereum")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, health[liar].Disagreements)
+	assert.Equal(t, 1, health[liar].Calls)
+	assert.Equal(t, 3, health[honest].Calls)
+	assert.Less(t, health[liar].Score(), health[honest].Score())
+
+	disagreements, err := evm.CountDisagreements(db, "ethereum")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, disagreements)
+}
+
+func TestConsensusQuorumPerNetwork(t *testing.T) {
+	server := blockNumberServer(t, map[string]string{"/a": "", "/b": "0x10"})
+	defer server.Close()
+
+	network := evm.Network{Name: "ethereum", ChainID: 1}
+	network.Consensus.Quorum = 1
+	network.Consensus.Retries = 1
+	client := evm.NewOfflineClient(network, util.NewFileDB(t.TempDir()))
+	client.Network.RPCs = []string{server.URL + "/a", server.URL + "/b"}
+
+	block, err := client.LatestBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), block)
+
+	network.Consensus.Quorum = 3
+	client = evm.NewOfflineClient(network, util.NewFileDB(t.TempDir()))
+	client.Network.RPCs = []string{server.URL + "/a", server.URL + "/b"}
+
+	_, err = client.LatestBlock()
+	assert.ErrorContains(t, err, "less than quorum of 3")
+}
+
+func TestRPCHealthScore(t *testing.T) {
+	assert.Equal(t, 1.0, evm.RPCHealth{}.Score())
+	assert.Equal(t, 0.5, evm.RPCHealth{Calls: 4, Errors: 1, Disagreements: 1}.Score())
+	assert.Equal(t, 0.0, evm.RPCHealth{Calls: 2, Errors: 2}.Score())
+}
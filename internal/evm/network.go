@@ -2,6 +2,7 @@ package evm
 
 import (
 	"fmt"
+	"math/big"
 	"os/exec"
 	"strings"
 	"time"
@@ -13,12 +14,13 @@ import (
 const ZERO_ADDRESS = "0x0000000000000000000000000000000000000000"
 
 type Network struct {
-	Name              NetworkName `mapstructure:"name"`
-	ChainID           uint        `mapstructure:"chain_id"`
-	NativeAssetSymbol string      `mapstructure:"native_asset"`
-	RPCs              []string    `mapstructure:"rpcs"`
-	SettlesTo         NetworkName `mapstructure:"settles_to"`
-	Deprecated        bool        `mapstructure:"deprecated"`
+	Name              NetworkName  `mapstructure:"name"`
+	ChainID           uint         `mapstructure:"chain_id"`
+	NativeAssetSymbol string       `mapstructure:"native_asset"`
+	RPCs              []string     `mapstructure:"rpcs"`
+	SettlesTo         NetworkName  `mapstructure:"settles_to"`
+	Deprecated        bool         `mapstructure:"deprecated"`
+	Checkpoints       []Checkpoint `mapstructure:"checkpoints"` // Trusted blocks to verify fetched transactions against (default none)
 	ExplorerURLs      struct {
 		Tx   string `mapstructure:"tx"`
 		Addr string `mapstructure:"addr"`
@@ -28,6 +30,10 @@ type Network struct {
 		Key string `mapstructure:"key"`
 		RPS uint   `mapstructure:"rps"`
 	} `mapstructure:"etherscan"`
+	Consensus struct {
+		Quorum  int `mapstructure:"quorum"`  // RPCs that have to agree on each answer (default QUORUM)
+		Retries int `mapstructure:"retries"` // Times to ask again before giving up (default CONSENSUS_RETRIES)
+	} `mapstructure:"consensus"`
 }
 
 func (n Network) GetKind() core.NetworkKind {
@@ -42,6 +48,31 @@ func (n Network) GetDeprecated() bool {
 	return n.Deprecated
 }
 
+func (n Network) QuorumSize() int {
+	if n.Consensus.Quorum > 0 {
+		return n.Consensus.Quorum
+	}
+	return QUORUM
+}
+
+func (n Network) ConsensusRetries() int {
+	if n.Consensus.Retries > 0 {
+		return n.Consensus.Retries
+	}
+	return CONSENSUS_RETRIES
+}
+
+// Covers tells whether a block is at or below a checkpoint, so that it can be
+// verified against one.
+func (n Network) Covers(number uint64) bool {
+	for _, checkpoint := range n.Checkpoints {
+		if checkpoint.Number >= number {
+			return true
+		}
+	}
+	return false
+}
+
 func (n Network) NativeAsset() core.Asset {
 	return core.Asset{
 		NetworkKind: core.EvmNetworkKind,
@@ -64,12 +95,23 @@ func (n Network) Erc20TokenAsset(contractAddress, symbol string, decimals uint8)
 	}
 }
 
-func (n Network) Erc721NftAsset(contractAddress, symbol string, tokenID uint64) core.Asset {
+func (n Network) Erc721NftAsset(contractAddress, symbol string, tokenID *big.Int) core.Asset {
 	return core.Asset{
 		NetworkKind: core.EvmNetworkKind,
 		NetworkName: n.Name.String(),
 		Kind:        core.Erc721Nft,
-		Identifier:  fmt.Sprintf("%s/%d", contractAddress, tokenID),
+		Identifier:  fmt.Sprintf("%s/%s", contractAddress, tokenID),
+		Symbol:      symbol,
+		Decimals:    0,
+	}
+}
+
+func (n Network) Erc1155NftAsset(contractAddress, symbol string, tokenID *big.Int) core.Asset {
+	return core.Asset{
+		NetworkKind: core.EvmNetworkKind,
+		NetworkName: n.Name.String(),
+		Kind:        core.Erc1155Nft,
+		Identifier:  fmt.Sprintf("%s/%s", contractAddress, tokenID),
 		Symbol:      symbol,
 		Decimals:    0,
 	}
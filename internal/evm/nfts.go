@@ -0,0 +1,106 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/abis"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+// An NftTransfer is a movement of a single token ID of an ERC-721 or ERC-1155
+// contract. Batch transfers are split into one NftTransfer per token ID.
+type NftTransfer struct {
+	Contract common.Address
+	Kind     core.AssetKind // Either core.Erc721Nft or core.Erc1155Nft
+	From     common.Address
+	To       common.Address
+	TokenID  *big.Int
+	Value    *big.Int // Always 1 for ERC-721
+}
+
+// ParseNftTransfers finds the ERC-721 Transfer and ERC-1155 TransferSingle and
+// TransferBatch events in the logs. ERC-721 Transfer shares its signature with
+// ERC-20 Transfer, but indexes the token ID, so ERC-20 logs (with one topic
+// fewer) are skipped.
+func ParseNftTransfers(network string, logs []*types.Log) ([]NftTransfer, error) {
+	transfers := make([]NftTransfer, 0)
+
+	erc721Logs, err := ParseKnownEvents(network, logs, abis.Erc721Abi)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, log := range erc721Logs {
+		if log.Name != "Transfer" {
+			continue
+		}
+		from, fromOk := log.Data["from"].(common.Address)
+		to, toOk := log.Data["to"].(common.Address)
+		tokenID, tokenIDOk := log.Data["tokenId"].(*big.Int)
+		if !fromOk || !toOk || !tokenIDOk {
+			continue
+		}
+
+		transfers = append(transfers, NftTransfer{
+			Contract: log.Contract,
+			Kind:     core.Erc721Nft,
+			From:     from,
+			To:       to,
+			TokenID:  tokenID,
+			Value:    big.NewInt(1),
+		})
+	}
+
+	erc1155Logs, err := ParseKnownEvents(network, logs, abis.Erc1155Abi)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, log := range erc1155Logs {
+		if log.Name != "TransferSingle" && log.Name != "TransferBatch" {
+			continue
+		}
+		from, fromOk := log.Data["from"].(common.Address)
+		to, toOk := log.Data["to"].(common.Address)
+		if !fromOk || !toOk {
+			continue
+		}
+
+		var ids []*big.Int
+		var values []*big.Int
+		if log.Name == "TransferSingle" {
+			id, idOk := log.Data["id"].(*big.Int)
+			value, valueOk := log.Data["value"].(*big.Int)
+			if !idOk || !valueOk {
+				continue
+			}
+			ids = []*big.Int{id}
+			values = []*big.Int{value}
+		} else {
+			var idsOk, valuesOk bool
+			ids, idsOk = log.Data["ids"].([]*big.Int)
+			values, valuesOk = log.Data["values"].([]*big.Int)
+			if !idsOk || !valuesOk || len(ids) != len(values) {
+				continue
+			}
+		}
+
+		for i, id := range ids {
+			if values[i].Sign() == 0 {
+				continue
+			}
+			transfers = append(transfers, NftTransfer{
+				Contract: log.Contract,
+				Kind:     core.Erc1155Nft,
+				From:     from,
+				To:       to,
+				TokenID:  id,
+				Value:    values[i],
+			})
+		}
+	}
+
+	return transfers, nil
+}
@@ -0,0 +1,124 @@
+package evm_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/abis"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	alice    = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	bob      = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	operator = common.HexToAddress("0x000000000000000000000000000000000000abcd")
+	token    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	punks    = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	items    = common.HexToAddress("0x3333333333333333333333333333333333333333")
+)
+
+func addressTopic(addr common.Address) common.Hash {
+	return common.BytesToHash(addr.Bytes())
+}
+
+func packData(t *testing.T, contractAbi abi.ABI, event string, args ...any) []byte {
+	data, err := contractAbi.Events[event].Inputs.NonIndexed().Pack(args...)
+	assert.NoError(t, err)
+	return data
+}
+
+// ParseNftTransfers tests
+
+func TestParseNftTransfers(t *testing.T) {
+	logs := []*types.Log{
+		{ // ERC-20 Transfer, same signature but only three topics
+			Address: token,
+			Topics: []common.Hash{
+				abis.Erc20Abi.Events["Transfer"].ID,
+				addressTopic(alice),
+				addressTopic(bob),
+			},
+			Data: packData(t, abis.Erc20Abi, "Transfer", big.NewInt(1000)),
+		},
+		{ // ERC-721 Transfer of token 7, minted to alice
+			Address: punks,
+			Topics: []common.Hash{
+				abis.Erc721Abi.Events["Transfer"].ID,
+				addressTopic(common.Address{}),
+				addressTopic(alice),
+				common.BigToHash(big.NewInt(7)),
+			},
+		},
+		{ // ERC-1155 TransferSingle of 3 of token 42
+			Address: items,
+			Topics: []common.Hash{
+				abis.Erc1155Abi.Events["TransferSingle"].ID,
+				addressTopic(operator),
+				addressTopic(alice),
+				addressTopic(bob),
+			},
+			Data: packData(t, abis.Erc1155Abi, "TransferSingle", big.NewInt(42), big.NewInt(3)),
+		},
+		{ // ERC-1155 TransferBatch, with a zero value that should be skipped
+			Address: items,
+			Topics: []common.Hash{
+				abis.Erc1155Abi.Events["TransferBatch"].ID,
+				addressTopic(operator),
+				addressTopic(bob),
+				addressTopic(alice),
+			},
+			Data: packData(t, abis.Erc1155Abi, "TransferBatch",
+				[]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)},
+				[]*big.Int{big.NewInt(5), big.NewInt(0), big.NewInt(1)},
+			),
+		},
+	}
+
+	transfers, err := evm.ParseNftTransfers("ethereum", logs)
+	assert.NoError(t, err)
+	assert.Equal(t, []evm.NftTransfer{
+		{punks, core.Erc721Nft, common.Address{}, alice, big.NewInt(7), big.NewInt(1)},
+		{items, core.Erc1155Nft, alice, bob, big.NewInt(42), big.NewInt(3)},
+		{items, core.Erc1155Nft, bob, alice, big.NewInt(1), big.NewInt(5)},
+		{items, core.Erc1155Nft, bob, alice, big.NewInt(3), big.NewInt(1)},
+	}, transfers)
+}
+
+func TestParseNftTransfersIgnoresErc20(t *testing.T) {
+	logs := []*types.Log{
+		{
+			Address: token,
+			Topics: []common.Hash{
+				abis.Erc20Abi.Events["Transfer"].ID,
+				addressTopic(alice),
+				addressTopic(bob),
+			},
+			Data: packData(t, abis.Erc20Abi, "Transfer", big.NewInt(1000)),
+		},
+	}
+
+	transfers, err := evm.ParseNftTransfers("ethereum", logs)
+	assert.NoError(t, err)
+	assert.Empty(t, transfers)
+}
+
+// Erc721NftAsset and Erc1155NftAsset tests
+
+func TestNftAssets(t *testing.T) {
+	tokenID, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+
+	erc721 := evm.Network{Name: evm.Ethereum}.Erc721NftAsset(punks.Hex(), "PUNK", tokenID)
+	assert.Equal(t, core.Erc721Nft, erc721.Kind)
+	assert.Equal(t, punks.Hex()+"/"+tokenID.String(), erc721.Identifier)
+	assert.True(t, erc721.IsNft())
+
+	erc1155 := evm.Network{Name: evm.Ethereum}.Erc1155NftAsset(items.Hex(), "ITEM", big.NewInt(42))
+	assert.Equal(t, core.Erc1155Nft, erc1155.Kind)
+	assert.Equal(t, items.Hex()+"/42", erc1155.Identifier)
+	assert.False(t, erc1155.FungibleWith(evm.Network{Name: evm.Ethereum}.Erc1155NftAsset(items.Hex(), "ITEM", big.NewInt(43))))
+}
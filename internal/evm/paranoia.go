@@ -1,31 +1,47 @@
 package evm
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ksmithbaylor/gohodl/internal/util"
 )
 
+// Defaults for networks without their own consensus settings
 const (
 	QUORUM            int = 2
 	CONSENSUS_RETRIES int = 5
 )
 
+// JSON-RPC error code for a method the RPC doesn't have
+const METHOD_NOT_FOUND = -32601
+
+// Returned when too few RPCs support a method to ever reach a quorum, which no
+// amount of retrying will change
+var METHOD_UNSUPPORTED = errors.New("method not supported by enough RPCs")
+
 func ensureAgreementWithRetry[R any, C comparable](
-	connections map[string]*ethclient.Client,
+	c *Client,
+	call string,
 	action func(*ethclient.Client) (R, C, error),
 ) (R, error) {
 	var ret R
 	var err error
 
-	for i := 0; i < CONSENSUS_RETRIES; i++ {
-		ret, err = ensureAgreement(connections, action)
+	for i := 0; i < c.Network.ConsensusRetries(); i++ {
+		ret, err = ensureAgreement(c, call, action)
 
 		if err == nil {
 			return ret, nil
+		} else if errors.Is(err, METHOD_UNSUPPORTED) {
+			return ret, err
 		} else {
 			util.Debug(err)
 			time.Sleep(time.Millisecond * 500)
@@ -35,70 +51,130 @@ func ensureAgreementWithRetry[R any, C comparable](
 	return ret, err
 }
 
+// ensureAgreement asks the healthiest RPCs at once, then the rest one at a
+// time, until a quorum of them give the same answer. Every answer and error
+// is scored, so RPCs that are slow, fail or disagree get asked later.
 func ensureAgreement[R any, C comparable](
-	connections map[string]*ethclient.Client,
+	c *Client,
+	call string,
 	getUsing func(*ethclient.Client) (R, C, error),
 ) (R, error) {
-	tried := make(map[string]bool, len(connections))
-	var rpcs []string
-	var clients []*ethclient.Client
+	quorum := c.Network.QuorumSize()
+	method, _, _ := strings.Cut(call, " ")
+	rpcs := c.rpcsSupporting(method)
+	if len(rpcs) < quorum && len(rpcs) < len(c.connections) {
+		var nothing R
+		return nothing, fmt.Errorf("Only %d RPCs for %s support %s, short of a quorum of %d: %w", len(rpcs), c.Network.Name, method, quorum, METHOD_UNSUPPORTED)
+	}
+
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	for rpc, client := range connections {
-		clients = append(clients, client)
-		rpcs = append(rpcs, rpc)
-		tried[rpc] = true
-		if len(clients) == QUORUM {
-			break
+	votes := make(map[C]int, 0)
+	results := make(map[C]R, 0)
+	answers := make(map[string]C, 0)
+	failures := make(map[string]string, 0)
+
+	ask := func(rpc string) {
+		start := time.Now()
+		result, compKey, err := getUsing(c.connections[rpc])
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Not having a method says nothing about how healthy an RPC is, so it
+		// is only remembered to stop asking
+		if isMethodNotFound(err) {
+			c.markUnsupported(rpc, method)
+			failures[rpc] = err.Error()
+			util.Debugf("%s does not support %s\n", rpc, method)
+			return
+		}
+
+		if err != nil {
+			c.health.recordError(rpc)
+			failures[rpc] = err.Error()
+			util.Debugf("Problem with %s: %s\n", rpc, err.Error())
+			return
 		}
+
+		c.health.recordAnswer(rpc, time.Since(start))
+		results[compKey] = result
+		votes[compKey]++
+		answers[rpc] = compKey
+		util.Debugf("Success from %s: %#+v\n", rpc, result)
 	}
 
-	votes := make(map[C]int, 0)
-	answers := make(map[C]R, 0)
+	agreed := func() (C, bool) {
+		for compKey, voteCount := range votes {
+			if voteCount >= quorum {
+				return compKey, true
+			}
+		}
+		var none C
+		return none, false
+	}
 
-	for i, client := range clients {
+	first := min(quorum, len(rpcs))
+	for _, rpc := range rpcs[:first] {
 		wg.Add(1)
-		go func(rpc string, c *ethclient.Client) {
+		go func(rpc string) {
 			defer wg.Done()
-
-			result, compKey, err := getUsing(c)
-			if err == nil {
-				mu.Lock()
-				answers[compKey] = result
-				votes[compKey]++
-				util.Debugf("Success from %s: %#+v\n", rpc, result)
-				mu.Unlock()
-			} else {
-				util.Debugf("Problem with %s: %s\n", rpc, err.Error())
-			}
-		}(rpcs[i], client)
+			ask(rpc)
+		}(rpc)
 	}
 	wg.Wait()
 
-	for rpc, client := range connections {
-		for compKey, voteCount := range votes {
-			if voteCount >= QUORUM {
-				return answers[compKey], nil
-			}
+	for _, rpc := range rpcs[first:] {
+		if _, found := agreed(); found {
+			break
 		}
+		ask(rpc)
+	}
 
-		if tried[rpc] {
-			continue
-		}
-		tried[rpc] = true
-		result, compKey, err := getUsing(client)
-		if err == nil {
-			mu.Lock()
-			answers[compKey] = result
-			votes[compKey]++
-			util.Debugf("Success from %s: %#+v\n", rpc, result)
-			mu.Unlock()
-		} else {
-			util.Debugf("Problem with %s: %s\n", rpc, err.Error())
-		}
+	compKey, found := agreed()
+
+	reported := make(map[string]string, len(answers))
+	for rpc, answer := range answers {
+		reported[rpc] = fmt.Sprint(answer)
+	}
+	c.health.settle(call, reported, failures, fmt.Sprint(compKey), found)
+
+	if found {
+		return results[compKey], nil
 	}
 
 	var nothing R
-	return nothing, fmt.Errorf("No quorum was successful and agreed")
+	if supporting := c.rpcsSupporting(method); len(supporting) < quorum && len(supporting) < len(c.connections) {
+		return nothing, fmt.Errorf("No quorum of %d was successful and agreed on %s: %w", quorum, call, METHOD_UNSUPPORTED)
+	}
+	return nothing, fmt.Errorf("No quorum of %d was successful and agreed on %s", quorum, call)
+}
+
+// rankedRPCs returns the connected RPCs from healthiest to least healthy.
+func (c *Client) rankedRPCs() []string {
+	return c.health.ranked(slices.Collect(maps.Keys(c.connections)))
+}
+
+// rpcsSupporting returns the ranked RPCs, leaving out the ones that said they
+// don't have the method.
+func (c *Client) rpcsSupporting(method string) []string {
+	c.unsupportedMu.Lock()
+	defer c.unsupportedMu.Unlock()
+
+	return slices.DeleteFunc(c.rankedRPCs(), func(rpc string) bool {
+		return c.unsupported[rpc+" "+method]
+	})
+}
+
+func (c *Client) markUnsupported(rpc, method string) {
+	c.unsupportedMu.Lock()
+	defer c.unsupportedMu.Unlock()
+
+	c.unsupported[rpc+" "+method] = true
+}
+
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == METHOD_NOT_FOUND
 }
@@ -0,0 +1,316 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// A Checkpoint is a block trusted without verification, like one compared
+// across several explorers by hand. Fetched blocks are tied to it by the hash
+// chain of their descendants.
+type Checkpoint struct {
+	Number uint64 `mapstructure:"number"`
+	Hash   string `mapstructure:"hash"`
+}
+
+// Returned by VerifiedBlock when the RPCs answered, but with a block that
+// doesn't hash to its trusted header
+var BLOCK_MISMATCH = errors.New("block does not match its trusted header")
+
+// A BlockRef is the block a fetched receipt claims its transaction is in.
+type BlockRef struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// TrustedHeaders walks the parent hashes down from the nearest checkpoint at
+// or above each block, and returns the headers of the blocks found on that
+// chain. Blocks above every checkpoint, or not on the chain, are left out.
+// Headers can come from any RPC, since each one is checked against the hash its
+// child committed to, and are fetched by number in batches. Trusted headers are cached, as is the lowest block each
+// checkpoint was walked down to, so later walks for older blocks can start
+// there instead.
+func (c *Client) TrustedHeaders(blocks []BlockRef) (map[common.Hash]*types.Header, error) {
+	checkpoints := make([]Checkpoint, len(c.Network.Checkpoints))
+	copy(checkpoints, c.Network.Checkpoints)
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Number < checkpoints[j].Number
+	})
+
+	trusted := make(map[common.Hash]*types.Header)
+
+	// Each block only needs the walk down from the checkpoint right above it
+	wanted := make(map[int]map[uint64][]common.Hash)
+	for _, block := range blocks {
+		header, found, err := c.cachedHeader(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			trusted[block.Hash] = header
+			continue
+		}
+
+		i := sort.Search(len(checkpoints), func(i int) bool {
+			return checkpoints[i].Number >= block.Number
+		})
+		if i == len(checkpoints) {
+			continue
+		}
+		if wanted[i] == nil {
+			wanted[i] = make(map[uint64][]common.Hash)
+		}
+		wanted[i][block.Number] = append(wanted[i][block.Number], block.Hash)
+	}
+
+	for i, wantedByNumber := range wanted {
+		checkpoint := checkpoints[i]
+		lowest, highest := checkpoint.Number, uint64(0)
+		for number := range wantedByNumber {
+			lowest = min(lowest, number)
+			highest = max(highest, number)
+		}
+
+		start := BlockRef{Number: checkpoint.Number, Hash: common.HexToHash(checkpoint.Hash)}
+		ancestorKey := fmt.Sprintf("%s-%d-%s", c.Network.Name, start.Number, start.Hash.Hex())
+		var ancestor BlockRef
+		walkedBefore, err := c.ancestorCache.Read(ancestorKey, &ancestor)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading lowest block walked to from %s checkpoint %d: %w", c.Network.Name, start.Number, err)
+		}
+		// Blocks above the lowest one walked to before still need the whole walk,
+		// since only the headers that were wanted then are cached
+		if walkedBefore && highest < ancestor.Number {
+			start = ancestor
+		}
+
+		expected := start.Hash
+		var header *types.Header
+		batch := make(map[uint64]*types.Header)
+		for number := start.Number; ; number-- {
+			// Headers are fetched by number a batch at a time, and only asked
+			// for by hash when the one fetched isn't the one the chain expects
+			if _, fetched := batch[number]; !fetched {
+				from := lowest
+				if number >= lowest+BATCH_SIZE {
+					from = number - BATCH_SIZE + 1
+				}
+				batch, err = c.headersByNumber(from, number)
+				if err != nil {
+					return nil, err
+				}
+			}
+			header = batch[number]
+			if header == nil || header.Hash() != expected {
+				header, err = c.headerByHash(expected)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if header.Number.Uint64() != number {
+				return nil, fmt.Errorf("Block %s on %s is number %d, but the chain from checkpoint %d expected %d", expected, c.Network.Name, header.Number.Uint64(), checkpoint.Number, number)
+			}
+
+			for _, hash := range wantedByNumber[number] {
+				if hash != expected {
+					continue
+				}
+				trusted[hash] = header
+				err = c.headerCache.Write(fmt.Sprintf("%s-%s", c.Network.Name, hash.Hex()), header)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to cache trusted header %s: %w", hash, err)
+				}
+			}
+
+			if number == lowest || number == 0 {
+				break
+			}
+			if (start.Number-number)%10000 == 0 {
+				util.Debugf("Walked %s headers down to %d of %d\n", c.Network.Name, number, lowest)
+			}
+			expected = header.ParentHash
+		}
+
+		if !walkedBefore || header.Number.Uint64() < ancestor.Number {
+			err = c.ancestorCache.Write(ancestorKey, BlockRef{Number: header.Number.Uint64(), Hash: header.Hash()})
+			if err != nil {
+				return nil, fmt.Errorf("Unable to record lowest block walked to from %s checkpoint %d: %w", c.Network.Name, checkpoint.Number, err)
+			}
+		}
+	}
+
+	return trusted, nil
+}
+
+// cachedHeader returns a header trusted by an earlier walk, if it still hashes
+// to what it was trusted as.
+func (c *Client) cachedHeader(hash common.Hash) (*types.Header, bool, error) {
+	var header types.Header
+	found, err := c.headerCache.Read(fmt.Sprintf("%s-%s", c.Network.Name, hash.Hex()), &header)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error reading trusted header %s: %w", hash, err)
+	}
+	if !found || header.Hash() != hash {
+		return nil, false, nil
+	}
+	return &header, true, nil
+}
+
+// VerifiedBlock fetches the transactions and receipts of a trusted block, and
+// checks that they hash to the roots in its header.
+func (c *Client) VerifiedBlock(header *types.Header) (*types.Block, types.Receipts, error) {
+	err := c.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash := header.Hash()
+	problems := make([]string, 0)
+	mismatches := 0
+
+	rpcURLs := c.rankedRPCs()
+	for _, rpcURL := range rpcURLs {
+		client := c.connections[rpcURL]
+		block, err := client.BlockByHash(context.Background(), hash)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", rpcURL, err.Error()))
+			continue
+		}
+		if root := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); root != header.TxHash {
+			problems = append(problems, fmt.Sprintf("%s: transactions root %s does not match %s", rpcURL, root, header.TxHash))
+			mismatches++
+			continue
+		}
+
+		receipts, err := client.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(hash, false))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", rpcURL, err.Error()))
+			continue
+		}
+		if root := types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil)); root != header.ReceiptHash {
+			problems = append(problems, fmt.Sprintf("%s: receipts root %s does not match %s", rpcURL, root, header.ReceiptHash))
+			mismatches++
+			continue
+		}
+
+		return block, receipts, nil
+	}
+
+	// Only a block every RPC served wrong is a mismatch, anything less could
+	// just be an RPC that is down or doesn't support eth_getBlockReceipts
+	if mismatches == len(rpcURLs) {
+		return nil, nil, fmt.Errorf("Could not verify block %s on %s: %w %v", hash, c.Network.Name, BLOCK_MISMATCH, problems)
+	}
+	return nil, nil, fmt.Errorf("Could not verify block %s on %s: %v", hash, c.Network.Name, problems)
+}
+
+// VerifyTransaction checks a fetched transaction and receipt against a
+// verified block. Only the fields covered by the roots are proven, which
+// leaves out things like the gas used and effective gas price of the receipt.
+// Deposit transactions are cached rewritten, so only their hash is checked.
+func VerifyTransaction(block *types.Block, receipts types.Receipts, hash string, tx *types.Transaction, receipt *types.Receipt) error {
+	index := int(receipt.TransactionIndex)
+	if index >= len(block.Transactions()) || index >= len(receipts) {
+		return fmt.Errorf("Block %s has no transaction %d", block.Hash(), index)
+	}
+
+	blockTx := block.Transactions()[index]
+	if blockTx.Hash() != common.HexToHash(hash) {
+		return fmt.Errorf("Transaction %d of block %s is %s, not %s", index, block.Hash(), blockTx.Hash(), hash)
+	}
+	if !tx.IsDepositTx() && tx.Hash() != blockTx.Hash() {
+		return fmt.Errorf("Cached transaction %s hashes to %s", hash, tx.Hash())
+	}
+
+	cached, err := receipt.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("Unable to encode cached receipt of %s: %w", hash, err)
+	}
+	verified, err := receipts[index].MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("Unable to encode block receipt of %s: %w", hash, err)
+	}
+	if !bytes.Equal(cached, verified) {
+		return fmt.Errorf("Cached receipt of %s does not match the one in block %s", hash, block.Hash())
+	}
+
+	return nil
+}
+
+// headerByHash asks each RPC in turn, healthiest first, for a header until one returns a header
+// that actually has the hash asked for.
+func (c *Client) headerByHash(hash common.Hash) (*types.Header, error) {
+	err := c.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rpcURL := range c.rankedRPCs() {
+		client := c.connections[rpcURL]
+		header, err := client.HeaderByHash(context.Background(), hash)
+		if err != nil {
+			util.Debugf("Problem with %s: %s\n", rpcURL, err.Error())
+			continue
+		}
+		if header.Hash() != hash {
+			util.Debugf("Header from %s hashes to %s instead of %s\n", rpcURL, header.Hash(), hash)
+			continue
+		}
+		return header, nil
+	}
+
+	return nil, fmt.Errorf("No RPC for %s returned block %s with a matching hash", c.Network.Name, hash)
+}
+
+// headersByNumber asks each RPC in turn, healthiest first, for the headers of
+// a range of blocks in one batch, until one answers. They aren't checked here,
+// since the walk checks each one against the hash its child committed to, so
+// any that are missing are just left out.
+func (c *Client) headersByNumber(from, to uint64) (map[uint64]*types.Header, error) {
+	err := c.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[uint64]*types.Header)
+
+	for _, rpcURL := range c.rankedRPCs() {
+		raws := make([]json.RawMessage, to-from+1)
+		elems := make([]rpc.BatchElem, len(raws))
+		for i := range elems {
+			number := hexutil.Uint64(from + uint64(i))
+			elems[i] = rpc.BatchElem{Method: "eth_getBlockByNumber", Args: []any{number, false}, Result: &raws[i]}
+		}
+
+		client := c.connections[rpcURL]
+		err := client.Client().BatchCallContext(context.Background(), elems)
+		if err != nil {
+			util.Debugf("Problem with %s: %s\n", rpcURL, err.Error())
+			continue
+		}
+
+		for i, elem := range elems {
+			if elem.Error != nil || len(raws[i]) == 0 || string(raws[i]) == "null" {
+				continue
+			}
+			header := new(types.Header)
+			if header.UnmarshalJSON(raws[i]) == nil {
+				headers[from+uint64(i)] = header
+			}
+		}
+		return headers, nil
+	}
+
+	return headers, nil
+}
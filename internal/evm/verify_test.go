@@ -0,0 +1,261 @@
+package evm_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func headerChain(length int) []*types.Header {
+	headers := make([]*types.Header, 0, length)
+	parent := common.Hash{}
+	for i := 0; i < length; i++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(int64(i)),
+			Difficulty: big.NewInt(0),
+			GasLimit:   30_000_000,
+			Time:       uint64(1700000000 + 12*i),
+		}
+		headers = append(headers, header)
+		parent = header.Hash()
+	}
+	return headers
+}
+
+// rpcServer answers eth_chainId, eth_getBlockByHash and eth_getBlockByNumber
+// from the given headers, alone or in batches, the way a node would. Blocks
+// have no transactions, so eth_getBlockReceipts is always empty.
+func rpcServer(t *testing.T, headers []*types.Header) *httptest.Server {
+	return countingRPCServer(t, headers, make(map[string]int))
+}
+
+// countingRPCServer is an rpcServer that counts the calls of each method.
+func countingRPCServer(t *testing.T, headers []*types.Header, calls map[string]int) *httptest.Server {
+	byHash := make(map[common.Hash]*types.Header)
+	byNumber := make(map[uint64]*types.Header)
+	for _, header := range headers {
+		byHash[header.Hash()] = header
+		byNumber[header.Number.Uint64()] = header
+	}
+
+	type request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+
+	var mu sync.Mutex
+	answer := func(request request) map[string]any {
+		mu.Lock()
+		calls[request.Method]++
+		mu.Unlock()
+
+		var result any
+		switch request.Method {
+		case "eth_chainId":
+			result = "0x1"
+		case "eth_getBlockByHash":
+			var hash common.Hash
+			assert.NoError(t, json.Unmarshal(request.Params[0], &hash))
+			if header, found := byHash[hash]; found {
+				result = header
+			}
+		case "eth_getBlockReceipts":
+			result = []any{}
+		case "eth_getBlockByNumber":
+			var number hexutil.Uint64
+			assert.NoError(t, json.Unmarshal(request.Params[0], &number))
+			if header, found := byNumber[uint64(number)]; found {
+				result = header
+			}
+		}
+
+		return map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": result}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			var requests []request
+			assert.NoError(t, json.Unmarshal(body, &requests))
+			responses := make([]map[string]any, len(requests))
+			for i, request := range requests {
+				responses[i] = answer(request)
+			}
+			json.NewEncoder(w).Encode(responses)
+			return
+		}
+
+		var single request
+		assert.NoError(t, json.Unmarshal(body, &single))
+		json.NewEncoder(w).Encode(answer(single))
+	}))
+}
+
+func verifyingClient(t *testing.T, server *httptest.Server, checkpoints ...evm.Checkpoint) *evm.Client {
+	return verifyingClientWithDB(util.NewFileDB(t.TempDir()), server, checkpoints...)
+}
+
+func verifyingClientWithDB(db *util.FileDB, server *httptest.Server, checkpoints ...evm.Checkpoint) *evm.Client {
+	client := evm.NewOfflineClient(evm.Network{Name: "ethereum", ChainID: 1}, db)
+	client.Network.RPCs = []string{server.URL + "/a", server.URL + "/b"}
+	client.Network.Checkpoints = checkpoints
+	return client
+}
+
+// TrustedHeaders tests
+
+func TestTrustedHeaders(t *testing.T) {
+	headers := headerChain(6)
+	server := rpcServer(t, headers)
+	defer server.Close()
+
+	client := verifyingClient(t, server, evm.Checkpoint{Number: 4, Hash: headers[4].Hash().Hex()})
+	trusted, err := client.TrustedHeaders([]evm.BlockRef{
+		{Number: 2, Hash: headers[2].Hash()},
+		{Number: 3, Hash: headers[1].Hash()}, // Not the block on the chain at 3
+		{Number: 5, Hash: headers[5].Hash()}, // Above the checkpoint
+		{Number: 4, Hash: headers[4].Hash()},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, trusted, 2)
+	assert.Equal(t, headers[2].Hash(), trusted[headers[2].Hash()].Hash())
+	assert.Contains(t, trusted, headers[4].Hash())
+}
+
+func TestTrustedHeadersCachesWalk(t *testing.T) {
+	headers := headerChain(6)
+	checkpoint := evm.Checkpoint{Number: 5, Hash: headers[5].Hash().Hex()}
+	db := util.NewFileDB(t.TempDir())
+
+	server := rpcServer(t, headers)
+	_, err := verifyingClientWithDB(db, server, checkpoint).TrustedHeaders([]evm.BlockRef{{Number: 3, Hash: headers[3].Hash()}})
+	server.Close()
+	assert.NoError(t, err)
+
+	// Later walks only have the headers at and below the lowest block walked to
+	server = rpcServer(t, headers[:4])
+	defer server.Close()
+	client := verifyingClientWithDB(db, server, checkpoint)
+
+	trusted, err := client.TrustedHeaders([]evm.BlockRef{
+		{Number: 3, Hash: headers[3].Hash()},
+		{Number: 1, Hash: headers[1].Hash()},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, trusted, 2)
+	assert.Equal(t, headers[3].Hash(), trusted[headers[3].Hash()].Hash())
+	assert.Equal(t, headers[1].Hash(), trusted[headers[1].Hash()].Hash())
+
+	// A block above the lowest one walked to needs the walk from the checkpoint
+	_, err = client.TrustedHeaders([]evm.BlockRef{{Number: 4, Hash: headers[4].Hash()}})
+	assert.ErrorContains(t, err, "with a matching hash")
+}
+
+func TestTrustedHeadersBatchesWalk(t *testing.T) {
+	headers := headerChain(120)
+	calls := make(map[string]int)
+	server := countingRPCServer(t, headers, calls)
+	defer server.Close()
+
+	client := verifyingClient(t, server, evm.Checkpoint{Number: 119, Hash: headers[119].Hash().Hex()})
+	trusted, err := client.TrustedHeaders([]evm.BlockRef{{Number: 0, Hash: headers[0].Hash()}})
+	assert.NoError(t, err)
+	assert.Contains(t, trusted, headers[0].Hash())
+
+	// Three batches of numbers cover the walk, with no headers asked for by hash
+	assert.Equal(t, 120, calls["eth_getBlockByNumber"])
+	assert.Equal(t, 0, calls["eth_getBlockByHash"])
+}
+
+func TestTrustedHeadersRejectsForgedChain(t *testing.T) {
+	headers := headerChain(4)
+	forged := headerChain(4)
+	forged[3].ParentHash = headers[2].Hash()
+	forged[2].Extra = []byte("forged")
+
+	// The node serves the forged block 2 under the hash the real block 3
+	// commits to, which can't hash to the same value
+	server := rpcServer(t, []*types.Header{headers[3], forged[2]})
+	defer server.Close()
+
+	client := verifyingClient(t, server, evm.Checkpoint{Number: 3, Hash: headers[3].Hash().Hex()})
+	_, err := client.TrustedHeaders([]evm.BlockRef{{Number: 2, Hash: forged[2].Hash()}})
+	assert.ErrorContains(t, err, "with a matching hash")
+}
+
+// VerifiedBlock tests
+
+func TestVerifiedBlockMismatchOnlyWhenServedWrong(t *testing.T) {
+	// An empty block whose header claims it has receipts
+	trusted := headerChain(1)[0]
+	trusted.TxHash = types.EmptyTxsHash
+	trusted.UncleHash = types.EmptyUncleHash
+	trusted.ReceiptHash = common.HexToHash("0x1234")
+
+	server := rpcServer(t, []*types.Header{trusted})
+	client := verifyingClient(t, server)
+
+	_, _, err := client.VerifiedBlock(trusted)
+	assert.ErrorIs(t, err, evm.BLOCK_MISMATCH)
+
+	// RPCs that can't be reached say nothing about the block
+	server.Close()
+	_, _, err = client.VerifiedBlock(trusted)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, evm.BLOCK_MISMATCH)
+}
+
+// VerifyTransaction tests
+
+func TestVerifyTransaction(t *testing.T) {
+	txs := types.Transactions{
+		types.NewTransaction(0, alice, big.NewInt(1), 21000, big.NewInt(1), nil),
+		types.NewTransaction(1, bob, big.NewInt(2), 50000, big.NewInt(1), []byte{0x01}),
+	}
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}},
+		{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 61000, Logs: []*types.Log{
+			{Address: token, Topics: []common.Hash{addressTopic(alice)}, Data: []byte{0x02}},
+		}},
+	}
+	for _, receipt := range receipts {
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+	block := types.NewBlock(headerChain(1)[0], &types.Body{Transactions: txs}, receipts, trie.NewStackTrie(nil))
+
+	cached := *receipts[1]
+	cached.TransactionIndex = 1
+	hash := txs[1].Hash().Hex()
+
+	assert.NoError(t, evm.VerifyTransaction(block, receipts, hash, txs[1], &cached))
+
+	err := evm.VerifyTransaction(block, receipts, txs[0].Hash().Hex(), txs[0], &cached)
+	assert.ErrorContains(t, err, "Transaction 1 of block")
+
+	tampered := cached
+	tampered.Logs = []*types.Log{{Address: token, Topics: []common.Hash{addressTopic(bob)}, Data: []byte{0x02}}}
+	err = evm.VerifyTransaction(block, receipts, hash, txs[1], &tampered)
+	assert.ErrorContains(t, err, "does not match")
+
+	cached.TransactionIndex = 2
+	err = evm.VerifyTransaction(block, receipts, hash, txs[1], &cached)
+	assert.ErrorContains(t, err, "has no transaction 2")
+}
@@ -3,6 +3,7 @@ package evm_util
 import (
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -43,7 +44,19 @@ type transfer struct {
 	amount core.Amount
 }
 
+// NetTokenTransfers nets out the native and fungible token transfers of a
+// transaction. NFTs are left out, see NetTransfersWithNfts.
 func NetTokenTransfers(client *evm.Client, info *evm.TxInfo, logs []*types.Log) (NetTransfers, error) {
+	return collectNetTransfers(client, info, logs, false)
+}
+
+// NetTransfersWithNfts is NetTokenTransfers with the ERC-721 and ERC-1155
+// transfers included, which Nfts splits back off.
+func NetTransfersWithNfts(client *evm.Client, info *evm.TxInfo, logs []*types.Log) (NetTransfers, error) {
+	return collectNetTransfers(client, info, logs, true)
+}
+
+func collectNetTransfers(client *evm.Client, info *evm.TxInfo, logs []*types.Log, withNfts bool) (NetTransfers, error) {
 	transfers := make([]transfer, 0)
 	netTransfers := make(NetTransfers)
 
@@ -111,6 +124,27 @@ func NetTokenTransfers(client *evm.Client, info *evm.TxInfo, logs []*types.Log)
 		transfers = append(transfers, transfer{fromAddr, toAddr, amount})
 	}
 
+	if withNfts {
+		nftTransfers, err := evm.ParseNftTransfers(info.Network, logs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, nft := range nftTransfers {
+			asset, err := client.NftAsset(nft)
+			if err != nil {
+				return nil, err
+			}
+
+			amount, err := asset.WithAtomicStringValue(nft.Value.String())
+			if err != nil {
+				return nil, err
+			}
+
+			transfers = append(transfers, transfer{nft.From, nft.To, amount})
+		}
+	}
+
 	wrappedNativeLogs, err := evm.ParseKnownEvents(info.Network, logs, abis.WrappedNativeAbi)
 	if err != nil {
 		return nil, err
@@ -221,16 +255,98 @@ func NetTokenTransfersOnlyMine(client *evm.Client, info *evm.TxInfo, logs []*typ
 		return nil, err
 	}
 
-	for asset, transfers := range netTransfers {
+	return netTransfers.OnlyMine(), nil
+}
+
+// OnlyMine removes (in place) the transfers that are not to or from one of my
+// addresses, or that net out to zero.
+func (nt NetTransfers) OnlyMine() NetTransfers {
+	for asset, transfers := range nt {
 		for addr, amount := range transfers {
 			if !config.Config.IsMyEvmAddress(addr) || amount.Value.IsZero() {
 				delete(transfers, addr)
 			}
 		}
 		if len(transfers) == 0 {
-			delete(netTransfers, asset)
+			delete(nt, asset)
 		}
 	}
 
-	return netTransfers, nil
+	return nt
+}
+
+// Nfts splits off the NFT transfers, returning them and the remaining fungible
+// transfers as separate collections.
+func (nt NetTransfers) Nfts() (NetTransfers, NetTransfers) {
+	nfts := make(NetTransfers)
+	fungible := make(NetTransfers)
+
+	for asset, transfers := range nt {
+		if asset.IsNft() {
+			nfts[asset] = transfers
+		} else {
+			fungible[asset] = transfers
+		}
+	}
+
+	return nfts, fungible
+}
+
+// Canonical merges the transfers of fungible assets (like ETH and WETH, or
+// USDC and USDC.e) according to the asset registry, so that a wrap or a
+// migration between representations nets out. Handlers see each asset
+// separately, so this is only for callers that want the merged view. Merged
+// assets are recorded under the native asset if there is one, then under the
+// one issued directly on the network rather than bridged or wrapped.
+func (nt NetTransfers) Canonical() (NetTransfers, error) {
+	assets := make([]core.Asset, 0, len(nt))
+	for asset := range nt {
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		if representativeRank(assets[i]) != representativeRank(assets[j]) {
+			return representativeRank(assets[i]) < representativeRank(assets[j])
+		}
+		return assets[i].String() < assets[j].String()
+	})
+
+	representatives := make(map[string]core.Asset)
+	canonical := make(NetTransfers)
+
+	for _, asset := range assets {
+		representative, found := representatives[asset.CanonicalID()]
+		if !found {
+			representative = asset
+			representatives[asset.CanonicalID()] = asset
+			canonical[representative] = make(TokenTransfers)
+		}
+
+		for addr, amount := range nt[asset] {
+			existing := canonical[representative][addr]
+			if existing == nil {
+				zero := representative.WithAtomicValue(0)
+				existing = &zero
+			}
+
+			total, err := existing.Add(*amount)
+			if err != nil {
+				return nil, err
+			}
+			canonical[representative][addr] = &total
+		}
+	}
+
+	return canonical, nil
+}
+
+// representativeRank orders the assets that could stand for a merged group,
+// lowest first.
+func representativeRank(asset core.Asset) int {
+	if asset.IsNative() {
+		return 0
+	}
+	if registered, found := core.ASSET_REGISTRY.Lookup(asset); found && registered.Relation == core.NativeRelation {
+		return 1
+	}
+	return 2
 }
@@ -0,0 +1,125 @@
+package evm_util_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/evm_util"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const TEST_REGISTRY = `
+assets:
+  - id: usdc
+    symbol: USDC
+    members:
+      - network: polygon
+        identifier: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"
+      - network: polygon
+        identifier: "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"
+        relation: bridged
+  - id: eth
+    symbol: ETH
+    members:
+      - network: ethereum
+        native: true
+      - network: ethereum
+        identifier: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+        relation: wrapped
+`
+
+var ME = common.HexToAddress("0x1111111111111111111111111111111111111111")
+var BRIDGE = common.HexToAddress("0x2222222222222222222222222222222222222222")
+var WETH_ADDRESS = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+
+var eth = evm.Network{Name: evm.Ethereum, NativeAssetSymbol: "ETH"}.NativeAsset()
+var weth = evm.Network{Name: evm.Ethereum}.Erc20TokenAsset(WETH_ADDRESS.String(), "WETH", 18)
+var usdc = evm.Network{Name: evm.Polygon}.Erc20TokenAsset(common.HexToAddress("0x3c499c542cef5e3811e1192ce70d8cc03d5c3359").String(), "USDC", 6)
+var usdcE = evm.Network{Name: evm.Polygon}.Erc20TokenAsset(common.HexToAddress("0x2791bca1f2de4661ed88a30c99a7a9449aa84174").String(), "USDC.e", 6)
+var dai = evm.Network{Name: evm.Polygon}.Erc20TokenAsset(common.HexToAddress("0x8f3cf7ad23cd3cadbd9735aff958023239c6a063").String(), "DAI", 18)
+
+func useTestRegistry(t *testing.T) {
+	registry, err := core.ParseAssetRegistry([]byte(TEST_REGISTRY))
+	assert.Nil(t, err)
+
+	previous := core.ASSET_REGISTRY
+	core.ASSET_REGISTRY = registry
+	t.Cleanup(func() {
+		core.ASSET_REGISTRY = previous
+	})
+}
+
+func amount(t *testing.T, asset core.Asset, value string) *core.Amount {
+	d, err := decimal.NewFromString(value)
+	assert.Nil(t, err)
+	a := core.NewSignedAmountFromDecimal(asset, d)
+	return &a
+}
+
+// Canonical tests
+
+func TestCanonical_NetsOutWrapsAndBridges(t *testing.T) {
+	useTestRegistry(t)
+
+	netTransfers := evm_util.NetTransfers{
+		// Wrapping 1 ETH into WETH
+		eth: {
+			ME:           amount(t, eth, "-1"),
+			WETH_ADDRESS: amount(t, eth, "1"),
+		},
+		weth: {
+			ME:               amount(t, weth, "1"),
+			common.Address{}: amount(t, weth, "-1"),
+		},
+		// Migrating 100 USDC.e to USDC, and an unrelated DAI payment
+		usdcE: {
+			ME:     amount(t, usdcE, "-100"),
+			BRIDGE: amount(t, usdcE, "100"),
+		},
+		usdc: {
+			ME:     amount(t, usdc, "100"),
+			BRIDGE: amount(t, usdc, "-100"),
+		},
+		dai: {
+			ME: amount(t, dai, "5"),
+		},
+	}
+
+	canonical, err := netTransfers.Canonical()
+	assert.Nil(t, err)
+	assert.Len(t, canonical, 3)
+
+	// Merged assets are kept under the native asset, or the one that isn't
+	// bridged
+	ethGroup := canonical[eth]
+	assert.NotNil(t, ethGroup)
+	assert.True(t, ethGroup[ME].Value.IsZero())
+	assert.Equal(t, "1", ethGroup[WETH_ADDRESS].Value.String())
+	assert.Equal(t, "-1", ethGroup[common.Address{}].Value.String())
+
+	usdcGroup := canonical[usdc]
+	assert.NotNil(t, usdcGroup)
+	assert.True(t, usdcGroup[ME].Value.IsZero())
+	assert.True(t, usdcGroup[BRIDGE].Value.IsZero())
+
+	assert.Equal(t, "5", canonical[dai][ME].Value.String())
+}
+
+func TestCanonical_UnregisteredAssetsStaySeparate(t *testing.T) {
+	useTestRegistry(t)
+
+	other := evm.Network{Name: evm.Polygon}.Erc20TokenAsset(BRIDGE.String(), "USDC", 6)
+	netTransfers := evm_util.NetTransfers{
+		usdc:  {ME: amount(t, usdc, "-100")},
+		other: {ME: amount(t, other, "100")},
+	}
+
+	canonical, err := netTransfers.Canonical()
+	assert.Nil(t, err)
+	assert.Len(t, canonical, 2)
+	assert.Equal(t, "-100", canonical[usdc][ME].Value.String())
+	assert.Equal(t, "100", canonical[other][ME].Value.String())
+}
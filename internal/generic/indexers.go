@@ -5,7 +5,10 @@ import (
 	"log"
 
 	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cosmos"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/solana"
+	"github.com/ksmithbaylor/gohodl/internal/utxo"
 )
 
 type AllIndexers map[string]core.Indexer
@@ -32,6 +35,12 @@ func GetIndexerForNetwork(network core.Network) (core.Indexer, error) {
 	switch network.GetKind() {
 	case core.EvmNetworkKind:
 		return evm.NewIndexer(network.(evm.Network))
+	case core.UtxoNetworkKind:
+		return utxo.NewIndexer(network.(utxo.Network))
+	case core.SolanaNetworkKind:
+		return solana.NewIndexer(network.(solana.Network))
+	case core.CosmosNetworkKind:
+		return cosmos.NewIndexer(network.(cosmos.Network))
 	default:
 		return nil, fmt.Errorf("No indexer implemented for %s", network.GetKind())
 	}
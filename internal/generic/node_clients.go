@@ -6,12 +6,16 @@ import (
 	"sync"
 
 	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cosmos"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/solana"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/ksmithbaylor/gohodl/internal/utxo"
 )
 
 type AllNodeClients map[string]core.NodeClient
 
-func NewAllNodeClients(networks []core.Network) AllNodeClients {
+func NewAllNodeClients(networks []core.Network, db *util.FileDB) AllNodeClients {
 	clients := make(map[string]core.NodeClient)
 
 	errs := make([]error, 0)
@@ -19,7 +23,7 @@ func NewAllNodeClients(networks []core.Network) AllNodeClients {
 	fmt.Printf("Getting node clients for %d networks...\n", len(networks))
 
 	for _, network := range networks {
-		client, err := GetNodeClientForNetwork(network)
+		client, err := GetNodeClientForNetwork(network, db)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("Failed to make node client for %s: %w", network.GetName(), err))
 		}
@@ -39,10 +43,18 @@ func NewAllNodeClients(networks []core.Network) AllNodeClients {
 	return AllNodeClients(clients)
 }
 
-func GetNodeClientForNetwork(network core.Network) (core.NodeClient, error) {
+// GetNodeClientForNetwork returns the client for a network, keeping anything
+// it caches in the given database.
+func GetNodeClientForNetwork(network core.Network, db *util.FileDB) (core.NodeClient, error) {
 	switch network.GetKind() {
 	case core.EvmNetworkKind:
-		return evm.NewClient(network.(evm.Network))
+		return evm.NewClient(network.(evm.Network), db)
+	case core.UtxoNetworkKind:
+		return utxo.NewClient(network.(utxo.Network))
+	case core.SolanaNetworkKind:
+		return solana.NewClient(network.(solana.Network))
+	case core.CosmosNetworkKind:
+		return cosmos.NewClient(network.(cosmos.Network))
 	default:
 		return nil, fmt.Errorf("No node client implemented for %s", network.GetKind())
 	}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+)
+
+// The transaction is shaped differently than its handler expects, like an
+// extra net transfer or an unknown event
+var ErrUnexpected = errors.New("Unexpected transaction")
+
+// A HandlerError is a transaction that couldn't be exported, and why. The
+// export skips it and carries on, so everything that failed can be reported at
+// once.
+type HandlerError struct {
+	Network string
+	Hash    string
+	Handler string // Name of the matcher that chose the handler
+	Reason  string
+	Panic   bool  // Whether the handler panicked rather than returning an error
+	Err     error // The underlying error, if any
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("%s transaction %s (%s): %s", e.Network, e.Hash, e.Handler, e.Reason)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// Unexpected is the error for a transaction that doesn't look like its handler
+// expects. The network, hash and handler are filled in by the dispatcher.
+func Unexpected(reason string) error {
+	return &HandlerError{
+		Reason: reason,
+		Err:    ErrUnexpected,
+	}
+}
+
+// AsHandlerError adds the details of the transaction and handler to any error
+// returned while handling it.
+func AsHandlerError(err error, info *evm.TxInfo, handler string) *HandlerError {
+	var handlerErr *HandlerError
+	if !errors.As(err, &handlerErr) {
+		handlerErr = &HandlerError{
+			Reason: err.Error(),
+			Err:    err,
+		}
+	}
+
+	if handlerErr.Network == "" {
+		handlerErr.Network = info.Network
+	}
+	if handlerErr.Hash == "" {
+		handlerErr.Hash = info.Hash
+	}
+	if handlerErr.Handler == "" {
+		handlerErr.Handler = handler
+	}
+
+	return handlerErr
+}
+
+// Recovered is the error for a handler that panicked.
+func Recovered(recovered any, info *evm.TxInfo, handler string) *HandlerError {
+	err, _ := recovered.(error)
+	return &HandlerError{
+		Network: info.Network,
+		Hash:    info.Hash,
+		Handler: handler,
+		Reason:  fmt.Sprint(recovered),
+		Panic:   true,
+		Err:     err,
+	}
+}
@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+// HandlerError tests
+
+func TestUnexpected(t *testing.T) {
+	info := tx("base", "0x1234", "0xa9059cbb", JAN_2024)
+	info.Hash = "0xabcd"
+
+	err := handlers.AsHandlerError(handlers.Unexpected("Multiple assets sold for swap"), info, "uniswap swap")
+	assert.ErrorIs(t, err, handlers.ErrUnexpected)
+	assert.Equal(t, "base", err.Network)
+	assert.Equal(t, "0xabcd", err.Hash)
+	assert.Equal(t, "uniswap swap", err.Handler)
+	assert.Equal(t, "base transaction 0xabcd (uniswap swap): Multiple assets sold for swap", err.Error())
+}
+
+func TestAsHandlerErrorWrapsOtherErrors(t *testing.T) {
+	info := tx("base", "0x1234", "0xa9059cbb", JAN_2024)
+	cause := errors.New("Could not get decimals")
+
+	err := handlers.AsHandlerError(fmt.Errorf("Token asset: %w", cause), info, "erc20 transfer")
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "Token asset: Could not get decimals", err.Reason)
+	assert.False(t, err.Panic)
+}
+
+func TestRecovered(t *testing.T) {
+	info := tx("base", "0x1234", "0xa9059cbb", JAN_2024)
+
+	err := handlers.Recovered("fee tx but did not pay a fee", info, "no data")
+	assert.True(t, err.Panic)
+	assert.Equal(t, "fee tx but did not pay a fee", err.Reason)
+	assert.Nil(t, err.Err)
+}
@@ -0,0 +1,185 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/nanmu42/etherscan-api"
+)
+
+// A Fixture is everything a handler needs to export one transaction, so it can
+// be replayed without any network access. Fixtures are JSON files, next to a
+// golden CSV of the rows the handler should export.
+type Fixture struct {
+	Info        evm.TxInfo               `json:"info"`
+	Network     evm.Network              `json:"network"` // Without RPCs or explorer keys
+	Mine        []common.Address         `json:"mine"`    // My addresses involved in the transaction
+	Tx          *types.Transaction       `json:"tx"`
+	Receipt     *types.Receipt           `json:"receipt"`
+	Block       *types.Header            `json:"block"`
+	InternalTxs []etherscan.InternalTx   `json:"internal_txs"`
+	Tokens      map[common.Address]Token `json:"tokens"` // Every contract that emitted a log
+}
+
+type Token struct {
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// New snapshots a transaction into a fixture. The token metadata and internal
+// transactions come from the client, which normally has them cached already.
+func New(
+	info evm.TxInfo,
+	client *evm.Client,
+	tx *types.Transaction,
+	receipt *types.Receipt,
+	block *types.Header,
+) (*Fixture, error) {
+	network := client.Network
+	network.RPCs = nil
+	network.Etherscan.Key = ""
+
+	fixture := &Fixture{
+		Info:    info,
+		Network: network,
+		Mine:    make([]common.Address, 0),
+		Tx:      tx,
+		Receipt: receipt,
+		Block:   block,
+		Tokens:  make(map[common.Address]Token),
+	}
+
+	internalTxs, _, err := client.GetInternalTransactions(info.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("Could not get internal transactions: %w", err)
+	}
+	fixture.InternalTxs = internalTxs
+
+	// Anything that looks like an address could be one of mine, including log
+	// topics and calldata arguments
+	candidates := []string{info.From, info.To}
+	for _, internalTx := range internalTxs {
+		candidates = append(candidates, internalTx.From, internalTx.To)
+	}
+	for _, log := range receipt.Logs {
+		for _, topic := range log.Topics {
+			candidates = append(candidates, common.BytesToAddress(topic.Bytes()).Hex())
+		}
+		for i := 0; i+32 <= len(log.Data); i += 32 {
+			candidates = append(candidates, common.BytesToAddress(log.Data[i:i+32]).Hex())
+		}
+
+		if _, found := fixture.Tokens[log.Address]; found {
+			continue
+		}
+		symbol, err := client.TokenSymbol(log.Address)
+		if err != nil {
+			continue // Not a token, or no symbol
+		}
+		decimals, err := client.Erc20Decimals(log.Address)
+		if err != nil {
+			decimals = 0 // NFTs usually have no decimals
+		}
+		fixture.Tokens[log.Address] = Token{Symbol: symbol, Decimals: decimals}
+	}
+	for i := 4; i+32 <= len(tx.Data()); i += 32 {
+		candidates = append(candidates, common.BytesToAddress(tx.Data()[i:i+32]).Hex())
+	}
+
+	for _, candidate := range util.UniqueItems(candidates) {
+		address := common.HexToAddress(candidate)
+		if config.Config.IsMyEvmAddress(address) {
+			fixture.Mine = append(fixture.Mine, address)
+		}
+	}
+
+	return fixture, nil
+}
+
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read fixture %s: %w", path, err)
+	}
+
+	var fixture Fixture
+	err = json.Unmarshal(data, &fixture)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid fixture %s: %w", path, err)
+	}
+
+	return &fixture, nil
+}
+
+// Glob returns the paths of every fixture in a directory.
+func Glob(dir string) ([]string, error) {
+	return filepath.Glob(filepath.Join(dir, "*.json"))
+}
+
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Could not marshal fixture: %w", err)
+	}
+
+	err = os.WriteFile(path, append(data, '\n'), util.FILEDB_FILE_PERMS)
+	if err != nil {
+		return fmt.Errorf("Could not write fixture %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Name is the file name fixtures of the transaction are saved under.
+func (f *Fixture) Name() string {
+	return fmt.Sprintf("%s-%s", f.Info.Network, strings.ToLower(f.Info.Hash))
+}
+
+// Run replays the transaction through a handler, with a client that only
+// knows what the fixture does. Caches are kept in the given directory, which
+// should be empty. Config is changed to only have the fixture's network and
+// addresses, so fixtures can't be run in parallel.
+func (f *Fixture) Run(handler handlers.TransactionHander, cacheDir string) ([]ctc_util.CTCTransaction, bool, error) {
+	config.Config.EvmNetworks = []evm.Network{f.Network}
+	config.Config.Ownership.Ethereum.Addresses = make(map[string]common.Address)
+	for i, address := range f.Mine {
+		config.Config.Ownership.Ethereum.Addresses[fmt.Sprintf("fixture-%d", i)] = address
+	}
+
+	client := evm.NewOfflineClient(f.Network, util.NewFileDB(cacheDir))
+	for address, token := range f.Tokens {
+		client.CacheToken(address, token.Symbol, token.Decimals)
+	}
+	err := client.CacheInternalTransactions(f.Info.Hash, f.InternalTxs)
+	if err != nil {
+		return nil, false, err
+	}
+
+	readTransaction := func(network, hash string) (*types.Transaction, *types.Receipt, *types.Header, error) {
+		if network != f.Info.Network || !strings.EqualFold(hash, f.Info.Hash) {
+			return nil, nil, nil, fmt.Errorf("Fixture has no %s transaction %s", network, hash)
+		}
+		return f.Tx, f.Receipt, f.Block, nil
+	}
+
+	rows := make([]ctc_util.CTCTransaction, 0)
+	export := func(exported ...ctc_util.CTCTransaction) error {
+		rows = append(rows, exported...)
+		return nil
+	}
+
+	info := f.Info
+	handled, err := handler.HandleTransaction(&info, client, readTransaction, export)
+
+	return rows, handled, err
+}
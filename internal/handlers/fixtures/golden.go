@@ -0,0 +1,52 @@
+package fixtures
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// GoldenPath is the path of the golden CSV for the fixture at the given path.
+func GoldenPath(fixturePath string) string {
+	return strings.TrimSuffix(fixturePath, ".json") + ".csv"
+}
+
+// FormatGolden writes the result of running a fixture as a CTC CSV, with
+// whether it was handled and any error in comments at the top.
+func FormatGolden(rows []ctc_util.CTCTransaction, handled bool, err error) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# handled: %t\n", handled)
+	if err != nil {
+		fmt.Fprintf(&buf, "# error: %s\n", strings.ReplaceAll(err.Error(), "\n", " "))
+	}
+
+	err = ctc_util.WriteCSV(&buf, rows)
+	if err != nil {
+		fmt.Fprintf(&buf, "# invalid: %s\n", err.Error())
+	}
+
+	return buf.Bytes()
+}
+
+// ReadGolden reads the golden CSV of a fixture.
+func ReadGolden(path string) ([]byte, error) {
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read golden file %s (run with -update to create it): %w", path, err)
+	}
+	return golden, nil
+}
+
+// WriteGolden replaces the golden CSV of a fixture.
+func WriteGolden(path string, golden []byte) error {
+	err := os.WriteFile(path, golden, util.FILEDB_FILE_PERMS)
+	if err != nil {
+		return fmt.Errorf("Could not update golden file %s: %w", path, err)
+	}
+	return nil
+}
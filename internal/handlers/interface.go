@@ -4,18 +4,21 @@ import (
 	"errors"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
 )
 
 // This module is meant to contain code specific to each user's transactions. It
 // should export the functions below, and is used by the `ctc` module in the
-// export step to translate the raw transaction data to the CSV format accepted
-// by CTC. To customize this for your own usage, simply implement the interface
+// export step to translate the raw transaction data to the transactions
+// accepted by CTC. To customize this for your own usage, simply implement the interface
 // in a new nested module and use that instead of the `kevin` one. Mine can be
 // used as an example. Private constants and other values are in private.go,
 // which is protected by git-crypt for my own personal implementation.
 
-type CTCWriter func(...[]string) error
+// Handlers export structured transactions, which are only serialized once the
+// whole export has been checked and sorted
+type CTCWriter func(...ctc_util.CTCTransaction) error
 type TransactionReader func(network, hash string) (
 	*types.Transaction,
 	*types.Receipt,
@@ -39,6 +42,12 @@ type TransactionHander interface {
 	) (bool, error)
 }
 
+// A TransactionHandler can also implement this to say which of its handlers
+// would apply to a transaction, and why
+type HandlerExplainer interface {
+	Explain(info *evm.TxInfo) []Explanation
+}
+
 // The below code allows the program to compile before implementing the real
 // handling logic
 
@@ -29,7 +29,7 @@ func handleAaveSupply(bundle handlers.TransactionBundle, client *evm.Client, exp
 	}
 
 	if len(netTransfers) != 2 {
-		panic("More than 2 net transfers for aave supply")
+		return handlers.Unexpected("More than 2 net transfers for aave supply")
 	}
 
 	var deposited core.Amount
@@ -37,18 +37,18 @@ func handleAaveSupply(bundle handlers.TransactionBundle, client *evm.Client, exp
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("More than 1 transfer for an asset for aave supply")
+			return handlers.Unexpected("More than 1 transfer for an asset for aave supply")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Transfer to/from the wrong address for aave supply")
+				return handlers.Unexpected("Transfer to/from the wrong address for aave supply")
 			}
 			if amount.Value.IsNegative() {
 				deposited = amount.Neg()
 			} else if amount.Value.IsPositive() {
 				received = *amount
 			} else {
-				panic("Zero-value transfers for aave supply")
+				return handlers.Unexpected("Zero-value transfers for aave supply")
 			}
 		}
 	}
@@ -66,18 +66,18 @@ func handleAaveSupply(bundle handlers.TransactionBundle, client *evm.Client, exp
 	}
 
 	if supplyEvent.Name == "" {
-		panic("No supply event for aave supply")
+		return handlers.Unexpected("No supply event for aave supply")
 	}
 
 	suppliedTokenAddress := supplyEvent.Data["reserve"].(common.Address).Hex()
 	isWrappedNative := slices.Contains(WRAPPED_NATIVE_CONTRACTS, fmt.Sprintf("%s-%s", bundle.Info.Network, suppliedTokenAddress))
 
 	if suppliedTokenAddress != deposited.Asset.Identifier && !isWrappedNative {
-		panic("Different asset supplied than token movements would suggest for aave supply")
+		return handlers.Unexpected("Different asset supplied than token movements would suggest for aave supply")
 	}
 
 	if supplyEvent.Data["amount"].(*big.Int).String() != deposited.Value.Shift(int32(deposited.Asset.Decimals)).String() {
-		panic("Different amount supplied than token movements would suggest for aave supply")
+		return handlers.Unexpected("Different amount supplied than token movements would suggest for aave supply")
 	}
 
 	to := "aave"
@@ -92,6 +92,7 @@ func handleAaveSupply(bundle handlers.TransactionBundle, client *evm.Client, exp
 		Type:         ctc_util.CTCCollateralDeposit,
 		BaseCurrency: deposited.Asset.Symbol,
 		BaseAmount:   deposited.Value,
+		BaseAsset:    deposited.Asset,
 		From:         bundle.Info.From,
 		To:           to,
 		Description: fmt.Sprintf("%s: supply %s, receive receipt token (%s)",
@@ -100,9 +101,11 @@ func handleAaveSupply(bundle handlers.TransactionBundle, client *evm.Client, exp
 			received,
 		),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleAaveBorrow(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -112,7 +115,7 @@ func handleAaveBorrow(bundle handlers.TransactionBundle, client *evm.Client, exp
 	}
 
 	if len(netTransfers) != 2 {
-		panic("More than 2 net transfers for aave borrow")
+		return handlers.Unexpected("More than 2 net transfers for aave borrow")
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.AaveAbi)
@@ -128,7 +131,7 @@ func handleAaveBorrow(bundle handlers.TransactionBundle, client *evm.Client, exp
 	}
 
 	if borrowEvent.Name == "" {
-		panic("No borrow event for aave borrow")
+		return handlers.Unexpected("No borrow event for aave borrow")
 	}
 
 	borrowedTokenAddress := borrowEvent.Data["reserve"].(common.Address).Hex()
@@ -138,11 +141,11 @@ func handleAaveBorrow(bundle handlers.TransactionBundle, client *evm.Client, exp
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("More than 1 transfer for an asset for aave borrow")
+			return handlers.Unexpected("More than 1 transfer for an asset for aave borrow")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Transfer to/from the wrong address for aave borrow")
+				return handlers.Unexpected("Transfer to/from the wrong address for aave borrow")
 			}
 			if amount.Asset.Identifier == borrowedTokenAddress {
 				borrowed = *amount
@@ -151,7 +154,7 @@ func handleAaveBorrow(bundle handlers.TransactionBundle, client *evm.Client, exp
 	}
 
 	if borrowedAmount != borrowed.Value.Shift(int32(borrowed.Asset.Decimals)).String() {
-		panic("Different amount borrowed vs received for aave borrow")
+		return handlers.Unexpected("Different amount borrowed vs received for aave borrow")
 	}
 
 	from := "aave"
@@ -166,13 +169,16 @@ func handleAaveBorrow(bundle handlers.TransactionBundle, client *evm.Client, exp
 		Type:         ctc_util.CTCBorrow,
 		BaseCurrency: borrowed.Asset.Symbol,
 		BaseAmount:   borrowed.Value,
+		BaseAsset:    borrowed.Asset,
 		From:         from,
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("%s: borrow %s", from, borrowed),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleAaveRepay(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -182,7 +188,7 @@ func handleAaveRepay(bundle handlers.TransactionBundle, client *evm.Client, expo
 	}
 
 	if len(netTransfers) != 2 {
-		panic("More than 2 net transfers for aave repay")
+		return handlers.Unexpected("More than 2 net transfers for aave repay")
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.AaveAbi)
@@ -198,7 +204,7 @@ func handleAaveRepay(bundle handlers.TransactionBundle, client *evm.Client, expo
 	}
 
 	if repayEvent.Name == "" {
-		panic("No repay event for aave repay")
+		return handlers.Unexpected("No repay event for aave repay")
 	}
 
 	repaidTokenAddress := repayEvent.Data["reserve"].(common.Address).Hex()
@@ -207,11 +213,11 @@ func handleAaveRepay(bundle handlers.TransactionBundle, client *evm.Client, expo
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("More than 1 transfer for an asset for aave repay")
+			return handlers.Unexpected("More than 1 transfer for an asset for aave repay")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Transfer to/from the wrong address for aave repay")
+				return handlers.Unexpected("Transfer to/from the wrong address for aave repay")
 			}
 			if amount.Asset.Identifier == repaidTokenAddress {
 				repaid = amount.Neg()
@@ -231,13 +237,16 @@ func handleAaveRepay(bundle handlers.TransactionBundle, client *evm.Client, expo
 		Type:         ctc_util.CTCLoanRepayment,
 		BaseCurrency: repaid.Asset.Symbol,
 		BaseAmount:   repaid.Value,
+		BaseAsset:    repaid.Asset,
 		From:         bundle.Info.From,
 		To:           to,
 		Description:  fmt.Sprintf("%s: repay %s", to, repaid),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleAaveRepayWithATokens(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -247,7 +256,7 @@ func handleAaveRepayWithATokens(bundle handlers.TransactionBundle, client *evm.C
 	}
 
 	if len(netTransfers) != 2 {
-		panic("More than 2 net transfers for aave repay with atokens")
+		return handlers.Unexpected("More than 2 net transfers for aave repay with atokens")
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.AaveAbi)
@@ -263,7 +272,7 @@ func handleAaveRepayWithATokens(bundle handlers.TransactionBundle, client *evm.C
 	}
 
 	if repayEvent.Name == "" {
-		panic("No repay event for aave repay with atokens")
+		return handlers.Unexpected("No repay event for aave repay with atokens")
 	}
 
 	repaidTokenAddress := repayEvent.Data["reserve"].(common.Address)
@@ -291,13 +300,16 @@ func handleAaveRepayWithATokens(bundle handlers.TransactionBundle, client *evm.C
 		Type:         ctc_util.CTCLoanRepayment,
 		BaseCurrency: repaid.Asset.Symbol,
 		BaseAmount:   repaid.Value,
+		BaseAsset:    repaid.Asset,
 		From:         bundle.Info.From,
 		To:           to,
 		Description:  fmt.Sprintf("%s: repay %s", to, repaid),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleAaveDeposit(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -307,7 +319,7 @@ func handleAaveDeposit(bundle handlers.TransactionBundle, client *evm.Client, ex
 	}
 
 	if len(netTransfers) != 2 {
-		panic("More than 2 net transfers for aave deposit")
+		return handlers.Unexpected("More than 2 net transfers for aave deposit")
 	}
 
 	var deposited core.Amount
@@ -315,24 +327,24 @@ func handleAaveDeposit(bundle handlers.TransactionBundle, client *evm.Client, ex
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("More than 1 transfer for an asset for aave deposit")
+			return handlers.Unexpected("More than 1 transfer for an asset for aave deposit")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Transfer to/from the wrong address for aave deposit")
+				return handlers.Unexpected("Transfer to/from the wrong address for aave deposit")
 			}
 			if amount.Value.IsNegative() {
 				deposited = amount.Neg()
 			} else if amount.Value.IsPositive() {
 				received = *amount
 			} else {
-				panic("Zero-value transfers for aave deposit")
+				return handlers.Unexpected("Zero-value transfers for aave deposit")
 			}
 		}
 	}
 
 	if !deposited.Value.Equal(received.Value) {
-		panic("Different amount deposited vs received for aave deposit")
+		return handlers.Unexpected("Different amount deposited vs received for aave deposit")
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.AaveAbi)
@@ -348,15 +360,15 @@ func handleAaveDeposit(bundle handlers.TransactionBundle, client *evm.Client, ex
 	}
 
 	if depositEvent.Name == "" {
-		panic("No deposit event for aave deposit")
+		return handlers.Unexpected("No deposit event for aave deposit")
 	}
 
 	if depositEvent.Data["reserve"].(common.Address).Hex() != deposited.Asset.Identifier {
-		panic("Different asset deposited than token movements would suggest for aave deposit")
+		return handlers.Unexpected("Different asset deposited than token movements would suggest for aave deposit")
 	}
 
 	if depositEvent.Data["amount"].(*big.Int).String() != deposited.Value.Shift(int32(deposited.Asset.Decimals)).String() {
-		panic("Different amount deposited than token movements would suggest for aave deposit")
+		return handlers.Unexpected("Different amount deposited than token movements would suggest for aave deposit")
 	}
 
 	to := "aave"
@@ -371,6 +383,7 @@ func handleAaveDeposit(bundle handlers.TransactionBundle, client *evm.Client, ex
 		Type:         ctc_util.CTCCollateralDeposit,
 		BaseCurrency: deposited.Asset.Symbol,
 		BaseAmount:   deposited.Value,
+		BaseAsset:    deposited.Asset,
 		From:         bundle.Info.From,
 		To:           to,
 		Description: fmt.Sprintf("%s: deposit %s, receive receipt token (%s)",
@@ -379,9 +392,11 @@ func handleAaveDeposit(bundle handlers.TransactionBundle, client *evm.Client, ex
 			received,
 		),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleAaveWithdraw(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -391,18 +406,18 @@ func handleAaveWithdraw(bundle handlers.TransactionBundle, client *evm.Client, e
 	}
 
 	if len(netTransfers) != 2 {
-		panic("More than 2 net transfers for aave withdrawal")
+		return handlers.Unexpected("More than 2 net transfers for aave withdrawal")
 	}
 
 	var withdrawn core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("More than 1 transfer for an asset for aave withdrawal")
+			return handlers.Unexpected("More than 1 transfer for an asset for aave withdrawal")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Transfer to/from the wrong address for aave withdrawal")
+				return handlers.Unexpected("Transfer to/from the wrong address for aave withdrawal")
 			}
 			if amount.Value.IsPositive() {
 				withdrawn = *amount
@@ -423,18 +438,18 @@ func handleAaveWithdraw(bundle handlers.TransactionBundle, client *evm.Client, e
 	}
 
 	if withdrawEvent.Name == "" {
-		panic("No withdraw event for aave withdrawal")
+		return handlers.Unexpected("No withdraw event for aave withdrawal")
 	}
 
 	withdrawnTokenAddress := withdrawEvent.Data["reserve"].(common.Address).Hex()
 	isWrappedNative := slices.Contains(WRAPPED_NATIVE_CONTRACTS, fmt.Sprintf("%s-%s", bundle.Info.Network, withdrawnTokenAddress))
 
 	if withdrawnTokenAddress != withdrawn.Asset.Identifier && !isWrappedNative {
-		panic("Different asset withdrawn than token movements would suggest for aave withdrawal")
+		return handlers.Unexpected("Different asset withdrawn than token movements would suggest for aave withdrawal")
 	}
 
 	if withdrawEvent.Data["amount"].(*big.Int).String() != withdrawn.Value.Shift(int32(withdrawn.Asset.Decimals)).String() {
-		panic("Different amount withdrawn than token movements would suggest for aave withdrawal")
+		return handlers.Unexpected("Different amount withdrawn than token movements would suggest for aave withdrawal")
 	}
 
 	from := "aave"
@@ -449,17 +464,20 @@ func handleAaveWithdraw(bundle handlers.TransactionBundle, client *evm.Client, e
 		Type:         ctc_util.CTCCollateralWithdrawal,
 		BaseCurrency: withdrawn.Asset.Symbol,
 		BaseAmount:   withdrawn.Value,
+		BaseAsset:    withdrawn.Asset,
 		From:         from,
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("%s: withdraw %s", from, withdrawn),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleAaveSetUserEMode(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash,
@@ -467,7 +485,10 @@ func handleAaveSetUserEMode(bundle handlers.TransactionBundle, client *evm.Clien
 		"aave: set user e-mode",
 		bundle.Receipt,
 	)
-	return export(ctcTx.ToCSV())
+	if err != nil {
+		return err
+	}
+	return export(*ctcTx)
 }
 
 func handleAaveClaimRewards(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -490,28 +511,28 @@ func handleAaveClaimRewards(bundle handlers.TransactionBundle, client *evm.Clien
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for aave claim rewards")
+		return handlers.Unexpected("Unexpected net transfers for aave claim rewards")
 	}
 
 	var claimed core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for aave claim rewards")
+			return handlers.Unexpected("Unexpected net transfers for aave claim rewards")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for aave claim rewards")
+				return handlers.Unexpected("Unexpected net transfers for aave claim rewards")
 			}
 			if !amount.Value.IsPositive() {
-				panic("Outflow for aave claim rewards")
+				return handlers.Unexpected("Outflow for aave claim rewards")
 			}
 			claimed = *amount
 		}
 	}
 
 	if claimed.Asset.Symbol == "" {
-		panic("Nothing claimed for aave claim rewards")
+		return handlers.Unexpected("Nothing claimed for aave claim rewards")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -521,11 +542,14 @@ func handleAaveClaimRewards(bundle handlers.TransactionBundle, client *evm.Clien
 		Type:         ctc_util.CTCInterest,
 		BaseCurrency: claimed.Asset.Symbol,
 		BaseAmount:   claimed.Value,
+		BaseAsset:    claimed.Asset,
 		From:         "unknown",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("%s: claim %s in rewards", from, claimed),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
@@ -47,18 +47,18 @@ func handleBenqi(bundle handlers.TransactionBundle, client *evm.Client, export h
 
 func handleBenqiMint(bundle handlers.TransactionBundle, _ *evm.Client, export handlers.CTCWriter, netTransfers evm_util.NetTransfers) error {
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for benqi mint")
+		return handlers.Unexpected("Unexpected net transfers for benqi mint")
 	}
 
 	var deposited core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for benqi mint")
+			return handlers.Unexpected("Unexpected net transfers for benqi mint")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for benqi mint")
+				return handlers.Unexpected("Unexpected net transfers for benqi mint")
 			}
 			if amount.Value.IsNegative() {
 				deposited = amount.Neg()
@@ -67,7 +67,7 @@ func handleBenqiMint(bundle handlers.TransactionBundle, _ *evm.Client, export ha
 	}
 
 	if deposited.Asset.Symbol == "" {
-		panic("No asset deposited for benqi mint")
+		return handlers.Unexpected("No asset deposited for benqi mint")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -77,29 +77,32 @@ func handleBenqiMint(bundle handlers.TransactionBundle, _ *evm.Client, export ha
 		Type:         ctc_util.CTCCollateralDeposit,
 		BaseCurrency: deposited.Asset.Symbol,
 		BaseAmount:   deposited.Value,
+		BaseAsset:    deposited.Asset,
 		From:         bundle.Info.From,
 		To:           "benqi",
 		Description:  fmt.Sprintf("benqi: supply %s", deposited),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleBenqiBorrow(bundle handlers.TransactionBundle, _ *evm.Client, export handlers.CTCWriter, netTransfers evm_util.NetTransfers) error {
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for benqi borrow")
+		return handlers.Unexpected("Unexpected net transfers for benqi borrow")
 	}
 
 	var borrowed core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for benqi borrow")
+			return handlers.Unexpected("Unexpected net transfers for benqi borrow")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for benqi borrow")
+				return handlers.Unexpected("Unexpected net transfers for benqi borrow")
 			}
 			if amount.Value.IsPositive() {
 				borrowed = *amount
@@ -108,7 +111,7 @@ func handleBenqiBorrow(bundle handlers.TransactionBundle, _ *evm.Client, export
 	}
 
 	if borrowed.Asset.Symbol == "" {
-		panic("No asset deposited for benqi mint")
+		return handlers.Unexpected("No asset deposited for benqi mint")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -118,29 +121,32 @@ func handleBenqiBorrow(bundle handlers.TransactionBundle, _ *evm.Client, export
 		Type:         ctc_util.CTCBorrow,
 		BaseCurrency: borrowed.Asset.Symbol,
 		BaseAmount:   borrowed.Value,
+		BaseAsset:    borrowed.Asset,
 		From:         "benqi",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("benqi: borrow %s", borrowed),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleBenqiRepay(bundle handlers.TransactionBundle, _ *evm.Client, export handlers.CTCWriter, netTransfers evm_util.NetTransfers) error {
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for benqi borrow")
+		return handlers.Unexpected("Unexpected net transfers for benqi borrow")
 	}
 
 	var repaid core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for benqi repay")
+			return handlers.Unexpected("Unexpected net transfers for benqi repay")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for benqi repay")
+				return handlers.Unexpected("Unexpected net transfers for benqi repay")
 			}
 			if amount.Value.IsNegative() {
 				repaid = amount.Neg()
@@ -149,7 +155,7 @@ func handleBenqiRepay(bundle handlers.TransactionBundle, _ *evm.Client, export h
 	}
 
 	if repaid.Asset.Symbol == "" {
-		panic("No asset sent for benqi repay")
+		return handlers.Unexpected("No asset sent for benqi repay")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -159,29 +165,32 @@ func handleBenqiRepay(bundle handlers.TransactionBundle, _ *evm.Client, export h
 		Type:         ctc_util.CTCLoanRepayment,
 		BaseCurrency: repaid.Asset.Symbol,
 		BaseAmount:   repaid.Value,
+		BaseAsset:    repaid.Asset,
 		From:         bundle.Info.From,
 		To:           "benqi",
 		Description:  fmt.Sprintf("benqi: repay %s", repaid),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleBenqiRedeem(bundle handlers.TransactionBundle, _ *evm.Client, export handlers.CTCWriter, netTransfers evm_util.NetTransfers) error {
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for benqi redeem")
+		return handlers.Unexpected("Unexpected net transfers for benqi redeem")
 	}
 
 	var withdrawn core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for benqi redeem")
+			return handlers.Unexpected("Unexpected net transfers for benqi redeem")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for benqi redeem")
+				return handlers.Unexpected("Unexpected net transfers for benqi redeem")
 			}
 			if amount.Value.IsPositive() {
 				withdrawn = *amount
@@ -190,7 +199,7 @@ func handleBenqiRedeem(bundle handlers.TransactionBundle, _ *evm.Client, export
 	}
 
 	if withdrawn.Asset.Symbol == "" {
-		panic("No asset withdrawn for benqi redeem")
+		return handlers.Unexpected("No asset withdrawn for benqi redeem")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -200,39 +209,42 @@ func handleBenqiRedeem(bundle handlers.TransactionBundle, _ *evm.Client, export
 		Type:         ctc_util.CTCCollateralWithdrawal,
 		BaseCurrency: withdrawn.Asset.Symbol,
 		BaseAmount:   withdrawn.Value,
+		BaseAsset:    withdrawn.Asset,
 		From:         "benqi",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("benqi: withdraw %s", withdrawn),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleBenqiClaimRewards(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter, netTransfers evm_util.NetTransfers) error {
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for benqi claim rewards")
+		return handlers.Unexpected("Unexpected net transfers for benqi claim rewards")
 	}
 
 	var claimed core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for benqi claim rewards")
+			return handlers.Unexpected("Unexpected net transfers for benqi claim rewards")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for benqi claim rewards")
+				return handlers.Unexpected("Unexpected net transfers for benqi claim rewards")
 			}
 			if !amount.Value.IsPositive() {
-				panic("Outflow for benqi claim rewards")
+				return handlers.Unexpected("Outflow for benqi claim rewards")
 			}
 			claimed = *amount
 		}
 	}
 
 	if claimed.Asset.Symbol == "" {
-		panic("Nothing claimed for benqi claim rewards")
+		return handlers.Unexpected("Nothing claimed for benqi claim rewards")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -242,11 +254,14 @@ func handleBenqiClaimRewards(bundle handlers.TransactionBundle, client *evm.Clie
 		Type:         ctc_util.CTCIncome,
 		BaseCurrency: claimed.Asset.Symbol,
 		BaseAmount:   claimed.Value,
+		BaseAsset:    claimed.Asset,
 		From:         "benqi",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("benqi: claim %s in rewards", claimed),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
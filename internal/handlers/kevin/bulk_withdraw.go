@@ -21,7 +21,7 @@ func handleBulkWithdrawFrom(label string) handlers.TransactionHandlerFunc {
 
 func handleBulkWithdraw(label string, bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
 	if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-		panic("Unexpected bulk withdraw from my own address")
+		return handlers.Unexpected("Unexpected bulk withdraw from my own address")
 	}
 
 	netTransfers, err := evm_util.NetTokenTransfersOnlyMine(client, bundle.Info, bundle.Receipt.Logs)
@@ -30,7 +30,7 @@ func handleBulkWithdraw(label string, bundle handlers.TransactionBundle, client
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for bulk withdraw")
+		return handlers.Unexpected("Unexpected net transfers for bulk withdraw")
 	}
 
 	var received core.Amount
@@ -38,11 +38,11 @@ func handleBulkWithdraw(label string, bundle handlers.TransactionBundle, client
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for bulk withdraw")
+			return handlers.Unexpected("Unexpected net transfers for bulk withdraw")
 		}
 		for addr, amount := range transfers {
 			if !amount.Value.IsPositive() {
-				panic("Outflow for bulk withdraw")
+				return handlers.Unexpected("Outflow for bulk withdraw")
 			}
 			received = *amount
 			receivedTo = addr
@@ -50,7 +50,7 @@ func handleBulkWithdraw(label string, bundle handlers.TransactionBundle, client
 	}
 
 	if received.Asset.Symbol == "" {
-		panic("Nothing received for bulk withdraw")
+		return handlers.Unexpected("Nothing received for bulk withdraw")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -60,10 +60,11 @@ func handleBulkWithdraw(label string, bundle handlers.TransactionBundle, client
 		Type:         ctc_util.CTCReceive,
 		BaseCurrency: received.Asset.Symbol,
 		BaseAmount:   received.Value,
+		BaseAsset:    received.Asset,
 		From:         label,
 		To:           receivedTo.Hex(),
 		Description:  fmt.Sprintf("withdraw %s from %s", received, label),
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
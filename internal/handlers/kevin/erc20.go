@@ -10,6 +10,7 @@ import (
 
 	"github.com/ksmithbaylor/gohodl/internal/abis"
 	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/core"
 	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
 	"github.com/ksmithbaylor/gohodl/internal/evm"
 	"github.com/ksmithbaylor/gohodl/internal/handlers"
@@ -23,14 +24,19 @@ func handleErc20Transfer(bundle handlers.TransactionBundle, client *evm.Client,
 		return err
 	}
 
+	if len(events) == 0 && bundle.Info.Method == abis.ERC721_TRANSFER_FROM {
+		// ERC-721 transferFrom has the same selector, but no ERC-20 events
+		return handleNft("nft transfer", bundle, client, export)
+	}
+
 	if len(events) != 1 {
 		fmt.Println(bundle.Info.Hash, bundle.Info.Network)
-		panic("Found more than one event in ERC20 transfer call")
+		return handlers.Unexpected("Found more than one event in ERC20 transfer call")
 	}
 
 	transfer := events[0]
 	if transfer.Name != "Transfer" {
-		panic("Non-transfer event found in ERC20 transfer call")
+		return handlers.Unexpected("Non-transfer event found in ERC20 transfer call")
 	}
 
 	from := transfer.Data["from"].(common.Address).Hex()
@@ -53,7 +59,7 @@ func handleErc20Transfer(bundle handlers.TransactionBundle, client *evm.Client,
 		if toMe {
 			ctcType = ctc_util.CTCReceive
 		} else {
-			panic("Found irrelevant transaction, not from/to any of my addresses")
+			return handlers.Unexpected("Found irrelevant transaction, not from/to any of my addresses")
 		}
 	}
 
@@ -64,6 +70,7 @@ func handleErc20Transfer(bundle handlers.TransactionBundle, client *evm.Client,
 		Type:         ctcType,
 		BaseCurrency: tokenAsset.Symbol,
 		BaseAmount:   amount.Value,
+		BaseAsset:    tokenAsset,
 		From:         from,
 		To:           to,
 		Description: fmt.Sprintf("transfer %s from %s to %s on %s",
@@ -89,6 +96,7 @@ func handleErc20Transfer(bundle handlers.TransactionBundle, client *evm.Client,
 			Type:         ctc_util.CTCBridgeIn,
 			BaseCurrency: tokenAsset.Symbol,
 			BaseAmount:   amount.Value,
+			BaseAsset:    tokenAsset,
 			From:         ctcTx.From,
 			To:           ctcTx.To,
 			Description: fmt.Sprintf("bridge %s to %s on avalanche (not a real tx)",
@@ -96,21 +104,23 @@ func handleErc20Transfer(bundle handlers.TransactionBundle, client *evm.Client,
 				to,
 			),
 		}
-		err = export(otherSideTx.ToCSV())
+		err = export(otherSideTx)
 		if err != nil {
 			return fmt.Errorf("Could not export synthetic avax bridge tx: %w", err)
 		}
 	}
 
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
 	// Add the other side of self-transfers
 	if fromMe && toMe {
 		if ctcTx.Type != ctc_util.CTCSend {
-			panic("erc20 to me and from me but not send")
+			return handlers.Unexpected("erc20 to me and from me but not send")
 		}
 		ctcTx.ID = bundle.Info.Hash + "-1"
-		err = export(ctcTx.ToCSV())
+		err = export(ctcTx)
 		if err != nil {
 			return err
 		}
@@ -118,9 +128,10 @@ func handleErc20Transfer(bundle handlers.TransactionBundle, client *evm.Client,
 		ctcTx.ID = bundle.Info.Hash + "-2"
 		ctcTx.FeeAmount = decimal.Zero
 		ctcTx.FeeCurrency = ""
+		ctcTx.FeeAsset = core.Asset{}
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleErc20Approve(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -130,12 +141,12 @@ func handleErc20Approve(bundle handlers.TransactionBundle, client *evm.Client, e
 	}
 
 	if len(events) != 1 {
-		panic("Found more than one event in ERC20 approve call")
+		return handlers.Unexpected("Found more than one event in ERC20 approve call")
 	}
 
 	approval := events[0]
 	if approval.Name != "Approval" {
-		panic("Non-approval event found in ERC20 approve call")
+		return handlers.Unexpected("Non-approval event found in ERC20 approve call")
 	}
 
 	owner := approval.Data["owner"].(common.Address).Hex()
@@ -152,7 +163,7 @@ func handleErc20Approve(bundle handlers.TransactionBundle, client *evm.Client, e
 		return err
 	}
 
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash,
@@ -160,6 +171,9 @@ func handleErc20Approve(bundle handlers.TransactionBundle, client *evm.Client, e
 		fmt.Sprintf("approve %s for spending by %s from %s", amount.String(), spender, owner),
 		bundle.Receipt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
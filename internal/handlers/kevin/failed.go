@@ -19,7 +19,9 @@ func handleFailed(bundle handlers.TransactionBundle, client *evm.Client, export
 		Description: "failed transaction",
 	}
 
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
@@ -0,0 +1,52 @@
+package kevin_test
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/handlers/fixtures"
+	"github.com/ksmithbaylor/gohodl/internal/handlers/kevin"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden CSVs from the handlers' current output")
+
+// Fixture tests
+
+func TestHandlerFixtures(t *testing.T) {
+	paths, err := fixtures.Glob("testdata")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			fixture, err := fixtures.Load(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			rows, handled, err := fixture.Run(kevin.Implementation, t.TempDir())
+			actual := fixtures.FormatGolden(rows, handled, err)
+			assertGolden(t, fixtures.GoldenPath(path), actual)
+		})
+	}
+}
+
+// assertGolden compares the result of running a fixture with its golden CSV,
+// or rewrites the golden CSV if updating.
+func assertGolden(t *testing.T, path string, actual []byte) {
+	t.Helper()
+
+	if *update {
+		assert.NoError(t, fixtures.WriteGolden(path, actual))
+		return
+	}
+
+	expected, err := fixtures.ReadGolden(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, string(expected), string(actual), "Rows differ from %s (run with -update if this is expected)", path)
+}
@@ -29,7 +29,7 @@ func handleFriendTechBuy(bundle handlers.TransactionBundle, client *evm.Client,
 	var paid core.Amount
 
 	if len(netTransfers) > 1 {
-		panic("Unexpected net transfers for friend.tech buy")
+		return handlers.Unexpected("Unexpected net transfers for friend.tech buy")
 	}
 
 	if len(netTransfers) == 0 {
@@ -42,23 +42,23 @@ func handleFriendTechBuy(bundle handlers.TransactionBundle, client *evm.Client,
 
 	for asset, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for friend.tech buy")
+			return handlers.Unexpected("Unexpected net transfers for friend.tech buy")
 		}
 		if asset.Kind != core.EvmNative {
-			panic("Non-native asset transfer for friend.tech buy")
+			return handlers.Unexpected("Non-native asset transfer for friend.tech buy")
 		}
 		for addr, amount := range transfers {
 			if amount.Value.IsNegative() {
 				if addr.Hex() != bundle.Info.From {
-					panic("Unexpected net transfers for friend.tech buy")
+					return handlers.Unexpected("Unexpected net transfers for friend.tech buy")
 				}
 				if received.Asset.Symbol != "" {
-					panic("Buy and receive in same friend.tech buy transaction")
+					return handlers.Unexpected("Buy and receive in same friend.tech buy transaction")
 				}
 				paid = amount.Neg()
 			} else {
 				if paid.Asset.Symbol != "" {
-					panic("Buy and receive in same friend.tech buy transaction")
+					return handlers.Unexpected("Buy and receive in same friend.tech buy transaction")
 				}
 				received = *amount
 				receivedTo = addr
@@ -74,16 +74,17 @@ func handleFriendTechBuy(bundle handlers.TransactionBundle, client *evm.Client,
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: received.Asset.Symbol,
 			BaseAmount:   received.Value,
+			BaseAsset:    received.Asset,
 			From:         "friend.tech",
 			To:           receivedTo.Hex(),
 			Description:  "friend.tech: someone bought my shares",
 		}
 
 		if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-			panic("Bought my own shares for friend.tech buy")
+			return handlers.Unexpected("Bought my own shares for friend.tech buy")
 		}
 
-		return export(ctcTx.ToCSV())
+		return export(*ctcTx)
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.FriendTechAbi)
@@ -92,25 +93,25 @@ func handleFriendTechBuy(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if len(events) != 1 || events[0].Name != "Trade" {
-		panic("Unexpected events for friend.tech buy")
+		return handlers.Unexpected("Unexpected events for friend.tech buy")
 	}
 
 	event := events[0]
 
 	isBuy := event.Data["isBuy"].(bool)
 	if !isBuy {
-		panic("Sell event for friend.tech buy")
+		return handlers.Unexpected("Sell event for friend.tech buy")
 	}
 
 	quantityString := event.Data["shareAmount"].(*big.Int).String()
 	quantity, err := decimal.NewFromString(quantityString)
 	if err != nil {
-		panic("Invalid shareAmount string for friend.tech buy")
+		return handlers.Unexpected("Invalid shareAmount string for friend.tech buy")
 	}
 
 	subject, ok := event.Data["subject"].(common.Address)
 	if !ok {
-		panic("Invalid subject for friend.tech buy")
+		return handlers.Unexpected("Invalid subject for friend.tech buy")
 	}
 
 	description := friendTechDescription(subject)
@@ -124,6 +125,7 @@ func handleFriendTechBuy(bundle handlers.TransactionBundle, client *evm.Client,
 		BaseAmount:    quantity,
 		QuoteCurrency: paid.Asset.Symbol,
 		QuoteAmount:   paid.Value,
+		QuoteAsset:    paid.Asset,
 		From:          "friend.tech",
 		To:            bundle.Info.From,
 		Description: fmt.Sprintf("friend.tech: bought %s shares of %s with %s",
@@ -132,9 +134,11 @@ func handleFriendTechBuy(bundle handlers.TransactionBundle, client *evm.Client,
 			paid,
 		),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -144,7 +148,7 @@ func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for friend.tech sell")
+		return handlers.Unexpected("Unexpected net transfers for friend.tech sell")
 	}
 
 	var received core.Amount
@@ -153,23 +157,23 @@ func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client,
 
 	for asset, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for friend.tech sell")
+			return handlers.Unexpected("Unexpected net transfers for friend.tech sell")
 		}
 		if asset.Kind != core.EvmNative {
-			panic("Non-native asset transfer for friend.tech sell")
+			return handlers.Unexpected("Non-native asset transfer for friend.tech sell")
 		}
 		for addr, amount := range transfers {
 			if !amount.Value.IsPositive() {
-				panic("Unexpected outflow for friend.tech sell")
+				return handlers.Unexpected("Unexpected outflow for friend.tech sell")
 			}
 			if addr.Hex() == bundle.Info.From {
 				if received.Asset.Symbol != "" {
-					panic("Sell and receive in same friend.tech sell transaction")
+					return handlers.Unexpected("Sell and receive in same friend.tech sell transaction")
 				}
 				saleProceeds = *amount
 			} else {
 				if saleProceeds.Asset.Symbol != "" {
-					panic("Sell and receive in same friend.tech sell transaction")
+					return handlers.Unexpected("Sell and receive in same friend.tech sell transaction")
 				}
 				received = *amount
 				receivedTo = addr
@@ -185,16 +189,17 @@ func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client,
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: received.Asset.Symbol,
 			BaseAmount:   received.Value,
+			BaseAsset:    received.Asset,
 			From:         "friend.tech",
 			To:           receivedTo.Hex(),
 			Description:  "friend.tech: someone sold my shares",
 		}
 
 		if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-			panic("Sold my own shares for friend.tech sell")
+			return handlers.Unexpected("Sold my own shares for friend.tech sell")
 		}
 
-		return export(ctcTx.ToCSV())
+		return export(*ctcTx)
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.FriendTechAbi)
@@ -203,25 +208,25 @@ func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if len(events) != 1 || events[0].Name != "Trade" {
-		panic("Unexpected events for friend.tech sell")
+		return handlers.Unexpected("Unexpected events for friend.tech sell")
 	}
 
 	event := events[0]
 
 	isBuy := event.Data["isBuy"].(bool)
 	if isBuy {
-		panic("Buy event for friend.tech sell")
+		return handlers.Unexpected("Buy event for friend.tech sell")
 	}
 
 	quantityString := event.Data["shareAmount"].(*big.Int).String()
 	quantity, err := decimal.NewFromString(quantityString)
 	if err != nil {
-		panic("Invalid shareAmount string for friend.tech sell")
+		return handlers.Unexpected("Invalid shareAmount string for friend.tech sell")
 	}
 
 	subject, ok := event.Data["subject"].(common.Address)
 	if !ok {
-		panic("Invalid subject for friend.tech buy")
+		return handlers.Unexpected("Invalid subject for friend.tech buy")
 	}
 
 	description := friendTechDescription(subject)
@@ -235,6 +240,7 @@ func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client,
 		BaseAmount:    quantity,
 		QuoteCurrency: saleProceeds.Asset.Symbol,
 		QuoteAmount:   saleProceeds.Value,
+		QuoteAsset:    saleProceeds.Asset,
 		From:          "friend.tech",
 		To:            bundle.Info.From,
 		Description: fmt.Sprintf("friend.tech: sold %s shares of %s for %s",
@@ -243,9 +249,11 @@ func handleFriendTechSell(bundle handlers.TransactionBundle, client *evm.Client,
 			saleProceeds,
 		),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func friendTechDescription(subject common.Address) string {
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/k0kubun/pp/v3"
 
 	"github.com/ksmithbaylor/gohodl/internal/abis"
 	"github.com/ksmithbaylor/gohodl/internal/core"
@@ -48,7 +47,7 @@ type instadappTargetHandlerArgs struct {
 	export               handlers.CTCWriter
 }
 
-func (args instadappTargetHandlerArgs) Print() {
+func (args instadappTargetHandlerArgs) Print() error {
 	fmt.Printf("--------- %s, %s: %s -> %s on %s\n",
 		time.Unix(int64(args.bundle.Block.Time), 0).UTC().Format("2006-01-02 15:04:05"),
 		args.bundle.Info.Hash,
@@ -85,8 +84,7 @@ func (args instadappTargetHandlerArgs) Print() {
 				case bool:
 					fmt.Printf("          - bool %t\n", arg)
 				default:
-					pp.Println(arg)
-					panic("Unknown instadapp sub-event arg type")
+					return fmt.Errorf("Unknown instadapp sub-event arg type %T", arg)
 				}
 			}
 		}
@@ -97,6 +95,8 @@ func (args instadappTargetHandlerArgs) Print() {
 
 	fmt.Println("Net transfers (only mine):")
 	fmt.Println(args.netTransfersOnlyMine)
+
+	return nil
 }
 
 func handleInstadapp(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -109,7 +109,7 @@ func handleInstadapp(bundle handlers.TransactionBundle, client *evm.Client, expo
 
 	for i, event := range events {
 		if event.Name != "LogCast" && event.Name != "LogCastMigrate" {
-			panic("Unexpected event emitted from instadapp call")
+			return handlers.Unexpected("Unexpected event emitted from instadapp call")
 		}
 
 		origin := event.Data["origin"].(common.Address)
@@ -121,11 +121,11 @@ func handleInstadapp(bundle handlers.TransactionBundle, client *evm.Client, expo
 		targetNames := event.Data["targetsNames"].([]string)
 
 		if value.String() != "0" {
-			panic("Unexpected value in instadapp cast log")
+			return handlers.Unexpected("Unexpected value in instadapp cast log")
 		}
 
 		if len(eventNames) != len(targets) || len(eventParams) != len(targets) || len(targetNames) != len(targets) {
-			panic("Mismatched sub-events in instadapp call")
+			return handlers.Unexpected("Mismatched sub-events in instadapp call")
 		}
 
 		subEvents := make([]instadappSubEvent, len(eventNames))
@@ -157,7 +157,7 @@ func handleInstadapp(bundle handlers.TransactionBundle, client *evm.Client, expo
 	}
 
 	if len(instadappEvents) == 0 {
-		panic("No instadapp events in instadapp transaction")
+		return handlers.Unexpected("No instadapp events in instadapp transaction")
 	}
 
 	return handleInstadappEvents(instadappEvents, bundle, client, export)
@@ -186,7 +186,7 @@ func handleInstadappEvents(
 
 	subEventNumber := 0
 
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		fmt.Sprintf("%s-%d", bundle.Info.Hash, subEventNumber),
@@ -194,7 +194,10 @@ func handleInstadappEvents(
 		"instadapp: record network fee separately from individual events",
 		bundle.Receipt,
 	)
-	err = combineErrs(err, export(ctcTx.ToCSV()))
+	if err != nil {
+		return err
+	}
+	err = combineErrs(err, export(*ctcTx))
 
 	if len(events) > 1 {
 		args := instadappTargetHandlerArgs{
@@ -250,7 +253,7 @@ func handleInstadappEvents(
 			case "1INCH-A", "1INCH-V4-A", "PARASWAP-A", "PARASWAP-V5-A":
 				err = combineErrs(err, handleInstadappTarget1inchOrParaswap(args))
 			default:
-				panic("Unknown instadapp target: " + subEvent.targetName)
+				return handlers.Unexpected("Unknown instadapp target: " + subEvent.targetName)
 			}
 		}
 	}
@@ -276,7 +279,7 @@ func instadappTxID(args instadappTargetHandlerArgs) string {
 
 func handleInstadappTargetBasicA(args instadappTargetHandlerArgs) error {
 	if args.subEvent.selector != "LogWithdraw(address,uint256,address,uint256,uint256)" {
-		panic("Unknown BASIC-A selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown BASIC-A selector: " + args.subEvent.selector)
 	}
 
 	token := args.subEvent.args[0].(common.Address)
@@ -301,6 +304,7 @@ func handleInstadappTargetBasicA(args instadappTargetHandlerArgs) error {
 		Type:         ctc_util.CTCSend,
 		BaseCurrency: asset.Symbol,
 		BaseAmount:   amount.Value,
+		BaseAsset:    asset,
 		From:         dsa,
 		To:           dest.Hex(),
 		Description: fmt.Sprintf("instadapp: withdraw %s to %s from dsa %s on %s",
@@ -311,12 +315,12 @@ func handleInstadappTargetBasicA(args instadappTargetHandlerArgs) error {
 		),
 	}
 
-	return args.export(ctcTx.ToCSV())
+	return args.export(ctcTx)
 }
 
 func handleInstadappTargetAuthorityA(args instadappTargetHandlerArgs) error {
 	if args.subEvent.selector != "LogAddAuth(address,address)" {
-		panic("Unknown AUTHORITY-A selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown AUTHORITY-A selector: " + args.subEvent.selector)
 	}
 
 	// Nothing to do here tax-wise, and transaction fee is already handled
@@ -343,7 +347,7 @@ func handleInstadappTargetAaveV2A(args instadappTargetHandlerArgs) error {
 		},
 		args.subEvent.selector,
 	) {
-		panic("Unknown AAVE-V2-A selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown AAVE-V2-A selector: " + args.subEvent.selector)
 	}
 
 	// All four of the above sub-events have the token as the first argument, and
@@ -385,7 +389,7 @@ func handleInstadappTargetAaveV2A(args instadappTargetHandlerArgs) error {
 		from = aaveConnector
 		to = dsa
 	default:
-		panic("Unknown AAVE-V2-A selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown AAVE-V2-A selector: " + args.subEvent.selector)
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -395,23 +399,24 @@ func handleInstadappTargetAaveV2A(args instadappTargetHandlerArgs) error {
 		Type:         ctcType,
 		BaseCurrency: asset.Symbol,
 		BaseAmount:   amount.Value,
+		BaseAsset:    asset,
 		From:         from,
 		To:           to,
 		Description:  description,
 	}
 
-	return args.export(ctcTx.ToCSV())
+	return args.export(ctcTx)
 }
 
 func handleInstadappTargetAaveClaimA(args instadappTargetHandlerArgs) error {
 	if args.totalSubEvents > 1 {
-		panic("Unexpected multiple instadapp events for AAVE-CLAIM-A")
+		return handlers.Unexpected("Unexpected multiple instadapp events for AAVE-CLAIM-A")
 	}
 	if args.subEvent.selector != "LogClaimed(address[],uint256,uint256,uint256)" {
-		panic("Unknown AAVE-CLAIM-A selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown AAVE-CLAIM-A selector: " + args.subEvent.selector)
 	}
 	if len(args.netTransfersOnlyMine) > 1 {
-		panic("Multiple assets claimed for instadapp")
+		return handlers.Unexpected("Multiple assets claimed for instadapp")
 	}
 	if len(args.netTransfersOnlyMine) == 0 {
 		ctcTx := ctc_util.CTCTransaction{
@@ -426,17 +431,17 @@ func handleInstadappTargetAaveClaimA(args instadappTargetHandlerArgs) error {
 			),
 		}
 
-		return args.export(ctcTx.ToCSV())
+		return args.export(ctcTx)
 	}
 
 	for asset, transfers := range args.netTransfersOnlyMine {
 		if len(transfers) != 1 {
-			panic("Extra net transfer in instadapp claim")
+			return handlers.Unexpected("Extra net transfer in instadapp claim")
 		}
 
 		dsaInflow, ok := transfers[common.HexToAddress(args.bundle.Info.To)]
 		if !ok {
-			panic("No DSA inflow in instadapp claim")
+			return handlers.Unexpected("No DSA inflow in instadapp claim")
 		}
 
 		ctcTx := ctc_util.CTCTransaction{
@@ -446,6 +451,7 @@ func handleInstadappTargetAaveClaimA(args instadappTargetHandlerArgs) error {
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: asset.Symbol,
 			BaseAmount:   dsaInflow.Value,
+			BaseAsset:    asset,
 			From:         "Instadapp Aave",
 			To:           args.bundle.Info.To,
 			Description: fmt.Sprintf("instadapp: claim %s in rewards for dsa %s on %s",
@@ -455,7 +461,7 @@ func handleInstadappTargetAaveClaimA(args instadappTargetHandlerArgs) error {
 			),
 		}
 
-		return args.export(ctcTx.ToCSV())
+		return args.export(ctcTx)
 	}
 
 	return NOT_HANDLED
@@ -463,23 +469,23 @@ func handleInstadappTargetAaveClaimA(args instadappTargetHandlerArgs) error {
 
 func handleInstadappTargetAaveClaimB(args instadappTargetHandlerArgs) error {
 	if args.totalSubEvents > 1 {
-		panic("Unexpected multiple instadapp events for AAVE-CLAIM-B")
+		return handlers.Unexpected("Unexpected multiple instadapp events for AAVE-CLAIM-B")
 	}
 	if args.subEvent.selector != "LogAaveV2Claim(address,address[],address[],uint256[],uint256[])" {
-		panic("Unknown AAVE-CLAIM-B selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown AAVE-CLAIM-B selector: " + args.subEvent.selector)
 	}
 	if len(args.netTransfersOnlyMine) != 1 {
-		panic("Multiple assets claimed for instadapp")
+		return handlers.Unexpected("Multiple assets claimed for instadapp")
 	}
 
 	for asset, transfers := range args.netTransfersOnlyMine {
 		if len(transfers) != 1 {
-			panic("Extra net transfer in instadapp claim")
+			return handlers.Unexpected("Extra net transfer in instadapp claim")
 		}
 
 		dsaInflow, ok := transfers[common.HexToAddress(args.bundle.Info.To)]
 		if !ok {
-			panic("No DSA inflow in instadapp claim")
+			return handlers.Unexpected("No DSA inflow in instadapp claim")
 		}
 
 		ctcTx := ctc_util.CTCTransaction{
@@ -489,6 +495,7 @@ func handleInstadappTargetAaveClaimB(args instadappTargetHandlerArgs) error {
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: asset.Symbol,
 			BaseAmount:   dsaInflow.Value,
+			BaseAsset:    asset,
 			From:         "Instadapp Aave",
 			To:           args.bundle.Info.To,
 			Description: fmt.Sprintf("instadapp: claim %s in rewards for dsa %s on %s",
@@ -498,7 +505,7 @@ func handleInstadappTargetAaveClaimB(args instadappTargetHandlerArgs) error {
 			),
 		}
 
-		return args.export(ctcTx.ToCSV())
+		return args.export(ctcTx)
 	}
 
 	return NOT_HANDLED
@@ -506,7 +513,7 @@ func handleInstadappTargetAaveClaimB(args instadappTargetHandlerArgs) error {
 
 func handleInstadappTargetAaveV2ImportA(args instadappTargetHandlerArgs) error {
 	if args.subEvent.selector != "LogAaveV2Import(address,bool,address[],address[],uint256[],uint256[],uint256[])" {
-		panic("Unknown AAVE-V2-IMPORT-A selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown AAVE-V2-IMPORT-A selector: " + args.subEvent.selector)
 	}
 
 	// Nothing to do here from a tax perspective, just moving a position around
@@ -521,7 +528,7 @@ func handleInstadappTarget1inchOrParaswap(args instadappTargetHandlerArgs) error
 		},
 		args.subEvent.selector,
 	) {
-		panic("Unknown 1INCH-*/PARASWAP-* selector: " + args.subEvent.selector)
+		return handlers.Unexpected("Unknown 1INCH-*/PARASWAP-* selector: " + args.subEvent.selector)
 	}
 
 	boughtToken := args.subEvent.args[0].(common.Address)
@@ -558,14 +565,16 @@ func handleInstadappTarget1inchOrParaswap(args instadappTargetHandlerArgs) error
 		Type:          ctc_util.CTCSell,
 		BaseCurrency:  soldAsset.Symbol,
 		BaseAmount:    soldAmount.Value,
+		BaseAsset:     soldAsset,
 		QuoteCurrency: boughtAsset.Symbol,
 		QuoteAmount:   boughtAmount.Value,
+		QuoteAsset:    boughtAsset,
 		From:          dsa,
 		To:            connector.Hex(),
 		Description:   fmt.Sprintf("instadapp: sell %s for %s", soldAmount, boughtAmount),
 	}
 
-	return args.export(ctcTx.ToCSV())
+	return args.export(ctcTx)
 }
 
 func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
@@ -589,7 +598,7 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 	for _, transfers := range args.netTransfersOnlyMine {
 		for addr, amount := range transfers {
 			if addr.Hex() != dsa {
-				panic("Net flows for an address other than the dsa")
+				return handlers.Unexpected("Net flows for an address other than the dsa")
 			}
 
 			symbol := amount.Asset.Symbol
@@ -622,7 +631,7 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 	}
 
 	if len(descriptors) < 2 || len(descriptors) > 3 {
-		panic("Unexpected net flows for complex instadapp operation")
+		return handlers.Unexpected("Unexpected net flows for complex instadapp operation")
 	}
 
 	// For consistency in the next step, sort by kind, then asset name
@@ -664,7 +673,7 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 	switch summary {
 	case "+aToken, -aToken": // Collateral swap
 		if descriptors[0].asset == descriptors[1].asset {
-			panic("Collateral swap but no change in assets")
+			return handlers.Unexpected("Collateral swap but no change in assets")
 		}
 		ctcTxs = []ctc_util.CTCTransaction{
 			{
@@ -791,7 +800,7 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 		})
 	case "+debtToken, -debtToken": // Debt swap
 		if descriptors[0].asset == descriptors[1].asset {
-			panic("Debt swap but no change in assets")
+			return handlers.Unexpected("Debt swap but no change in assets")
 		}
 		ctcTxs = []ctc_util.CTCTransaction{
 			{
@@ -834,7 +843,7 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 		}
 	case "-aToken, +asset, -debtToken": // Lever down with leftover
 		if descriptors[0].asset != descriptors[2].asset {
-			panic("Lever down but assets don't match")
+			return handlers.Unexpected("Lever down but assets don't match")
 		}
 		ctcTxs = []ctc_util.CTCTransaction{
 			{
@@ -914,7 +923,7 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 			},
 		}
 	default:
-		panic("Unexpected descriptor combination")
+		return handlers.Unexpected("Unexpected descriptor combination")
 	}
 
 	if len(ctcTxs) == 0 {
@@ -923,14 +932,14 @@ func handleInstadappMultiEvents(args instadappTargetHandlerArgs) error {
 
 	var err error
 	for _, ctcTx := range ctcTxs {
-		err = combineErrs(err, args.export(ctcTx.ToCSV()))
+		err = combineErrs(err, args.export(ctcTx))
 	}
 
 	return err
 }
 
 func handleInstadappDSACreate(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash,
@@ -938,6 +947,9 @@ func handleInstadappDSACreate(bundle handlers.TransactionBundle, client *evm.Cli
 		"instadapp: create DSA",
 		bundle.Receipt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
@@ -20,7 +20,7 @@ func handleMiscWithLabel(label string) handlers.TransactionHandlerFunc {
 }
 
 func handleMisc(label string, bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash,
@@ -28,8 +28,11 @@ func handleMisc(label string, bundle handlers.TransactionBundle, client *evm.Cli
 		label,
 		bundle.Receipt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func handleRewardWithLabel(label string) handlers.TransactionHandlerFunc {
@@ -45,7 +48,7 @@ func handleReward(label string, bundle handlers.TransactionBundle, client *evm.C
 	}
 
 	if len(netTransfers) > 1 {
-		panic("Multiple net transfers for rewards tx")
+		return handlers.Unexpected("Multiple net transfers for rewards tx")
 	}
 
 	var rewardAmount *core.Amount
@@ -53,14 +56,14 @@ func handleReward(label string, bundle handlers.TransactionBundle, client *evm.C
 
 	for _, transfers := range netTransfers {
 		if len(transfers) > 1 {
-			panic("Multiple net transfers for rewards tx")
+			return handlers.Unexpected("Multiple net transfers for rewards tx")
 		}
 		for addr, amount := range transfers {
 			if amount.Value.IsPositive() {
 				rewardAmount = amount
 				receivedTo = addr
 			} else {
-				panic("Outflow for rewards tx")
+				return handlers.Unexpected("Outflow for rewards tx")
 			}
 		}
 	}
@@ -69,7 +72,7 @@ func handleReward(label string, bundle handlers.TransactionBundle, client *evm.C
 
 	if rewardAmount == nil {
 		if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-			ctcTx = ctc_util.NewFeeTransaction(
+			ctcTx, err = ctc_util.NewFeeTransaction(
 				bundle.Block.Time,
 				bundle.Info.Network,
 				bundle.Info.Hash,
@@ -77,6 +80,9 @@ func handleReward(label string, bundle handlers.TransactionBundle, client *evm.C
 				label+": rewards, but nothing was claimed",
 				bundle.Receipt,
 			)
+			if err != nil {
+				return err
+			}
 		}
 	} else {
 		ctcTx = &ctc_util.CTCTransaction{
@@ -86,16 +92,19 @@ func handleReward(label string, bundle handlers.TransactionBundle, client *evm.C
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: rewardAmount.Asset.Symbol,
 			BaseAmount:   rewardAmount.Value,
+			BaseAsset:    rewardAmount.Asset,
 			From:         label,
 			To:           receivedTo.Hex(),
 			Description:  fmt.Sprintf("%s: reward of %s", label, *rewardAmount),
 		}
-		ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+		if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+			return err
+		}
 	}
 
 	if ctcTx == nil {
 		return nil
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
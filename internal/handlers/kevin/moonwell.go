@@ -14,7 +14,7 @@ import (
 func handleMoonwellEnterMarkets(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
 	// This just opts an asset into being used as collateral, so the fee is all
 	// that needs to be handled
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash,
@@ -22,8 +22,11 @@ func handleMoonwellEnterMarkets(bundle handlers.TransactionBundle, client *evm.C
 		"moonwell: use an asset as collateral",
 		bundle.Receipt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func handleMoonwellClaimReward(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -33,23 +36,23 @@ func handleMoonwellClaimReward(bundle handlers.TransactionBundle, client *evm.Cl
 	}
 
 	if len(netTransfers) > 1 {
-		panic("Multiple net transfers for moonwell rewards claim")
+		return handlers.Unexpected("Multiple net transfers for moonwell rewards claim")
 	}
 
 	var rewardAmount *core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) > 1 {
-			panic("Multiple net transfers for moonwell rewards claim")
+			return handlers.Unexpected("Multiple net transfers for moonwell rewards claim")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Wrong address received rewards for moonwell rewards claim")
+				return handlers.Unexpected("Wrong address received rewards for moonwell rewards claim")
 			}
 			if amount.Value.IsPositive() {
 				rewardAmount = amount
 			} else {
-				panic("Outflow for moonwell rewards claim")
+				return handlers.Unexpected("Outflow for moonwell rewards claim")
 			}
 		}
 	}
@@ -57,7 +60,7 @@ func handleMoonwellClaimReward(bundle handlers.TransactionBundle, client *evm.Cl
 	var ctcTx *ctc_util.CTCTransaction
 
 	if rewardAmount == nil {
-		ctcTx = ctc_util.NewFeeTransaction(
+		ctcTx, err = ctc_util.NewFeeTransaction(
 			bundle.Block.Time,
 			bundle.Info.Network,
 			bundle.Info.Hash,
@@ -65,6 +68,9 @@ func handleMoonwellClaimReward(bundle handlers.TransactionBundle, client *evm.Cl
 			"moonwell: claim rewards, but nothing was claimed",
 			bundle.Receipt,
 		)
+		if err != nil {
+			return err
+		}
 	} else {
 		ctcTx = &ctc_util.CTCTransaction{
 			Timestamp:    time.Unix(int64(bundle.Block.Time), 0).UTC(),
@@ -73,6 +79,7 @@ func handleMoonwellClaimReward(bundle handlers.TransactionBundle, client *evm.Cl
 			Type:         ctc_util.CTCInterest,
 			BaseCurrency: rewardAmount.Asset.Symbol,
 			BaseAmount:   rewardAmount.Value,
+			BaseAsset:    rewardAmount.Asset,
 			From:         "unknown",
 			To:           bundle.Info.From,
 			Description: fmt.Sprintf("moonwell: claim reward on %s, %s",
@@ -80,10 +87,12 @@ func handleMoonwellClaimReward(bundle handlers.TransactionBundle, client *evm.Cl
 				*rewardAmount,
 			),
 		}
-		ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+		if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+			return err
+		}
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func handleMoonwellMint(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -93,18 +102,18 @@ func handleMoonwellMint(bundle handlers.TransactionBundle, client *evm.Client, e
 	}
 
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for moonwell mint")
+		return handlers.Unexpected("Unexpected net transfers for moonwell mint")
 	}
 
 	var deposited core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for moonwell mint")
+			return handlers.Unexpected("Unexpected net transfers for moonwell mint")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for moonwell mint")
+				return handlers.Unexpected("Unexpected net transfers for moonwell mint")
 			}
 			if amount.Value.IsNegative() {
 				deposited = amount.Neg()
@@ -113,7 +122,7 @@ func handleMoonwellMint(bundle handlers.TransactionBundle, client *evm.Client, e
 	}
 
 	if deposited.Asset.Symbol == "" {
-		panic("No asset deposited for moonwell mint")
+		return handlers.Unexpected("No asset deposited for moonwell mint")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -123,13 +132,16 @@ func handleMoonwellMint(bundle handlers.TransactionBundle, client *evm.Client, e
 		Type:         ctc_util.CTCCollateralDeposit,
 		BaseCurrency: deposited.Asset.Symbol,
 		BaseAmount:   deposited.Value,
+		BaseAsset:    deposited.Asset,
 		From:         bundle.Info.From,
 		To:           "moonwell",
 		Description:  fmt.Sprintf("moonwell: supply %s", deposited),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleMoonwellBorrow(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -139,18 +151,18 @@ func handleMoonwellBorrow(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for moonwell borrow")
+		return handlers.Unexpected("Unexpected net transfers for moonwell borrow")
 	}
 
 	var borrowed core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for moonwell borrow")
+			return handlers.Unexpected("Unexpected net transfers for moonwell borrow")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for moonwell borrow")
+				return handlers.Unexpected("Unexpected net transfers for moonwell borrow")
 			}
 			if amount.Value.IsPositive() {
 				borrowed = *amount
@@ -159,7 +171,7 @@ func handleMoonwellBorrow(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if borrowed.Asset.Symbol == "" {
-		panic("No asset received for moonwell borrow")
+		return handlers.Unexpected("No asset received for moonwell borrow")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -169,13 +181,16 @@ func handleMoonwellBorrow(bundle handlers.TransactionBundle, client *evm.Client,
 		Type:         ctc_util.CTCBorrow,
 		BaseCurrency: borrowed.Asset.Symbol,
 		BaseAmount:   borrowed.Value,
+		BaseAsset:    borrowed.Asset,
 		From:         "moonwell",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("moonwell: borrow %s", borrowed),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleMoonwellRepayBorrow(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -185,18 +200,18 @@ func handleMoonwellRepayBorrow(bundle handlers.TransactionBundle, client *evm.Cl
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for moonwell borrow")
+		return handlers.Unexpected("Unexpected net transfers for moonwell borrow")
 	}
 
 	var repaid core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for moonwell repay")
+			return handlers.Unexpected("Unexpected net transfers for moonwell repay")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for moonwell repay")
+				return handlers.Unexpected("Unexpected net transfers for moonwell repay")
 			}
 			if amount.Value.IsNegative() {
 				repaid = amount.Neg()
@@ -205,7 +220,7 @@ func handleMoonwellRepayBorrow(bundle handlers.TransactionBundle, client *evm.Cl
 	}
 
 	if repaid.Asset.Symbol == "" {
-		panic("No asset sent for moonwell repay")
+		return handlers.Unexpected("No asset sent for moonwell repay")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -215,13 +230,16 @@ func handleMoonwellRepayBorrow(bundle handlers.TransactionBundle, client *evm.Cl
 		Type:         ctc_util.CTCLoanRepayment,
 		BaseCurrency: repaid.Asset.Symbol,
 		BaseAmount:   repaid.Value,
+		BaseAsset:    repaid.Asset,
 		From:         bundle.Info.From,
 		To:           "moonwell",
 		Description:  fmt.Sprintf("moonwell: repay %s", repaid),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleMoonwellRedeem(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -231,18 +249,18 @@ func handleMoonwellRedeem(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for moonwell redeem")
+		return handlers.Unexpected("Unexpected net transfers for moonwell redeem")
 	}
 
 	var withdrawn core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for moonwell redeem")
+			return handlers.Unexpected("Unexpected net transfers for moonwell redeem")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for moonwell redeem")
+				return handlers.Unexpected("Unexpected net transfers for moonwell redeem")
 			}
 			if amount.Value.IsPositive() {
 				withdrawn = *amount
@@ -251,7 +269,7 @@ func handleMoonwellRedeem(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if withdrawn.Asset.Symbol == "" {
-		panic("No asset withdrawn for moonwell redeem")
+		return handlers.Unexpected("No asset withdrawn for moonwell redeem")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -261,13 +279,16 @@ func handleMoonwellRedeem(bundle handlers.TransactionBundle, client *evm.Client,
 		Type:         ctc_util.CTCCollateralWithdrawal,
 		BaseCurrency: withdrawn.Asset.Symbol,
 		BaseAmount:   withdrawn.Value,
+		BaseAsset:    withdrawn.Asset,
 		From:         "moonwell",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("moonwell: withdraw %s", withdrawn),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleMoonwellStake(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -277,18 +298,18 @@ func handleMoonwellStake(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for moonwell stake")
+		return handlers.Unexpected("Unexpected net transfers for moonwell stake")
 	}
 
 	var staked core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for moonwell stake")
+			return handlers.Unexpected("Unexpected net transfers for moonwell stake")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for moonwell stake")
+				return handlers.Unexpected("Unexpected net transfers for moonwell stake")
 			}
 			if amount.Value.IsNegative() {
 				staked = amount.Neg()
@@ -297,7 +318,7 @@ func handleMoonwellStake(bundle handlers.TransactionBundle, client *evm.Client,
 	}
 
 	if staked.Asset.Symbol == "" {
-		panic("No asset sent for moonwell stake")
+		return handlers.Unexpected("No asset sent for moonwell stake")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -307,18 +328,21 @@ func handleMoonwellStake(bundle handlers.TransactionBundle, client *evm.Client,
 		Type:         ctc_util.CTCStakingDeposit,
 		BaseCurrency: staked.Asset.Symbol,
 		BaseAmount:   staked.Value,
+		BaseAsset:    staked.Asset,
 		From:         bundle.Info.From,
 		To:           "moonwell",
 		Description:  fmt.Sprintf("moonwell: stake %s", staked),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleMoonwellStakingCooldown(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
 	// This just starts a timer for unstaking, so the fee is the only thing that needs to be handled
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash,
@@ -326,8 +350,11 @@ func handleMoonwellStakingCooldown(bundle handlers.TransactionBundle, client *ev
 		"moonwell: start unstaking cooldown",
 		bundle.Receipt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func handleMoonwellStakingRedeem(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -337,18 +364,18 @@ func handleMoonwellStakingRedeem(bundle handlers.TransactionBundle, client *evm.
 	}
 
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for moonwell staking redeem")
+		return handlers.Unexpected("Unexpected net transfers for moonwell staking redeem")
 	}
 
 	var withdrawn core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for moonwell staking redeem")
+			return handlers.Unexpected("Unexpected net transfers for moonwell staking redeem")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for moonwell staking redeem")
+				return handlers.Unexpected("Unexpected net transfers for moonwell staking redeem")
 			}
 			if amount.Value.IsPositive() {
 				withdrawn = *amount
@@ -357,7 +384,7 @@ func handleMoonwellStakingRedeem(bundle handlers.TransactionBundle, client *evm.
 	}
 
 	if withdrawn.Asset.Symbol == "" {
-		panic("No asset withdrawn for moonwell staking redeem")
+		return handlers.Unexpected("No asset withdrawn for moonwell staking redeem")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -367,11 +394,14 @@ func handleMoonwellStakingRedeem(bundle handlers.TransactionBundle, client *evm.
 		Type:         ctc_util.CTCStakingWithdrawal,
 		BaseCurrency: withdrawn.Asset.Symbol,
 		BaseAmount:   withdrawn.Value,
+		BaseAsset:    withdrawn.Asset,
 		From:         "moonwell",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("moonwell: withdraw stake of %s", withdrawn),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
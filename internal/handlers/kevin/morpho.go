@@ -22,20 +22,20 @@ func handleMorphoClaimRewards(bundle handlers.TransactionBundle, client *evm.Cli
 	for _, transfers := range netTransfers {
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for morpho claim rewards")
+				return handlers.Unexpected("Unexpected net transfers for morpho claim rewards")
 			}
 			if !amount.Value.IsPositive() {
-				panic("Outflow for morpho claim rewards")
+				return handlers.Unexpected("Outflow for morpho claim rewards")
 			}
 			claimed = append(claimed, *amount)
 		}
 	}
 
 	if len(claimed) == 0 {
-		panic("Nothing claimed for morpho claim rewards")
+		return handlers.Unexpected("Nothing claimed for morpho claim rewards")
 	}
 
-	ctcTx := ctc_util.NewFeeTransaction(
+	ctcTx, err := ctc_util.NewFeeTransaction(
 		bundle.Block.Time,
 		bundle.Info.Network,
 		bundle.Info.Hash+"-1",
@@ -43,7 +43,10 @@ func handleMorphoClaimRewards(bundle handlers.TransactionBundle, client *evm.Cli
 		"Fee for Morpho rewards claim",
 		bundle.Receipt,
 	)
-	err = export(ctcTx.ToCSV())
+	if err != nil {
+		return err
+	}
+	err = export(*ctcTx)
 
 	for i, amount := range claimed {
 		ctcTx = &ctc_util.CTCTransaction{
@@ -53,11 +56,12 @@ func handleMorphoClaimRewards(bundle handlers.TransactionBundle, client *evm.Cli
 			Type:         ctc_util.CTCInterest,
 			BaseCurrency: amount.Asset.Symbol,
 			BaseAmount:   amount.Value,
+			BaseAsset:    amount.Asset,
 			From:         "unknown",
 			To:           bundle.Info.From,
 			Description:  fmt.Sprintf("morpho: claim %s in rewards", amount),
 		}
-		err = combineErrs(err, export(ctcTx.ToCSV()))
+		err = combineErrs(err, export(*ctcTx))
 	}
 
 	return err
@@ -0,0 +1,217 @@
+package kevin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/evm_util"
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+)
+
+var MARKETPLACE_METHODS = []string{
+	"0xfb0f3ee1", // seaport fulfillBasicOrder
+	"0x00000000", // seaport fulfillBasicOrder_efficient_6GL6yc
+	"0xb3a34c4c", // seaport fulfillOrder
+	"0xe7acab24", // seaport fulfillAdvancedOrder
+	"0x87201b41", // seaport fulfillAvailableAdvancedOrders
+	"0xab834bab", // wyvern atomicMatch_
+	"0x9a1fc3a7", // blur execute
+}
+
+var NFT_MINT_METHODS = []string{
+	"0x1249c58b", // mint()
+	"0xa0712d68", // mint(uint256)
+	"0x40c10f19", // mint(address,uint256)
+	"0x6a627842", // mint(address)
+}
+
+type nftMovement struct {
+	amount core.Amount
+	addr   common.Address
+	minted bool
+}
+
+func handleNftLabeled(label string) handlers.TransactionHandlerFunc {
+	return func(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
+		return handleNft(label, bundle, client, export)
+	}
+}
+
+// handleNft exports NFT buys, sells, mints and plain transfers. Any payment in
+// a fungible asset is split across the NFTs by quantity. Transactions that end
+// up moving no NFTs of mine are left to the one-off handler.
+func handleNft(label string, bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
+	allTransfers, err := evm_util.NetTransfersWithNfts(client, bundle.Info, bundle.Receipt.Logs)
+	if err != nil {
+		return err
+	}
+
+	minted := make(map[core.Asset]bool)
+	zeroAddress := common.HexToAddress(evm.ZERO_ADDRESS)
+	for asset, transfers := range allTransfers {
+		if amount, ok := transfers[zeroAddress]; ok && asset.IsNft() && amount.IsNegative() {
+			minted[asset] = true
+		}
+	}
+
+	nfts, fungible := allTransfers.OnlyMine().Nfts()
+	if len(nfts) == 0 {
+		return handleOneOff(bundle, client, export)
+	}
+
+	received := make([]nftMovement, 0)
+	sent := make([]nftMovement, 0)
+	for asset, transfers := range nfts {
+		for addr, amount := range transfers {
+			if amount.IsPositive() {
+				received = append(received, nftMovement{*amount, addr, minted[asset]})
+			} else {
+				sent = append(sent, nftMovement{amount.Neg(), addr, false})
+			}
+		}
+	}
+
+	var payment *core.Amount
+	if len(fungible) > 1 {
+		return handlers.Unexpected("Multiple fungible assets for " + label + " NFT transaction")
+	}
+	for _, transfers := range fungible {
+		if len(transfers) != 1 {
+			return handlers.Unexpected("Unexpected net transfers for " + label + " NFT transaction")
+		}
+		for _, amount := range transfers {
+			payment = amount
+		}
+	}
+
+	var ctcTxs []ctc_util.CTCTransaction
+
+	switch {
+	case len(received) > 0 && len(sent) > 0:
+		return handlers.Unexpected("NFTs both sent and received for " + label + " NFT transaction")
+	case len(received) > 0:
+		if payment != nil && payment.IsPositive() {
+			return handlers.Unexpected("Received both NFTs and payment for " + label + " NFT transaction")
+		}
+		ctcTxs = nftRows(label, bundle, received, payment, true)
+	default:
+		if payment != nil && payment.IsNegative() {
+			return handlers.Unexpected("Sent both NFTs and payment for " + label + " NFT transaction")
+		}
+		ctcTxs = nftRows(label, bundle, sent, payment, false)
+	}
+
+	if err := ctcTxs[0].AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
+
+	err = nil
+	for _, ctcTx := range ctcTxs {
+		err = combineErrs(err, export(ctcTx))
+	}
+
+	return err
+}
+
+func nftRows(
+	label string,
+	bundle handlers.TransactionBundle,
+	movements []nftMovement,
+	payment *core.Amount,
+	incoming bool,
+) []ctc_util.CTCTransaction {
+	sort.Slice(movements, func(i, j int) bool {
+		if movements[i].amount.Asset == movements[j].amount.Asset {
+			return movements[i].addr.Hex() < movements[j].addr.Hex()
+		}
+		return movements[i].amount.Asset.String() < movements[j].amount.Asset.String()
+	})
+
+	totalQuantity := decimal.Zero
+	for _, movement := range movements {
+		totalQuantity = totalQuantity.Add(movement.amount.Value)
+	}
+
+	var remaining core.Amount
+	if payment != nil {
+		remaining = payment.Abs()
+	}
+
+	ctcTxs := make([]ctc_util.CTCTransaction, 0, len(movements))
+	for i, movement := range movements {
+		id := bundle.Info.Hash
+		if len(movements) > 1 {
+			id = fmt.Sprintf("%s-%d", bundle.Info.Hash, i+1)
+		}
+
+		ctcTx := ctc_util.CTCTransaction{
+			Timestamp:    time.Unix(int64(bundle.Block.Time), 0).UTC(),
+			Blockchain:   bundle.Info.Network,
+			ID:           id,
+			BaseCurrency: nftCurrency(movement.amount.Asset),
+			BaseAmount:   movement.amount.Value,
+			BaseAsset:    movement.amount.Asset,
+		}
+
+		if incoming {
+			ctcTx.To = movement.addr.Hex()
+		} else {
+			ctcTx.From = movement.addr.Hex()
+		}
+
+		var share core.Amount
+		if payment != nil {
+			share = remaining
+			if i < len(movements)-1 {
+				share.Value = payment.Abs().Value.
+					Mul(movement.amount.Value).
+					Div(totalQuantity).
+					Truncate(int32(payment.Asset.Decimals))
+			}
+			remaining.Value = remaining.Value.Sub(share.Value)
+
+			ctcTx.QuoteCurrency = share.Asset.Symbol
+			ctcTx.QuoteAmount = share.Value
+			ctcTx.QuoteAsset = share.Asset
+		}
+
+		switch {
+		case incoming && movement.minted:
+			ctcTx.Type = ctc_util.CTCMint
+			ctcTx.Description = fmt.Sprintf("%s: mint %s", label, movement.amount)
+			if payment != nil {
+				ctcTx.Description += fmt.Sprintf(" for %s", share)
+			}
+		case incoming && payment != nil:
+			ctcTx.Type = ctc_util.CTCBuy
+			ctcTx.Description = fmt.Sprintf("%s: buy %s for %s", label, movement.amount, share)
+		case incoming:
+			ctcTx.Type = ctc_util.CTCReceive
+			ctcTx.Description = fmt.Sprintf("%s: receive %s", label, movement.amount)
+		case payment != nil:
+			ctcTx.Type = ctc_util.CTCSell
+			ctcTx.Description = fmt.Sprintf("%s: sell %s for %s", label, movement.amount, share)
+		default:
+			ctcTx.Type = ctc_util.CTCSend
+			ctcTx.Description = fmt.Sprintf("%s: send %s", label, movement.amount)
+		}
+
+		ctcTxs = append(ctcTxs, ctcTx)
+	}
+
+	return ctcTxs
+}
+
+// nftCurrency names an NFT for CTC by its collection symbol and token ID.
+func nftCurrency(asset core.Asset) string {
+	tokenID := asset.Identifier[strings.LastIndex(asset.Identifier, "/")+1:]
+	return fmt.Sprintf("%s #%s", asset.Symbol, tokenID)
+}
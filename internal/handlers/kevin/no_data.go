@@ -26,23 +26,23 @@ func handleNoData(bundle handlers.TransactionBundle, client *evm.Client, export
 	case types.DepositTxType:
 		return handleDepositTx(bundle, client, export)
 	case types.AccessListTxType:
-		panic("Access list transactions not implemented")
+		return handlers.Unexpected("Access list transactions not implemented")
 	case types.BlobTxType:
-		panic("Blob transactions not implemented")
+		return handlers.Unexpected("Blob transactions not implemented")
 	default:
-		panic(fmt.Sprintf("Unimplemented transaction type: %d\n", bundle.Tx.Type()))
+		return handlers.Unexpected(fmt.Sprintf("Unimplemented transaction type: %d\n", bundle.Tx.Type()))
 	}
 }
 
 func handleDepositTx(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
 	nativeAsset, err := client.NativeAsset()
 	if err != nil {
-		panic("No native asset found")
+		return handlers.Unexpected("No native asset found")
 	}
 
 	amount, err := nativeAsset.WithAtomicStringValue(bundle.Tx.Value().String())
 	if err != nil {
-		panic("Could not parse value")
+		return handlers.Unexpected("Could not parse value")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -52,23 +52,24 @@ func handleDepositTx(bundle handlers.TransactionBundle, client *evm.Client, expo
 		Type:         ctc_util.CTCBridgeIn,
 		BaseCurrency: nativeAsset.Symbol,
 		BaseAmount:   amount.Value,
+		BaseAsset:    nativeAsset,
 		From:         bundle.Info.From,
 		To:           bundle.Info.To,
 		Description:  fmt.Sprintf("bridge %s to %s", amount.String(), bundle.Info.Network),
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleRegularNoDataTx(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
 	nativeAsset, err := client.NativeAsset()
 	if err != nil {
-		panic("No native asset found")
+		return handlers.Unexpected("No native asset found")
 	}
 
 	amount, err := nativeAsset.WithAtomicStringValue(bundle.Tx.Value().String())
 	if err != nil {
-		panic("Could not parse value")
+		return handlers.Unexpected("Could not parse value")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -77,6 +78,7 @@ func handleRegularNoDataTx(bundle handlers.TransactionBundle, client *evm.Client
 		ID:           bundle.Info.Hash,
 		BaseCurrency: nativeAsset.Symbol,
 		BaseAmount:   amount.Value,
+		BaseAsset:    nativeAsset,
 		From:         bundle.Info.From,
 		To:           bundle.Info.To,
 		Description: fmt.Sprintf("transfer %s from %s to %s on %s",
@@ -87,7 +89,9 @@ func handleRegularNoDataTx(bundle handlers.TransactionBundle, client *evm.Client
 		),
 	}
 
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 	fromMe := config.Config.IsMyEvmAddressString(bundle.Info.From)
 	toMe := config.Config.IsMyEvmAddressString(bundle.Info.To)
 
@@ -97,7 +101,7 @@ func handleRegularNoDataTx(bundle handlers.TransactionBundle, client *evm.Client
 		ctcTx.Description = fmt.Sprintf("bridge %s to base", amount.String())
 	case bundle.Info.From == AVAX_OLD_BRIDGE || bundle.Info.From == AVAX_BITCOIN_BRIDGE:
 		if amount.Asset.Symbol != "AVAX" || amount.Asset.Kind != core.AssetKind("evm_native") {
-			panic("Non-airdrop transfer from avalanche bridge")
+			return handlers.Unexpected("Non-airdrop transfer from avalanche bridge")
 		}
 		ctcTx.Type = ctc_util.CTCIncome
 		ctcTx.Description = "airdrop from avalanche bridge"
@@ -114,16 +118,16 @@ func handleRegularNoDataTx(bundle handlers.TransactionBundle, client *evm.Client
 	case toMe:
 		ctcTx.Type = ctc_util.CTCReceive
 	default:
-		panic("Found irrelevant transaction, not from/to any of my addresses")
+		return handlers.Unexpected("Found irrelevant transaction, not from/to any of my addresses")
 	}
 
 	// Make sure sends have the other side as well
 	if toMe && fromMe {
 		if ctcTx.Type != ctc_util.CTCSend {
-			panic("native to me and from me but not send")
+			return handlers.Unexpected("native to me and from me but not send")
 		}
 		ctcTx.ID = bundle.Info.Hash + "-1"
-		err = export(ctcTx.ToCSV())
+		err = export(ctcTx)
 		if err != nil {
 			return err
 		}
@@ -131,9 +135,10 @@ func handleRegularNoDataTx(bundle handlers.TransactionBundle, client *evm.Client
 		ctcTx.ID = bundle.Info.Hash + "-2"
 		ctcTx.FeeAmount = decimal.Zero
 		ctcTx.FeeCurrency = ""
+		ctcTx.FeeAsset = core.Asset{}
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleBatchBridge(bundle handlers.TransactionBundle, client *evm.Client, ctcTx *ctc_util.CTCTransaction) error {
@@ -147,23 +152,24 @@ func handleBatchBridge(bundle handlers.TransactionBundle, client *evm.Client, ct
 	}
 
 	if len(netTransfers) == 0 {
-		panic("No net transfers to my addresses found in bridge transaction")
+		return handlers.Unexpected("No net transfers to my addresses found in bridge transaction")
 	}
 	if len(netTransfers) > 1 {
-		panic("Multiple assets transferred in bridge transaction")
+		return handlers.Unexpected("Multiple assets transferred in bridge transaction")
 	}
 
 	for _, transfers := range netTransfers {
 		if len(transfers) == 0 {
-			panic("No net transfers to my addresses found in bridge transaction")
+			return handlers.Unexpected("No net transfers to my addresses found in bridge transaction")
 		}
 		if len(transfers) > 1 {
-			panic("Multiple of my addresses had net transfers in bridge transaction")
+			return handlers.Unexpected("Multiple of my addresses had net transfers in bridge transaction")
 		}
 		for addr, amount := range transfers {
 			ctcTx.Type = ctc_util.CTCBridgeIn
 			ctcTx.BaseCurrency = amount.Asset.Symbol
 			ctcTx.BaseAmount = amount.Value
+			ctcTx.BaseAsset = amount.Asset
 			ctcTx.From = addr.Hex()
 			ctcTx.To = addr.Hex()
 			ctcTx.Description = fmt.Sprintf("bridge %s to %s on %s",
@@ -19,21 +19,21 @@ func handlePolygonBridgeOut(bundle handlers.TransactionBundle, client *evm.Clien
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for polygon bridge out")
+		return handlers.Unexpected("Unexpected net transfers for polygon bridge out")
 	}
 
 	var bridged core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for polygon bridge out")
+			return handlers.Unexpected("Unexpected net transfers for polygon bridge out")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for polygon bridge out")
+				return handlers.Unexpected("Unexpected net transfers for polygon bridge out")
 			}
 			if !amount.Value.IsNegative() {
-				panic("Unexpected net transfers for polygon bridge out")
+				return handlers.Unexpected("Unexpected net transfers for polygon bridge out")
 			}
 			bridged = amount.Neg()
 		}
@@ -46,13 +46,16 @@ func handlePolygonBridgeOut(bundle handlers.TransactionBundle, client *evm.Clien
 		Type:         ctc_util.CTCBridgeOut,
 		BaseCurrency: bridged.Asset.Symbol,
 		BaseAmount:   bridged.Value,
+		BaseAsset:    bridged.Asset,
 		From:         bundle.Info.From,
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("bridge %s to polygon", bridged),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handlePolygonBridgeIn(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -62,7 +65,7 @@ func handlePolygonBridgeIn(bundle handlers.TransactionBundle, client *evm.Client
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for polygon bridge in")
+		return handlers.Unexpected("Unexpected net transfers for polygon bridge in")
 	}
 
 	var bridged core.Amount
@@ -70,11 +73,11 @@ func handlePolygonBridgeIn(bundle handlers.TransactionBundle, client *evm.Client
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for polygon bridge in")
+			return handlers.Unexpected("Unexpected net transfers for polygon bridge in")
 		}
 		for addr, amount := range transfers {
 			if !amount.Value.IsPositive() {
-				panic("Unexpected net transfers for polygon bridge in")
+				return handlers.Unexpected("Unexpected net transfers for polygon bridge in")
 			}
 			bridged = *amount
 			bridgedTo = addr
@@ -88,11 +91,14 @@ func handlePolygonBridgeIn(bundle handlers.TransactionBundle, client *evm.Client
 		Type:         ctc_util.CTCBridgeIn,
 		BaseCurrency: bridged.Asset.Symbol,
 		BaseAmount:   bridged.Value,
+		BaseAsset:    bridged.Asset,
 		From:         bridgedTo.Hex(),
 		To:           bridgedTo.Hex(),
 		Description:  fmt.Sprintf("bridge %s from polygon", bridged),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
@@ -0,0 +1,355 @@
+package kevin
+
+import (
+	"fmt"
+
+	"github.com/ksmithbaylor/gohodl/internal/abis"
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+	"golang.org/x/exp/slices"
+)
+
+var REGISTRY = handlers.NewRegistry()
+
+var failed = handlers.Condition{
+	Description: "transaction failed",
+	Check: func(info *evm.TxInfo) bool {
+		return !info.Success
+	},
+}
+
+var noData = handlers.Condition{
+	Description: "no calldata",
+	Check: func(info *evm.TxInfo) bool {
+		return info.Method == ""
+	},
+}
+
+var notFromMe = handlers.Condition{
+	Description: "not sent from one of my addresses",
+	Check: func(info *evm.TxInfo) bool {
+		return !config.Config.IsMyEvmAddressString(info.From)
+	},
+}
+
+var toWrappedNative = handlers.Condition{
+	Description: "to a wrapped native contract",
+	Check: func(info *evm.TxInfo) bool {
+		return slices.Contains(WRAPPED_NATIVE_CONTRACTS, fmt.Sprintf("%s-%s", info.Network, info.To))
+	},
+}
+
+func init() {
+	REGISTRY.Register(
+		handlers.Matcher{
+			Name:       "failed",
+			Conditions: []handlers.Condition{failed},
+			Priority:   handlers.PRIORITY_FIRST,
+			Handle:     handleFailed,
+		},
+		handlers.Matcher{
+			Name:       "no data",
+			Conditions: []handlers.Condition{noData},
+			Priority:   handlers.PRIORITY_FIRST - 1,
+			Handle:     handleNoData,
+		},
+		handlers.Matcher{
+			Name:     "spam contract",
+			To:       spamContracts,
+			Priority: handlers.PRIORITY_SPAM,
+		},
+		handlers.Matcher{
+			Name:       "spam method (verified)",
+			Methods:    spamMethods,
+			Conditions: []handlers.Condition{notFromMe},
+			Since:      SPAM_VERIFIED.From,
+			Until:      SPAM_VERIFIED.To,
+			Priority:   handlers.PRIORITY_SPAM,
+		},
+		handlers.Matcher{
+			Name:       "spam method (needs review)",
+			Methods:    spamMethods,
+			Conditions: []handlers.Condition{notFromMe},
+			Priority:   handlers.PRIORITY_SPAM - 1,
+			Handle:     handleSpamReview,
+		},
+	)
+
+	REGISTRY.Register(
+		handlers.Matcher{
+			Name:    "erc20 approve",
+			Methods: []string{abis.ERC20_APPROVE},
+			Handle:  handleErc20Approve,
+		},
+		handlers.Matcher{
+			Name:    "erc20 transfer",
+			Methods: []string{abis.ERC20_TRANSFER, abis.ERC20_TRANSFER_FROM},
+			Handle:  handleErc20Transfer,
+		},
+		handlers.Matcher{
+			Name: "coinbase bulk withdraw",
+			Methods: []string{
+				"0x1a1da075",
+				"0xca350aa6",
+			},
+			Handle: handleBulkWithdrawFrom("coinbase"),
+		},
+		handlers.Matcher{
+			Name: "uniswap swap",
+			Methods: []string{
+				abis.UNISWAP_V2_SWAP_EXACT_TOKENS_FOR_TOKENS,
+				abis.UNISWAP_V2_SWAP_TOKENS_FOR_EXACT_TOKENS,
+				abis.UNISWAP_V2_SWAP_EXACT_ETH_FOR_TOKENS,
+				abis.UNISWAP_V2_SWAP_TOKENS_FOR_EXACT_ETH,
+				abis.UNISWAP_V2_SWAP_EXACT_TOKENS_FOR_ETH,
+				abis.UNISWAP_V2_SWAP_ETH_FOR_EXACT_TOKENS,
+				abis.UNISWAP_UNIVERSAL_EXECUTE,
+				abis.UNISWAP_UNIVERSAL_EXECUTE_0,
+			},
+			Handle: handleTokenSwapLabeled("uniswap"),
+		},
+		handlers.Matcher{
+			Name: "aave supply",
+			Methods: []string{
+				abis.AAVE_SUPPLY,
+				"0x474cf53d", // depositETH(address,address,uint16)
+			},
+			Handle: handleAaveSupply,
+		},
+		handlers.Matcher{
+			Name:    "aave deposit",
+			Methods: []string{abis.AAVE_DEPOSIT},
+			Handle:  handleAaveDeposit,
+		},
+		handlers.Matcher{
+			Name:    "aave borrow",
+			Methods: []string{abis.AAVE_BORROW},
+			Handle:  handleAaveBorrow,
+		},
+		handlers.Matcher{
+			Name: "aave repay",
+			Methods: []string{
+				abis.AAVE_REPAY,
+				"0x02c5fcf8", // repayETH(address,uint256,uint256,address)
+			},
+			Handle: handleAaveRepay,
+		},
+		handlers.Matcher{
+			Name:    "aave repay with atokens",
+			Methods: []string{abis.AAVE_REPAY_WITH_A_TOKENS},
+			Handle:  handleAaveRepayWithATokens,
+		},
+		handlers.Matcher{
+			Name:    "aave set user e-mode",
+			Methods: []string{abis.AAVE_SET_USER_E_MODE},
+			Handle:  handleAaveSetUserEMode,
+		},
+		handlers.Matcher{
+			Name: "aave withdraw",
+			Methods: []string{
+				abis.AAVE_WITHDRAW,
+				"0x80500d20", // withdrawETH(address,uint256,address)
+			},
+			Handle: handleAaveWithdraw,
+		},
+		handlers.Matcher{
+			Name: "aave claim rewards",
+			Methods: []string{
+				abis.AAVE_CLAIM_REWARDS,
+				abis.AAVE_CLAIM_ALL_REWARDS,
+				"0x3111e7b3", // claimRewards(address[],uint256,address)
+			},
+			Handle: handleAaveClaimRewards,
+		},
+		handlers.Matcher{
+			Name:    "moonwell enter markets",
+			Methods: []string{abis.MOONWELL_ENTER_MARKETS},
+			Handle:  handleMoonwellEnterMarkets,
+		},
+		handlers.Matcher{
+			Name: "moonwell claim reward",
+			Methods: []string{
+				abis.MOONWELL_CLAIM_REWARD,
+				abis.MOONWELL_CLAIM_REWARD_0,
+				abis.MOONWELL_STAKING_CLAIM,
+			},
+			Handle: handleMoonwellClaimReward,
+		},
+		handlers.Matcher{
+			Name:     "moonwell mint",
+			Methods:  []string{abis.MOONWELL_MINT, abis.MOONWELL_NATIVE_MINT},
+			Networks: []string{"moon*"},
+			Handle:   handleMoonwellMint,
+		},
+		handlers.Matcher{
+			Name:     "moonwell mint",
+			Methods:  []string{"0x6a627842"},
+			Networks: []string{"base"},
+			Handle:   handleMoonwellMint,
+		},
+		handlers.Matcher{
+			Name:     "moonwell borrow",
+			Methods:  []string{abis.MOONWELL_BORROW},
+			Networks: []string{"moon*"},
+			Handle:   handleMoonwellBorrow,
+		},
+		handlers.Matcher{
+			Name:     "moonwell borrow",
+			Methods:  []string{"0xc5ebeaec"},
+			Networks: []string{"base"},
+			Handle:   handleMoonwellBorrow,
+		},
+		handlers.Matcher{
+			Name: "moonwell repay borrow",
+			Methods: []string{
+				abis.MOONWELL_REPAY_BORROW,
+				"0x4e4d9fea", // repayBorrow()
+			},
+			Networks: []string{"moon*"},
+			Handle:   handleMoonwellRepayBorrow,
+		},
+		handlers.Matcher{
+			Name:     "moonwell repay borrow",
+			Methods:  []string{"0x0e752702"}, // repayBorrow(uint256)
+			Networks: []string{"base*"},
+			Handle:   handleMoonwellRepayBorrow,
+		},
+		handlers.Matcher{
+			Name:     "moonwell redeem",
+			Methods:  []string{abis.MOONWELL_REDEEM},
+			Networks: []string{"moon*"},
+			Handle:   handleMoonwellRedeem,
+		},
+		handlers.Matcher{
+			Name:     "moonwell redeem",
+			Methods:  []string{"0xdb006a75", "0x7bde82f2"},
+			Networks: []string{"base"},
+			Handle:   handleMoonwellRedeem,
+		},
+		handlers.Matcher{
+			Name: "nft transfer",
+			Methods: []string{
+				abis.ERC721_SAFE_TRANSFER_FROM,
+				abis.ERC721_SAFE_TRANSFER_FROM_0,
+				abis.ERC1155_SAFE_TRANSFER_FROM,
+				abis.ERC1155_SAFE_BATCH_TRANSFER_FROM,
+			},
+			Handle: handleNftLabeled("nft transfer"),
+		},
+		handlers.Matcher{
+			Name:    "nft marketplace",
+			Methods: MARKETPLACE_METHODS,
+			Handle:  handleNftLabeled("nft marketplace"),
+		},
+		handlers.Matcher{
+			Name:    "nft mint",
+			Methods: NFT_MINT_METHODS,
+			Handle:  handleNftLabeled("nft mint"),
+		},
+		handlers.Matcher{
+			Name:    "moonwell stake",
+			Methods: []string{abis.MOONWELL_STAKING_STAKE},
+			Handle:  handleMoonwellStake,
+		},
+		handlers.Matcher{
+			Name:    "moonwell staking cooldown",
+			Methods: []string{abis.MOONWELL_STAKING_COOLDOWN},
+			Handle:  handleMoonwellStakingCooldown,
+		},
+		handlers.Matcher{
+			Name:    "moonwell staking redeem",
+			Methods: []string{abis.MOONWELL_STAKING_REDEEM},
+			Handle:  handleMoonwellStakingRedeem,
+		},
+		handlers.Matcher{
+			Name:       "wrap or unwrap native",
+			Methods:    []string{abis.WRAPPED_NATIVE_DEPOSIT, abis.WRAPPED_NATIVE_WITHDRAW},
+			Conditions: []handlers.Condition{toWrappedNative},
+			Handle:     handleTokenSwapLabeled("wrapped native"),
+		},
+		handlers.Matcher{
+			Name: "uniswap add liquidity",
+			Methods: []string{
+				abis.UNISWAP_V2_ADD_LIQUIDITY,
+				abis.UNISWAP_V2_ADD_LIQUIDITY_ETH,
+			},
+			Handle: handleUniswapAddLiquidity,
+		},
+		handlers.Matcher{
+			Name: "uniswap remove liquidity",
+			Methods: []string{
+				abis.UNISWAP_V2_REMOVE_LIQUIDITY_ETH,
+				abis.UNISWAP_V2_REMOVE_LIQUIDITY_PERMIT,
+				abis.UNISWAP_V2_REMOVE_LIQUIDITY_ETH_PERMIT,
+				abis.UNISWAP_V2_REMOVE_LIQUIDITY_ETH_PERMIT_FOTT,
+			},
+			Handle: handleUniswapRemoveLiquidity,
+		},
+		handlers.Matcher{
+			Name:    "curve swap",
+			Methods: []string{"0x65b2489b"},
+			Handle:  handleTokenSwapLabeled("curve"),
+		},
+		handlers.Matcher{
+			Name:    "kyberswap swap",
+			Methods: []string{"0xe21fd0e9"},
+			Handle:  handleTokenSwapLabeled("kyberswap"),
+		},
+		handlers.Matcher{
+			Name:    "rainbow swap",
+			Methods: []string{"0x999b6464"},
+			Handle:  handleTokenSwapLabeled("rainbow"),
+		},
+		handlers.Matcher{
+			Name:    "1inch swap",
+			Methods: []string{"0x12aa3caf"},
+			Handle:  handleTokenSwapLabeled("1inch"),
+		},
+		handlers.Matcher{
+			Name:    "0x swap",
+			Methods: []string{"0x415565b0"},
+			Handle:  handleTokenSwapLabeled("0x"),
+		},
+		handlers.Matcher{
+			Name:    "friend.tech buy",
+			Methods: []string{abis.FRIEND_TECH_BUY_SHARES},
+			Handle:  handleFriendTechBuy,
+		},
+		handlers.Matcher{
+			Name:    "friend.tech sell",
+			Methods: []string{abis.FRIEND_TECH_SELL_SHARES},
+			Handle:  handleFriendTechSell,
+		},
+		handlers.Matcher{
+			Name:    "XEN Crypto reward",
+			Methods: []string{"0x52c7f8dc"},
+			Handle:  handleRewardWithLabel("XEN Crypto"),
+		},
+		handlers.Matcher{
+			Name:    "moonwell governance vote",
+			Methods: []string{"0x56781388"},
+			Handle:  handleMiscWithLabel("moonwell governance vote"),
+		},
+		handlers.Matcher{
+			Name:    "misc reward",
+			Methods: []string{"0x2046d075"},
+			Handle:  handleRewardWithLabel("misc reward"),
+		},
+	)
+
+	REGISTRY.Register(
+		handlers.Matcher{
+			Name:     "one-off",
+			Priority: handlers.PRIORITY_FALLBACK,
+			Handle:   handleOneOff,
+		},
+	)
+}
+
+// Spam outside of the verified window is opened in the explorer, and left
+// unhandled until it has been checked
+func handleSpamReview(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
+	client.OpenTransactionInExplorer(bundle.Info.Hash, true)
+	return NEEDS_REVIEW
+}
@@ -0,0 +1,143 @@
+package kevin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/abis"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/ksmithbaylor/gohodl/internal/handlers/kevin"
+	"github.com/stretchr/testify/assert"
+)
+
+const SOMEONE_ELSE = "0x000000000000000000000000000000000000dEaD"
+
+var MID_2024 = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func call(network, to, method string, t time.Time) *evm.TxInfo {
+	return &evm.TxInfo{
+		Network: network,
+		From:    SOMEONE_ELSE,
+		To:      to,
+		Method:  method,
+		Time:    int(t.Unix()),
+		Success: true,
+	}
+}
+
+func matcherNamed(t *testing.T, name string) handlers.Matcher {
+	for _, matcher := range kevin.REGISTRY.Matchers() {
+		if matcher.Name == name {
+			return matcher
+		}
+	}
+	t.Fatalf("No matcher named %s", name)
+	return handlers.Matcher{}
+}
+
+func matchedName(info *evm.TxInfo) string {
+	matcher, found := kevin.REGISTRY.Match(info)
+	if !found {
+		return ""
+	}
+	return matcher.Name
+}
+
+// Registry tests
+
+// The order the handlers are tried in, which is the order of the cases in the
+// switch they replaced. Some selectors are shared (like NFT mints with other
+// protocols), so moving a handler changes which one wins.
+func TestRegistryOrder(t *testing.T) {
+	names := make([]string, 0)
+	for _, matcher := range kevin.REGISTRY.Matchers() {
+		if len(names) == 0 || names[len(names)-1] != matcher.Name {
+			names = append(names, matcher.Name)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"failed",
+		"no data",
+		"spam contract",
+		"spam method (verified)",
+		"spam method (needs review)",
+		"erc20 approve",
+		"erc20 transfer",
+		"coinbase bulk withdraw",
+		"uniswap swap",
+		"aave supply",
+		"aave deposit",
+		"aave borrow",
+		"aave repay",
+		"aave repay with atokens",
+		"aave set user e-mode",
+		"aave withdraw",
+		"aave claim rewards",
+		"moonwell enter markets",
+		"moonwell claim reward",
+		"moonwell mint",
+		"moonwell borrow",
+		"moonwell repay borrow",
+		"moonwell redeem",
+		"nft transfer",
+		"nft marketplace",
+		"nft mint",
+		"moonwell stake",
+		"moonwell staking cooldown",
+		"moonwell staking redeem",
+		"wrap or unwrap native",
+		"uniswap add liquidity",
+		"uniswap remove liquidity",
+		"curve swap",
+		"kyberswap swap",
+		"rainbow swap",
+		"1inch swap",
+		"0x swap",
+		"friend.tech buy",
+		"friend.tech sell",
+		"XEN Crypto reward",
+		"moonwell governance vote",
+		"misc reward",
+		"one-off",
+	}, names)
+}
+
+func TestRegistryMatchesLikeTheSwitch(t *testing.T) {
+	failedNoData := call("ethereum", SOMEONE_ELSE, "", MID_2024)
+	failedNoData.Success = false
+	assert.Equal(t, "failed", matchedName(failedNoData))
+	assert.Equal(t, "no data", matchedName(call("ethereum", SOMEONE_ELSE, "", MID_2024)))
+
+	spamContract := matcherNamed(t, "spam contract").To[0]
+	assert.Equal(t, "spam contract", matchedName(call("ethereum", spamContract, abis.ERC20_TRANSFER, MID_2024)))
+
+	spamMethod := matcherNamed(t, "spam method (verified)").Methods[0]
+	assert.Equal(t, "spam method (verified)", matchedName(call("ethereum", SOMEONE_ELSE, spamMethod, MID_2024)))
+	assert.Equal(t, "spam method (needs review)", matchedName(call("ethereum", SOMEONE_ELSE, spamMethod, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))))
+
+	// Moonwell selectors only count on the networks they were deployed to
+	assert.Equal(t, "moonwell mint", matchedName(call("base", SOMEONE_ELSE, "0x6a627842", MID_2024)))
+	assert.NotEqual(t, "moonwell mint", matchedName(call("ethereum", SOMEONE_ELSE, "0x6a627842", MID_2024)))
+	assert.Equal(t, "moonwell repay borrow", matchedName(call("base-sepolia", SOMEONE_ELSE, "0x0e752702", MID_2024)))
+
+	// Wrapping only counts when calling the wrapped native contract itself
+	weth := "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	assert.Equal(t, "wrap or unwrap native", matchedName(call("ethereum", weth, abis.WRAPPED_NATIVE_DEPOSIT, MID_2024)))
+	assert.NotEqual(t, "wrap or unwrap native", matchedName(call("base", weth, abis.WRAPPED_NATIVE_DEPOSIT, MID_2024)))
+
+	// NFT mints come before the handlers that were after them in the switch
+	position := make(map[string]int)
+	for i, matcher := range kevin.REGISTRY.Matchers() {
+		if _, found := position[matcher.Name]; !found {
+			position[matcher.Name] = i
+		}
+	}
+	for _, method := range matcherNamed(t, "nft mint").Methods {
+		name := matchedName(call("ethereum", SOMEONE_ELSE, method, MID_2024))
+		assert.LessOrEqual(t, position[name], position["nft mint"], "%s is handled by %s", method, name)
+	}
+
+	assert.Equal(t, "one-off", matchedName(call("ethereum", SOMEONE_ELSE, "0x12345678", MID_2024)))
+}
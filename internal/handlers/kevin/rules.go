@@ -0,0 +1,71 @@
+package kevin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ksmithbaylor/gohodl/internal/config"
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+)
+
+// The generic handlers that rules can use, by the name given in rules.yml
+var RULE_HANDLERS = map[string]func(label string) handlers.TransactionHandlerFunc{
+	"swap":          handleTokenSwapLabeled,
+	"misc":          handleMiscWithLabel,
+	"reward":        handleRewardWithLabel,
+	"nft":           handleNftLabeled,
+	"bulk_withdraw": handleBulkWithdrawFrom,
+}
+
+var fromMe = handlers.Condition{
+	Description: "sent from one of my addresses",
+	Check: func(info *evm.TxInfo) bool {
+		return config.Config.IsMyEvmAddressString(info.From)
+	},
+}
+
+// LoadRules reads the rules file at the given path and registers each rule
+// with the generic handler it names.
+func LoadRules(path string) error {
+	rules, err := handlers.LoadRules(path)
+	if err != nil {
+		return err
+	}
+
+	matchers := make([]handlers.Matcher, 0, len(rules))
+	for _, rule := range rules {
+		handler, found := RULE_HANDLERS[rule.Handler]
+		if !found {
+			return fmt.Errorf(
+				"Rule %s in %s has unknown handler '%s', expected one of %s",
+				rule.Name, path, rule.Handler, ruleHandlerNames(),
+			)
+		}
+
+		conditions := make([]handlers.Condition, 0)
+		if rule.FromMine != nil {
+			if *rule.FromMine {
+				conditions = append(conditions, fromMe)
+			} else {
+				conditions = append(conditions, notFromMe)
+			}
+		}
+
+		matchers = append(matchers, rule.Matcher(handler(rule.Label), conditions...))
+	}
+
+	REGISTRY.Register(matchers...)
+
+	return nil
+}
+
+func ruleHandlerNames() string {
+	names := make([]string, 0, len(RULE_HANDLERS))
+	for name := range RULE_HANDLERS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
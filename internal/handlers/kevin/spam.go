@@ -83,7 +83,9 @@ func handleSpam(bundle handlers.TransactionBundle, client *evm.Client, export ha
 		Type:        ctc_util.CTCSpam,
 		Description: "spam transaction",
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
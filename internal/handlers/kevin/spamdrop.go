@@ -20,11 +20,11 @@ func handleSpamDrop(bundle handlers.TransactionBundle, client *evm.Client, expor
 	}
 
 	if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-		panic("I sent spamdrop?")
+		return handlers.Unexpected("I sent spamdrop?")
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for spamdrop")
+		return handlers.Unexpected("Unexpected net transfers for spamdrop")
 	}
 
 	var to string
@@ -32,14 +32,14 @@ func handleSpamDrop(bundle handlers.TransactionBundle, client *evm.Client, expor
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for spamdrop")
+			return handlers.Unexpected("Unexpected net transfers for spamdrop")
 		}
 		for addr, amount := range transfers {
 			if !config.Config.IsMyEvmAddress(addr) {
-				panic("Irrelevant transfer for spamdrop")
+				return handlers.Unexpected("Irrelevant transfer for spamdrop")
 			}
 			if amount.Value.IsNegative() {
-				panic("Negative transfer for spamdrop")
+				return handlers.Unexpected("Negative transfer for spamdrop")
 			}
 			to = addr.Hex()
 			received = amount
@@ -47,7 +47,7 @@ func handleSpamDrop(bundle handlers.TransactionBundle, client *evm.Client, expor
 	}
 
 	if to == "" || received == nil {
-		panic("Nothing received for spamdrop")
+		return handlers.Unexpected("Nothing received for spamdrop")
 	}
 
 	tiny, err := decimal.NewFromString("0.005")
@@ -57,7 +57,7 @@ func handleSpamDrop(bundle handlers.TransactionBundle, client *evm.Client, expor
 	price := tiny.Div(received.Value)
 
 	if price.String() == "0" {
-		panic("zero price for spamdrop")
+		return handlers.Unexpected("zero price for spamdrop")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -74,5 +74,5 @@ func handleSpamDrop(bundle handlers.TransactionBundle, client *evm.Client, expor
 		Description:            fmt.Sprintf("spamdrop: %s received %s", to, received),
 	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
@@ -34,7 +34,7 @@ func handleTokenSwap(
 	}
 
 	if len(netTransfers) != 2 {
-		panic("Unexpected net transfers for swap")
+		return handlers.Unexpected("Unexpected net transfers for swap")
 	}
 
 	var bought *core.Amount
@@ -42,31 +42,31 @@ func handleTokenSwap(
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for swap")
+			return handlers.Unexpected("Unexpected net transfers for swap")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From && !strings.Contains(label, "gas swap") && label != "solarflare" && label != "cowswap" && label != "0x" {
-				panic("Swap recipient not the transaction sender")
+				return handlers.Unexpected("Swap recipient not the transaction sender")
 			}
 			if amount.Value.IsNegative() {
 				if sold != nil {
-					panic("Multiple assets sold for swap")
+					return handlers.Unexpected("Multiple assets sold for swap")
 				}
 				outflow := amount.Neg()
 				sold = &outflow
 			} else if amount.Value.IsPositive() {
 				if bought != nil {
-					panic("Multiple assets bought for swap")
+					return handlers.Unexpected("Multiple assets bought for swap")
 				}
 				bought = amount
 			} else {
-				panic("Zero-value transfer for swap")
+				return handlers.Unexpected("Zero-value transfer for swap")
 			}
 		}
 	}
 
 	if bought == nil || sold == nil {
-		panic("Confusing asset state for swap")
+		return handlers.Unexpected("Confusing asset state for swap")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -76,13 +76,17 @@ func handleTokenSwap(
 		Type:          ctc_util.CTCSell,
 		BaseCurrency:  sold.Asset.Symbol,
 		BaseAmount:    sold.Value,
+		BaseAsset:     sold.Asset,
 		QuoteCurrency: bought.Asset.Symbol,
 		QuoteAmount:   bought.Value,
+		QuoteAsset:    bought.Asset,
 		From:          bundle.Info.From,
 		To:            bundle.Info.To,
 		Description:   fmt.Sprintf("%s: sell %s for %s", label, sold, bought),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
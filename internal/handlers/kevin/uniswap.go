@@ -18,7 +18,7 @@ func handleUniswapAddLiquidity(bundle handlers.TransactionBundle, client *evm.Cl
 	}
 
 	if len(netTransfers) != 3 {
-		panic("Unexpected net transfers for uniswap liquidity add")
+		return handlers.Unexpected("Unexpected net transfers for uniswap liquidity add")
 	}
 
 	var tokenA *core.Amount
@@ -27,15 +27,15 @@ func handleUniswapAddLiquidity(bundle handlers.TransactionBundle, client *evm.Cl
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for uniswap liquidity add")
+			return handlers.Unexpected("Unexpected net transfers for uniswap liquidity add")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for uniswap liquidity add")
+				return handlers.Unexpected("Unexpected net transfers for uniswap liquidity add")
 			}
 			if amount.Value.IsPositive() {
 				if lpToken != nil {
-					panic("Multiple token inflows for uniswap liquidity add")
+					return handlers.Unexpected("Multiple token inflows for uniswap liquidity add")
 				}
 				lpToken = amount
 			} else if amount.Value.IsNegative() {
@@ -45,27 +45,32 @@ func handleUniswapAddLiquidity(bundle handlers.TransactionBundle, client *evm.Cl
 				} else if tokenB == nil {
 					tokenB = &outflow
 				} else {
-					panic("More than two tokens provided for uniswap liquidity add")
+					return handlers.Unexpected("More than two tokens provided for uniswap liquidity add")
 				}
 			} else {
-				panic("Zero-value transfer for uniswap liquidity add")
+				return handlers.Unexpected("Zero-value transfer for uniswap liquidity add")
 			}
 		}
 	}
 
 	if tokenA == nil || tokenB == nil || lpToken == nil {
-		panic("Confusing asset state for uniswap liquidity add")
+		return handlers.Unexpected("Confusing asset state for uniswap liquidity add")
+	}
+
+	feeTx, err := ctc_util.NewFeeTransaction(
+		bundle.Block.Time,
+		bundle.Info.Network,
+		bundle.Info.Hash+"-1",
+		bundle.Info.From,
+		"uniswap add liquidity: transaction fee",
+		bundle.Receipt,
+	)
+	if err != nil {
+		return err
 	}
 
 	ctcTxs := []ctc_util.CTCTransaction{
-		*ctc_util.NewFeeTransaction(
-			bundle.Block.Time,
-			bundle.Info.Network,
-			bundle.Info.Hash+"-1",
-			bundle.Info.From,
-			"uniswap add liquidity: transaction fee",
-			bundle.Receipt,
-		),
+		*feeTx,
 		{
 			Timestamp:    time.Unix(int64(bundle.Block.Time), 0).UTC(),
 			Blockchain:   bundle.Info.Network,
@@ -73,6 +78,7 @@ func handleUniswapAddLiquidity(bundle handlers.TransactionBundle, client *evm.Cl
 			Type:         ctc_util.CTCAddLiquidity,
 			BaseCurrency: tokenA.Asset.Symbol,
 			BaseAmount:   tokenA.Value,
+			BaseAsset:    tokenA.Asset,
 			Description:  fmt.Sprintf("uniswap add liquidity: deposit %s", tokenA),
 		},
 		{
@@ -82,6 +88,7 @@ func handleUniswapAddLiquidity(bundle handlers.TransactionBundle, client *evm.Cl
 			Type:         ctc_util.CTCAddLiquidity,
 			BaseCurrency: tokenB.Asset.Symbol,
 			BaseAmount:   tokenB.Value,
+			BaseAsset:    tokenB.Asset,
 			Description:  fmt.Sprintf("uniswap add liquidity: deposit %s", tokenB),
 		},
 		{
@@ -91,13 +98,14 @@ func handleUniswapAddLiquidity(bundle handlers.TransactionBundle, client *evm.Cl
 			Type:         ctc_util.CTCReceiveLPToken,
 			BaseCurrency: fmt.Sprintf("%s-%s", lpToken.Asset.Identifier, lpToken.Asset.Symbol),
 			BaseAmount:   lpToken.Value,
+			BaseAsset:    lpToken.Asset,
 			Description:  fmt.Sprintf("uniswap add liquidity: receive lp token %s", lpToken),
 		},
 	}
 
 	err = nil
 	for _, ctcTx := range ctcTxs {
-		err = combineErrs(err, export(ctcTx.ToCSV()))
+		err = combineErrs(err, export(ctcTx))
 	}
 
 	return err
@@ -114,7 +122,7 @@ func handleUniswapRemoveLiquidity(bundle handlers.TransactionBundle, client *evm
 	}
 
 	if len(netTransfers) != 3 {
-		panic("Unexpected net transfers for uniswap liquidity remove")
+		return handlers.Unexpected("Unexpected net transfers for uniswap liquidity remove")
 	}
 
 	var tokenA *core.Amount
@@ -123,15 +131,15 @@ func handleUniswapRemoveLiquidity(bundle handlers.TransactionBundle, client *evm
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for uniswap liquidity remove")
+			return handlers.Unexpected("Unexpected net transfers for uniswap liquidity remove")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for uniswap liquidity remove")
+				return handlers.Unexpected("Unexpected net transfers for uniswap liquidity remove")
 			}
 			if amount.Value.IsNegative() {
 				if lpToken != nil {
-					panic("Multiple token outflows for uniswap liquidity remove")
+					return handlers.Unexpected("Multiple token outflows for uniswap liquidity remove")
 				}
 				outflow := amount.Neg()
 				lpToken = &outflow
@@ -141,27 +149,32 @@ func handleUniswapRemoveLiquidity(bundle handlers.TransactionBundle, client *evm
 				} else if tokenB == nil {
 					tokenB = amount
 				} else {
-					panic("More than two tokens received for uniswap liquidity remove")
+					return handlers.Unexpected("More than two tokens received for uniswap liquidity remove")
 				}
 			} else {
-				panic("Zero-value transfer for uniswap liquidity remove")
+				return handlers.Unexpected("Zero-value transfer for uniswap liquidity remove")
 			}
 		}
 	}
 
 	if tokenA == nil || tokenB == nil || lpToken == nil {
-		panic("Confusing asset state for uniswap liquidity remove")
+		return handlers.Unexpected("Confusing asset state for uniswap liquidity remove")
+	}
+
+	feeTx, err := ctc_util.NewFeeTransaction(
+		bundle.Block.Time,
+		bundle.Info.Network,
+		bundle.Info.Hash+"-1",
+		bundle.Info.From,
+		"uniswap remove liquidity: transaction fee",
+		bundle.Receipt,
+	)
+	if err != nil {
+		return err
 	}
 
 	ctcTxs := []ctc_util.CTCTransaction{
-		*ctc_util.NewFeeTransaction(
-			bundle.Block.Time,
-			bundle.Info.Network,
-			bundle.Info.Hash+"-1",
-			bundle.Info.From,
-			"uniswap remove liquidity: transaction fee",
-			bundle.Receipt,
-		),
+		*feeTx,
 		{
 			Timestamp:    time.Unix(int64(bundle.Block.Time), 0).UTC(),
 			Blockchain:   bundle.Info.Network,
@@ -169,6 +182,7 @@ func handleUniswapRemoveLiquidity(bundle handlers.TransactionBundle, client *evm
 			Type:         ctc_util.CTCRemoveLiquidity,
 			BaseCurrency: tokenA.Asset.Symbol,
 			BaseAmount:   tokenA.Value,
+			BaseAsset:    tokenA.Asset,
 			Description:  fmt.Sprintf("uniswap remove liquidity: receive %s", tokenA),
 		},
 		{
@@ -178,6 +192,7 @@ func handleUniswapRemoveLiquidity(bundle handlers.TransactionBundle, client *evm
 			Type:         ctc_util.CTCRemoveLiquidity,
 			BaseCurrency: tokenB.Asset.Symbol,
 			BaseAmount:   tokenB.Value,
+			BaseAsset:    tokenB.Asset,
 			Description:  fmt.Sprintf("uniswap remove liquidity: receive %s", tokenB),
 		},
 		{
@@ -187,13 +202,14 @@ func handleUniswapRemoveLiquidity(bundle handlers.TransactionBundle, client *evm
 			Type:         ctc_util.CTCReturnLPToken,
 			BaseCurrency: fmt.Sprintf("%s-%s", lpToken.Asset.Identifier, lpToken.Asset.Symbol),
 			BaseAmount:   lpToken.Value,
+			BaseAsset:    lpToken.Asset,
 			Description:  fmt.Sprintf("uniswap remove liquidity: burn lp token %s", lpToken),
 		},
 	}
 
 	err = nil
 	for _, ctcTx := range ctcTxs {
-		err = combineErrs(err, export(ctcTx.ToCSV()))
+		err = combineErrs(err, export(ctcTx))
 	}
 
 	return err
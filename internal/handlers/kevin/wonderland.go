@@ -23,18 +23,18 @@ func handleWonderlandDeposit(bundle handlers.TransactionBundle, client *evm.Clie
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for wonderland deposit")
+		return handlers.Unexpected("Unexpected net transfers for wonderland deposit")
 	}
 
 	var deposited core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for wonderland deposit")
+			return handlers.Unexpected("Unexpected net transfers for wonderland deposit")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for wonderland deposit")
+				return handlers.Unexpected("Unexpected net transfers for wonderland deposit")
 			}
 			if amount.Value.IsNegative() {
 				deposited = amount.Neg()
@@ -43,7 +43,7 @@ func handleWonderlandDeposit(bundle handlers.TransactionBundle, client *evm.Clie
 	}
 
 	if deposited.Asset.Symbol == "" {
-		panic("No asset deposited for wonderland deposit")
+		return handlers.Unexpected("No asset deposited for wonderland deposit")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -53,13 +53,16 @@ func handleWonderlandDeposit(bundle handlers.TransactionBundle, client *evm.Clie
 		Type:         ctc_util.CTCCollateralDeposit,
 		BaseCurrency: deposited.Asset.Symbol,
 		BaseAmount:   deposited.Value,
+		BaseAsset:    deposited.Asset,
 		From:         bundle.Info.From,
 		To:           "wonderland",
 		Description:  fmt.Sprintf("wonderland: deposit %s", deposited),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleWonderlandRedeem(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -69,18 +72,18 @@ func handleWonderlandRedeem(bundle handlers.TransactionBundle, client *evm.Clien
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for wonderland redeem/bond")
+		return handlers.Unexpected("Unexpected net transfers for wonderland redeem/bond")
 	}
 
 	var borrowed core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for wonderland redeem/bond")
+			return handlers.Unexpected("Unexpected net transfers for wonderland redeem/bond")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for wonderland redeem/bond")
+				return handlers.Unexpected("Unexpected net transfers for wonderland redeem/bond")
 			}
 			if amount.Value.IsPositive() {
 				borrowed = *amount
@@ -89,7 +92,7 @@ func handleWonderlandRedeem(bundle handlers.TransactionBundle, client *evm.Clien
 	}
 
 	if borrowed.Asset.Symbol == "" {
-		panic("No asset received for wonderland redeem/bond")
+		return handlers.Unexpected("No asset received for wonderland redeem/bond")
 	}
 
 	ctcTx := ctc_util.CTCTransaction{
@@ -99,11 +102,14 @@ func handleWonderlandRedeem(bundle handlers.TransactionBundle, client *evm.Clien
 		Type:         ctc_util.CTCBorrow,
 		BaseCurrency: borrowed.Asset.Symbol,
 		BaseAmount:   borrowed.Value,
+		BaseAsset:    borrowed.Asset,
 		From:         "wonderland",
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("wonderland: redeem/bond %s", borrowed),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
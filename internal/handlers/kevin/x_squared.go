@@ -25,7 +25,7 @@ func handleXSquaredBuyItem(bundle handlers.TransactionBundle, client *evm.Client
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for xsquared buy")
+		return handlers.Unexpected("Unexpected net transfers for xsquared buy")
 	}
 
 	var received core.Amount
@@ -34,23 +34,23 @@ func handleXSquaredBuyItem(bundle handlers.TransactionBundle, client *evm.Client
 
 	for asset, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for xsquared buy")
+			return handlers.Unexpected("Unexpected net transfers for xsquared buy")
 		}
 		if asset.Kind != core.EvmNative {
-			panic("Non-native asset transfer for xsquared buy")
+			return handlers.Unexpected("Non-native asset transfer for xsquared buy")
 		}
 		for addr, amount := range transfers {
 			if amount.Value.IsNegative() {
 				if addr.Hex() != bundle.Info.From {
-					panic("Unexpected net transfers for xsquared buy")
+					return handlers.Unexpected("Unexpected net transfers for xsquared buy")
 				}
 				if received.Asset.Symbol != "" {
-					panic("Buy and receive in same xsquared buy transaction")
+					return handlers.Unexpected("Buy and receive in same xsquared buy transaction")
 				}
 				paid = amount.Neg()
 			} else {
 				if paid.Asset.Symbol != "" {
-					panic("Buy and receive in same xsquared buy transaction")
+					return handlers.Unexpected("Buy and receive in same xsquared buy transaction")
 				}
 				received = *amount
 				receivedTo = addr
@@ -66,16 +66,17 @@ func handleXSquaredBuyItem(bundle handlers.TransactionBundle, client *evm.Client
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: received.Asset.Symbol,
 			BaseAmount:   received.Value,
+			BaseAsset:    received.Asset,
 			From:         "xsquared",
 			To:           receivedTo.Hex(),
 			Description:  "xsquared: someone bought my item",
 		}
 
 		if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-			panic("Bought my own item for xsquared buy")
+			return handlers.Unexpected("Bought my own item for xsquared buy")
 		}
 
-		return export(ctcTx.ToCSV())
+		return export(*ctcTx)
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.XSquaredAbi)
@@ -84,31 +85,31 @@ func handleXSquaredBuyItem(bundle handlers.TransactionBundle, client *evm.Client
 	}
 
 	if len(events) != 1 {
-		panic("Unexpected events for xsquared buy")
+		return handlers.Unexpected("Unexpected events for xsquared buy")
 	}
 
 	event := events[0]
 
 	isBuy := event.Data["isBuy"].(bool)
 	if !isBuy {
-		panic("Sell event for xsquared buy")
+		return handlers.Unexpected("Sell event for xsquared buy")
 	}
 
 	quantityString := event.Data["quantity"].(*big.Int).String()
 	quantity, err := decimal.NewFromString(quantityString)
 	if err != nil {
-		panic("Invalid quantity string for xsquared buy")
+		return handlers.Unexpected("Invalid quantity string for xsquared buy")
 	}
 
 	collectionBytes, ok := event.Data["collection"].([32]uint8)
 	if !ok {
-		panic("Invalid collection for xsquared buy")
+		return handlers.Unexpected("Invalid collection for xsquared buy")
 	}
 	collection := common.Bytes2Hex(collectionBytes[:])
 
 	itemBytes, ok := event.Data["item"].([32]uint8)
 	if !ok {
-		panic("Invalid item for xsquared buy")
+		return handlers.Unexpected("Invalid item for xsquared buy")
 	}
 	item := common.Bytes2Hex(itemBytes[:])
 
@@ -123,6 +124,7 @@ func handleXSquaredBuyItem(bundle handlers.TransactionBundle, client *evm.Client
 		BaseAmount:    quantity,
 		QuoteCurrency: paid.Asset.Symbol,
 		QuoteAmount:   paid.Value,
+		QuoteAsset:    paid.Asset,
 		From:          "xsquared",
 		To:            bundle.Info.From,
 		Description: fmt.Sprintf("xsquared: bought %s %s with %s",
@@ -131,9 +133,11 @@ func handleXSquaredBuyItem(bundle handlers.TransactionBundle, client *evm.Client
 			paid,
 		),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -143,7 +147,7 @@ func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Clien
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for xsquared sell")
+		return handlers.Unexpected("Unexpected net transfers for xsquared sell")
 	}
 
 	var received core.Amount
@@ -152,23 +156,23 @@ func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Clien
 
 	for asset, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for xsquared sell")
+			return handlers.Unexpected("Unexpected net transfers for xsquared sell")
 		}
 		if asset.Kind != core.EvmNative {
-			panic("Non-native asset transfer for xsquared sell")
+			return handlers.Unexpected("Non-native asset transfer for xsquared sell")
 		}
 		for addr, amount := range transfers {
 			if !amount.Value.IsPositive() {
-				panic("Unexpected outflow for xsquared sell")
+				return handlers.Unexpected("Unexpected outflow for xsquared sell")
 			}
 			if addr.Hex() == bundle.Info.From {
 				if received.Asset.Symbol != "" {
-					panic("Sell and receive in same xsquared sell transaction")
+					return handlers.Unexpected("Sell and receive in same xsquared sell transaction")
 				}
 				saleProceeds = *amount
 			} else {
 				if saleProceeds.Asset.Symbol != "" {
-					panic("Sell and receive in same xsquared sell transaction")
+					return handlers.Unexpected("Sell and receive in same xsquared sell transaction")
 				}
 				received = *amount
 				receivedTo = addr
@@ -184,16 +188,17 @@ func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Clien
 			Type:         ctc_util.CTCIncome,
 			BaseCurrency: received.Asset.Symbol,
 			BaseAmount:   received.Value,
+			BaseAsset:    received.Asset,
 			From:         "xsquared",
 			To:           receivedTo.Hex(),
 			Description:  "xsquared: someone sold my item",
 		}
 
 		if config.Config.IsMyEvmAddressString(bundle.Info.From) {
-			panic("Sold my own item for xsquared sell")
+			return handlers.Unexpected("Sold my own item for xsquared sell")
 		}
 
-		return export(ctcTx.ToCSV())
+		return export(*ctcTx)
 	}
 
 	events, err := evm.ParseKnownEvents(bundle.Info.Network, bundle.Receipt.Logs, abis.XSquaredAbi)
@@ -202,31 +207,31 @@ func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Clien
 	}
 
 	if len(events) != 1 {
-		panic("Unexpected events for xsquared sell")
+		return handlers.Unexpected("Unexpected events for xsquared sell")
 	}
 
 	event := events[0]
 
 	isBuy := event.Data["isBuy"].(bool)
 	if isBuy {
-		panic("Buy event for xsquared sell")
+		return handlers.Unexpected("Buy event for xsquared sell")
 	}
 
 	quantityString := event.Data["quantity"].(*big.Int).String()
 	quantity, err := decimal.NewFromString(quantityString)
 	if err != nil {
-		panic("Invalid quantity string for xsquared sell")
+		return handlers.Unexpected("Invalid quantity string for xsquared sell")
 	}
 
 	collectionBytes, ok := event.Data["collection"].([32]uint8)
 	if !ok {
-		panic("Invalid collection for xsquared sell")
+		return handlers.Unexpected("Invalid collection for xsquared sell")
 	}
 	collection := common.Bytes2Hex(collectionBytes[:])
 
 	itemBytes, ok := event.Data["item"].([32]uint8)
 	if !ok {
-		panic("Invalid item for xsquared sell")
+		return handlers.Unexpected("Invalid item for xsquared sell")
 	}
 	item := common.Bytes2Hex(itemBytes[:])
 
@@ -241,6 +246,7 @@ func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Clien
 		BaseAmount:    quantity,
 		QuoteCurrency: saleProceeds.Asset.Symbol,
 		QuoteAmount:   saleProceeds.Value,
+		QuoteAsset:    saleProceeds.Asset,
 		From:          "xsquared",
 		To:            bundle.Info.From,
 		Description: fmt.Sprintf("xsquared: sold %s %s for %s",
@@ -249,9 +255,11 @@ func handleXSquaredSellItem(bundle handlers.TransactionBundle, client *evm.Clien
 			saleProceeds,
 		),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(*ctcTx)
 }
 
 func xsquaredDescription(collection, item string) string {
@@ -19,21 +19,21 @@ func handleXpollinateBridgeOut(bundle handlers.TransactionBundle, client *evm.Cl
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for xpollinate bridge out")
+		return handlers.Unexpected("Unexpected net transfers for xpollinate bridge out")
 	}
 
 	var bridged core.Amount
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for xpollinate bridge out")
+			return handlers.Unexpected("Unexpected net transfers for xpollinate bridge out")
 		}
 		for addr, amount := range transfers {
 			if addr.Hex() != bundle.Info.From {
-				panic("Unexpected net transfers for xpollinate bridge out")
+				return handlers.Unexpected("Unexpected net transfers for xpollinate bridge out")
 			}
 			if !amount.Value.IsNegative() {
-				panic("Unexpected net transfers for xpollinate bridge out")
+				return handlers.Unexpected("Unexpected net transfers for xpollinate bridge out")
 			}
 			bridged = amount.Neg()
 		}
@@ -46,13 +46,16 @@ func handleXpollinateBridgeOut(bundle handlers.TransactionBundle, client *evm.Cl
 		Type:         ctc_util.CTCBridgeOut,
 		BaseCurrency: bridged.Asset.Symbol,
 		BaseAmount:   bridged.Value,
+		BaseAsset:    bridged.Asset,
 		From:         bundle.Info.From,
 		To:           bundle.Info.From,
 		Description:  fmt.Sprintf("xpollinate: bridge out %s", bridged),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
 
 func handleXpollinateBridgeIn(bundle handlers.TransactionBundle, client *evm.Client, export handlers.CTCWriter) error {
@@ -62,7 +65,7 @@ func handleXpollinateBridgeIn(bundle handlers.TransactionBundle, client *evm.Cli
 	}
 
 	if len(netTransfers) != 1 {
-		panic("Unexpected net transfers for xpollinate bridge in")
+		return handlers.Unexpected("Unexpected net transfers for xpollinate bridge in")
 	}
 
 	var bridged core.Amount
@@ -70,11 +73,11 @@ func handleXpollinateBridgeIn(bundle handlers.TransactionBundle, client *evm.Cli
 
 	for _, transfers := range netTransfers {
 		if len(transfers) != 1 {
-			panic("Unexpected net transfers for xpollinate bridge in")
+			return handlers.Unexpected("Unexpected net transfers for xpollinate bridge in")
 		}
 		for addr, amount := range transfers {
 			if !amount.Value.IsPositive() {
-				panic("Unexpected net transfers for xpollinate bridge in")
+				return handlers.Unexpected("Unexpected net transfers for xpollinate bridge in")
 			}
 			bridged = *amount
 			bridgedTo = addr
@@ -88,11 +91,14 @@ func handleXpollinateBridgeIn(bundle handlers.TransactionBundle, client *evm.Cli
 		Type:         ctc_util.CTCBridgeIn,
 		BaseCurrency: bridged.Asset.Symbol,
 		BaseAmount:   bridged.Value,
+		BaseAsset:    bridged.Asset,
 		From:         bridgedTo.Hex(),
 		To:           bridgedTo.Hex(),
 		Description:  fmt.Sprintf("xpollinate: bridge in %s", bridged),
 	}
-	ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt)
+	if err := ctcTx.AddTransactionFeeIfMine(bundle.Info.From, bundle.Info.Network, bundle.Receipt); err != nil {
+		return err
+	}
 
-	return export(ctcTx.ToCSV())
+	return export(ctcTx)
 }
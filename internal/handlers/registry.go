@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+)
+
+// Priorities for the usual kinds of matchers. Anything in between works too.
+const (
+	PRIORITY_FIRST    = 100  // Checked before anything else, like failed transactions
+	PRIORITY_SPAM     = 50   // Spam that would otherwise look like something real
+	PRIORITY_DEFAULT  = 0    // Specific protocols and contracts
+	PRIORITY_GENERIC  = -10  // Catch-alls by common selector
+	PRIORITY_FALLBACK = -100 // Whatever is left
+)
+
+// A Condition is any extra check a Matcher needs, described for Explain.
+type Condition struct {
+	Description string
+	Check       func(info *evm.TxInfo) bool
+}
+
+// A Matcher picks out the transactions a handler applies to. Every non-empty
+// criterion must match. A nil Handle marks matching transactions as handled
+// without exporting anything (like spam).
+type Matcher struct {
+	Name       string      // Shown when explaining matches
+	Methods    []string    // Method selectors, like 0xa9059cbb
+	To         []string    // Contract addresses called, compared case-insensitively
+	Networks   []string    // Network names, or prefixes ending in * (like moon*)
+	Since      time.Time   // Only transactions at or after this time
+	Until      time.Time   // Only transactions before this time
+	Conditions []Condition // Anything else
+	Priority   int         // Higher priorities are checked first
+	Handle     TransactionHandlerFunc
+}
+
+// An Explanation is the result of checking one Matcher against a transaction.
+type Explanation struct {
+	Name     string
+	Priority int
+	Matched  bool
+	Chosen   bool     // The first match, which handles the transaction
+	Reasons  []string // One per criterion checked, in order
+}
+
+func (e Explanation) String() string {
+	status := "no match"
+	if e.Chosen {
+		status = "CHOSEN"
+	} else if e.Matched {
+		status = "match (shadowed)"
+	}
+
+	s := fmt.Sprintf("[%d] %s: %s", e.Priority, e.Name, status)
+	for _, reason := range e.Reasons {
+		s += "\n    " + reason
+	}
+	return s
+}
+
+// A Registry finds the handler for a transaction from the registered matchers,
+// by priority and then in the order they were registered.
+type Registry struct {
+	matchers []Matcher
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		matchers: make([]Matcher, 0),
+	}
+}
+
+func (r *Registry) Register(matchers ...Matcher) {
+	r.matchers = append(r.matchers, matchers...)
+
+	sort.SliceStable(r.matchers, func(i, j int) bool {
+		return r.matchers[i].Priority > r.matchers[j].Priority
+	})
+}
+
+func (r *Registry) Matchers() []Matcher {
+	return slices.Clone(r.matchers)
+}
+
+// Match returns the matcher that handles the transaction, if any.
+func (r *Registry) Match(info *evm.TxInfo) (Matcher, bool) {
+	for _, matcher := range r.matchers {
+		if matched, _ := matcher.check(info, false); matched {
+			return matcher, true
+		}
+	}
+
+	return Matcher{}, false
+}
+
+// Explain checks every matcher against the transaction, in the order Match
+// does, and says why each one did or didn't match.
+func (r *Registry) Explain(info *evm.TxInfo) []Explanation {
+	explanations := make([]Explanation, 0, len(r.matchers))
+	chosen := false
+
+	for _, matcher := range r.matchers {
+		matched, reasons := matcher.check(info, true)
+		explanations = append(explanations, Explanation{
+			Name:     matcher.Name,
+			Priority: matcher.Priority,
+			Matched:  matched,
+			Chosen:   matched && !chosen,
+			Reasons:  reasons,
+		})
+		chosen = chosen || matched
+	}
+
+	return explanations
+}
+
+// check returns whether the matcher applies to the transaction. If explaining,
+// every criterion is checked and described, otherwise it stops at the first
+// one that fails.
+func (m Matcher) check(info *evm.TxInfo, explain bool) (bool, []string) {
+	matched := true
+	reasons := make([]string, 0)
+
+	note := func(ok bool, format string, a ...any) bool {
+		if explain {
+			mark := "✓"
+			if !ok {
+				mark = "✗"
+			}
+			reasons = append(reasons, mark+" "+fmt.Sprintf(format, a...))
+		}
+		matched = matched && ok
+		return matched || explain
+	}
+
+	if len(m.Methods) > 0 {
+		ok := slices.Contains(m.Methods, info.Method)
+		if !note(ok, "method %s in %s", info.Method, summarize(m.Methods)) {
+			return false, reasons
+		}
+	}
+
+	if len(m.To) > 0 {
+		ok := slices.ContainsFunc(m.To, func(to string) bool {
+			return strings.EqualFold(to, info.To)
+		})
+		if !note(ok, "to %s in %s", info.To, summarize(m.To)) {
+			return false, reasons
+		}
+	}
+
+	if len(m.Networks) > 0 {
+		ok := slices.ContainsFunc(m.Networks, func(network string) bool {
+			if prefix, isPrefix := strings.CutSuffix(network, "*"); isPrefix {
+				return strings.HasPrefix(info.Network, prefix)
+			}
+			return network == info.Network
+		})
+		if !note(ok, "network %s in %s", info.Network, summarize(m.Networks)) {
+			return false, reasons
+		}
+	}
+
+	txTime := time.Unix(int64(info.Time), 0).UTC()
+
+	if !m.Since.IsZero() {
+		ok := !txTime.Before(m.Since)
+		if !note(ok, "time %s at or after %s", txTime.Format(time.RFC3339), m.Since.Format(time.RFC3339)) {
+			return false, reasons
+		}
+	}
+
+	if !m.Until.IsZero() {
+		ok := txTime.Before(m.Until)
+		if !note(ok, "time %s before %s", txTime.Format(time.RFC3339), m.Until.Format(time.RFC3339)) {
+			return false, reasons
+		}
+	}
+
+	for _, condition := range m.Conditions {
+		if !note(condition.Check(info), "%s", condition.Description) {
+			return false, reasons
+		}
+	}
+
+	if explain && len(reasons) == 0 {
+		reasons = append(reasons, "✓ matches everything")
+	}
+
+	return matched, reasons
+}
+
+// summarize lists a few items, so long lists of selectors or contracts don't
+// drown out the rest of an explanation.
+func summarize(items []string) string {
+	if len(items) <= 4 {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s, ... (%d total)", strings.Join(items[:3], ", "), len(items))
+}
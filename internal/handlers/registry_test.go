@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/evm"
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func tx(network, to, method string, t time.Time) *evm.TxInfo {
+	return &evm.TxInfo{
+		Network: network,
+		To:      to,
+		Method:  method,
+		Time:    int(t.Unix()),
+		Success: true,
+	}
+}
+
+var JAN_2024 = time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+// Match tests
+
+func TestMatchByPriority(t *testing.T) {
+	registry := handlers.NewRegistry()
+	registry.Register(
+		handlers.Matcher{Name: "fallback", Priority: handlers.PRIORITY_FALLBACK},
+		handlers.Matcher{Name: "transfer", Methods: []string{"0xa9059cbb"}},
+		handlers.Matcher{Name: "spam", To: []string{"0xABCD"}, Priority: handlers.PRIORITY_SPAM},
+	)
+
+	matcher, found := registry.Match(tx("ethereum", "0xabcd", "0xa9059cbb", JAN_2024))
+	assert.True(t, found)
+	assert.Equal(t, "spam", matcher.Name)
+
+	matcher, found = registry.Match(tx("ethereum", "0x1234", "0xa9059cbb", JAN_2024))
+	assert.True(t, found)
+	assert.Equal(t, "transfer", matcher.Name)
+
+	matcher, found = registry.Match(tx("ethereum", "0x1234", "0x095ea7b3", JAN_2024))
+	assert.True(t, found)
+	assert.Equal(t, "fallback", matcher.Name)
+}
+
+func TestMatchInRegistrationOrder(t *testing.T) {
+	registry := handlers.NewRegistry()
+	registry.Register(handlers.Matcher{Name: "first", Methods: []string{"0xa9059cbb"}})
+	registry.Register(handlers.Matcher{Name: "second", Methods: []string{"0xa9059cbb"}})
+
+	matcher, _ := registry.Match(tx("ethereum", "0x1234", "0xa9059cbb", JAN_2024))
+	assert.Equal(t, "first", matcher.Name)
+}
+
+func TestMatchByNetwork(t *testing.T) {
+	registry := handlers.NewRegistry()
+	registry.Register(
+		handlers.Matcher{Name: "moonwell", Networks: []string{"moon*"}},
+		handlers.Matcher{Name: "base", Networks: []string{"base"}},
+	)
+
+	matcher, _ := registry.Match(tx("moonbeam", "0x1234", "0xa0712d68", JAN_2024))
+	assert.Equal(t, "moonwell", matcher.Name)
+
+	matcher, _ = registry.Match(tx("base", "0x1234", "0xa0712d68", JAN_2024))
+	assert.Equal(t, "base", matcher.Name)
+
+	_, found := registry.Match(tx("base-sepolia", "0x1234", "0xa0712d68", JAN_2024))
+	assert.False(t, found)
+}
+
+func TestMatchByTime(t *testing.T) {
+	registry := handlers.NewRegistry()
+	registry.Register(handlers.Matcher{
+		Name:  "2024",
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	_, found := registry.Match(tx("ethereum", "0x1234", "", JAN_2024))
+	assert.True(t, found)
+
+	_, found = registry.Match(tx("ethereum", "0x1234", "", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, found)
+}
+
+func TestMatchByCondition(t *testing.T) {
+	registry := handlers.NewRegistry()
+	registry.Register(handlers.Matcher{
+		Name: "failed",
+		Conditions: []handlers.Condition{{
+			Description: "transaction failed",
+			Check:       func(info *evm.TxInfo) bool { return !info.Success },
+		}},
+	})
+
+	info := tx("ethereum", "0x1234", "", JAN_2024)
+	_, found := registry.Match(info)
+	assert.False(t, found)
+
+	info.Success = false
+	_, found = registry.Match(info)
+	assert.True(t, found)
+}
+
+// Explain tests
+
+func TestExplain(t *testing.T) {
+	registry := handlers.NewRegistry()
+	registry.Register(
+		handlers.Matcher{Name: "approve", Methods: []string{"0x095ea7b3"}},
+		handlers.Matcher{Name: "transfer", Methods: []string{"0xa9059cbb"}},
+		handlers.Matcher{Name: "fallback", Priority: handlers.PRIORITY_FALLBACK},
+	)
+
+	explanations := registry.Explain(tx("ethereum", "0x1234", "0xa9059cbb", JAN_2024))
+	assert.Len(t, explanations, 3)
+
+	assert.Equal(t, "approve", explanations[0].Name)
+	assert.False(t, explanations[0].Matched)
+	assert.Equal(t, []string{"✗ method 0xa9059cbb in 0x095ea7b3"}, explanations[0].Reasons)
+
+	assert.True(t, explanations[1].Matched)
+	assert.True(t, explanations[1].Chosen)
+
+	assert.True(t, explanations[2].Matched)
+	assert.False(t, explanations[2].Chosen)
+	assert.Contains(t, explanations[2].String(), "shadowed")
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var SELECTOR_PATTERN = regexp.MustCompile("^0x[0-9a-f]{8}$")
+
+// A Rule classifies simple transactions with one of the generic handlers,
+// without writing any code. See rules.yml for the format.
+type Rule struct {
+	Name     string   `yaml:"name"`      // Shown when explaining matches (default the label)
+	Networks []string `yaml:"networks"`  // Network names, or prefixes ending in * (like moon*)
+	To       []string `yaml:"to"`        // Contract addresses called
+	Methods  []string `yaml:"methods"`   // Method selectors, like 0x65b2489b
+	FromMine *bool    `yaml:"from_mine"` // Whether one of my addresses sent it (default either)
+	Handler  string   `yaml:"handler"`   // Which generic handler to use, like swap
+	Label    string   `yaml:"label"`     // Passed to the handler, like curve
+	Priority int      `yaml:"priority"`  // Default PRIORITY_DEFAULT, after the built-in handlers
+}
+
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read rules %s: %w", path, err)
+	}
+
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid rules %s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+func ParseRules(data []byte) ([]Rule, error) {
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range file.Rules {
+		err := rule.validate()
+		if err != nil {
+			return nil, fmt.Errorf("Rule %d (%s): %w", i+1, rule.Name, err)
+		}
+		if rule.Name == "" {
+			file.Rules[i].Name = rule.Label
+		}
+	}
+
+	return file.Rules, nil
+}
+
+func (r Rule) validate() error {
+	if r.Handler == "" {
+		return fmt.Errorf("Missing handler")
+	}
+	if r.Label == "" {
+		return fmt.Errorf("Missing label")
+	}
+
+	// Without these, a rule would match every transaction on its networks
+	if len(r.Methods) == 0 && len(r.To) == 0 {
+		return fmt.Errorf("Needs at least one method or to address")
+	}
+
+	for _, method := range r.Methods {
+		if !SELECTOR_PATTERN.MatchString(method) {
+			return fmt.Errorf("Invalid method '%s', expected a lowercase selector like 0x65b2489b", method)
+		}
+	}
+
+	return nil
+}
+
+// Matcher makes a Matcher for the rule, using the given handler. The caller
+// is responsible for checking FromMine with a condition, since it depends on
+// the configured addresses.
+func (r Rule) Matcher(handle TransactionHandlerFunc, conditions ...Condition) Matcher {
+	return Matcher{
+		Name:       "rule: " + r.Name,
+		Methods:    r.Methods,
+		To:         r.To,
+		Networks:   r.Networks,
+		Conditions: conditions,
+		Priority:   r.Priority,
+		Handle:     handle,
+	}
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+// ParseRules tests
+
+func TestParseRules(t *testing.T) {
+	rules, err := handlers.ParseRules([]byte(`
+rules:
+  - label: curve
+    handler: swap
+    methods: [0x65b2489b]
+  - name: governance
+    label: moonwell governance vote
+    handler: misc
+    networks: [moon*, base]
+    methods: [0x56781388]
+    from_mine: true
+    priority: 10
+`))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	assert.Equal(t, "curve", rules[0].Name)
+	assert.Nil(t, rules[0].FromMine)
+
+	assert.Equal(t, "governance", rules[1].Name)
+	assert.Equal(t, []string{"moon*", "base"}, rules[1].Networks)
+	assert.True(t, *rules[1].FromMine)
+
+	matcher := rules[1].Matcher(nil)
+	assert.Equal(t, "rule: governance", matcher.Name)
+	assert.Equal(t, 10, matcher.Priority)
+
+	registry := handlers.NewRegistry()
+	registry.Register(matcher)
+	_, found := registry.Match(tx("base", "0x1234", "0x56781388", JAN_2024))
+	assert.True(t, found)
+	_, found = registry.Match(tx("ethereum", "0x1234", "0x56781388", JAN_2024))
+	assert.False(t, found)
+}
+
+func TestParseInvalidRules(t *testing.T) {
+	_, err := handlers.ParseRules([]byte(`
+rules:
+  - label: curve
+    methods: [0x65b2489b]
+`))
+	assert.ErrorContains(t, err, "Missing handler")
+
+	_, err = handlers.ParseRules([]byte(`
+rules:
+  - label: everything
+    handler: misc
+    networks: [base]
+`))
+	assert.ErrorContains(t, err, "at least one method")
+
+	_, err = handlers.ParseRules([]byte(`
+rules:
+  - label: curve
+    handler: swap
+    methods: [0x65B2489B]
+`))
+	assert.ErrorContains(t, err, "Invalid method")
+}
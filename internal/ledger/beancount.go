@@ -0,0 +1,85 @@
+package ledger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// beancountExporter writes a Beancount journal. Acquisitions with a known
+// price are held at cost, so disposals of them book against those lots.
+type beancountExporter struct {
+	books Books
+}
+
+func (beancountExporter) Name() string {
+	return "beancount"
+}
+
+func (beancountExporter) Extension() string {
+	return "beancount"
+}
+
+func (e beancountExporter) Write(w io.Writer, entries []Entry) error {
+	txs := e.books.journal(entries)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "option \"operating_currency\" \"%s\"\n\n", e.books.fiat())
+
+	// Every account has to be opened before it's used
+	opened := make(map[string]time.Time)
+	for _, tx := range txs {
+		for _, p := range tx.Postings {
+			if _, found := opened[p.Account]; !found {
+				opened[p.Account] = tx.Date
+			}
+		}
+	}
+	accounts := make([]string, 0, len(opened))
+	for account := range opened {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		fmt.Fprintf(&out, "%s open %s\n", opened[account].Format("2006-01-02"), account)
+	}
+
+	for _, tx := range txs {
+		narration := tx.Entry.Description
+		if narration == "" {
+			narration = string(tx.Entry.Kind)
+		}
+
+		fmt.Fprintf(&out, "\n%s * %s\n", tx.Date.Format("2006-01-02"), strconv.Quote(narration))
+		fmt.Fprintf(&out, "  id: %s\n", strconv.Quote(tx.Entry.ID))
+		fmt.Fprintf(&out, "  kind: %s\n", strconv.Quote(string(tx.Entry.Kind)))
+		if tx.Entry.Network != "" {
+			fmt.Fprintf(&out, "  network: %s\n", strconv.Quote(tx.Entry.Network))
+		}
+
+		for _, p := range tx.Postings {
+			if p.Amount.IsZero() {
+				fmt.Fprintf(&out, "  %s\n", p.Account)
+				continue
+			}
+
+			amount := fmt.Sprintf("%s %s", p.Amount.Value.String(), commodity(p.Amount.Currency))
+			switch {
+			case !p.Cost.IsZero():
+				amount += fmt.Sprintf(" {%s %s}", p.Cost.String(), e.books.fiat())
+			case p.Reduce:
+				amount += " {}"
+			}
+			if !p.Price.IsZero() {
+				amount += fmt.Sprintf(" @ %s %s", p.Price.String(), e.books.fiat())
+			}
+			fmt.Fprintf(&out, "  %s  %s\n", p.Account, amount)
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
@@ -0,0 +1,109 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CoinTracking's CSV import format
+var COINTRACKING_HEADERS = []string{
+	"Type",
+	"Buy Amount",
+	"Buy Currency",
+	"Sell Amount",
+	"Sell Currency",
+	"Fee",
+	"Fee Currency",
+	"Exchange",
+	"Trade-Group",
+	"Comment",
+	"Date",
+	"Tx-ID",
+}
+
+// CoinTracking's types for kinds that aren't plain deposits, withdrawals or
+// trades
+var COINTRACKING_TYPES = map[Kind]string{
+	Fee:            "Other Fee",
+	Approval:       "Other Fee",
+	Expense:        "Spend",
+	PersonalUse:    "Spend",
+	Stolen:         "Stolen",
+	Lost:           "Lost",
+	Burn:           "Lost",
+	Income:         "Income",
+	Royalty:        "Income",
+	Interest:       "Interest Income",
+	Mining:         "Mining",
+	Airdrop:        "Airdrop",
+	Staking:        "Staking",
+	Rebate:         "Reward / Bonus",
+	GiftIn:         "Gift / Tip",
+	GiftOut:        "Gift",
+	RealizedProfit: "Margin Profit",
+	RealizedLoss:   "Margin Loss",
+	MarginFee:      "Margin Fee",
+}
+
+type coinTrackingExporter struct{}
+
+func (coinTrackingExporter) Name() string {
+	return "cointracking"
+}
+
+func (coinTrackingExporter) Extension() string {
+	return "csv"
+}
+
+func (coinTrackingExporter) Write(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(COINTRACKING_HEADERS)
+	if err != nil {
+		return fmt.Errorf("Error writing CoinTracking CSV headers: %w", err)
+	}
+
+	for _, entry := range entries {
+		bought, sold, fee := entry.Received, entry.Sent, entry.Fee
+		if entry.Kind.Direction() == FeeOnly {
+			sold, fee = fee, Amount{}
+		}
+
+		err = writer.Write([]string{
+			coinTrackingType(entry, bought, sold),
+			amountValue(bought),
+			amountCurrency(bought),
+			amountValue(sold),
+			amountCurrency(sold),
+			amountValue(fee),
+			amountCurrency(fee),
+			entry.Network,
+			"",
+			entry.Description,
+			entry.Time.UTC().Format("2006-01-02 15:04:05"),
+			entry.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("Error writing CoinTracking CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func coinTrackingType(entry Entry, bought, sold Amount) string {
+	if t, found := COINTRACKING_TYPES[entry.Kind]; found {
+		return t
+	}
+
+	switch {
+	case !bought.IsZero() && !sold.IsZero():
+		return "Trade"
+	case entry.Kind.Direction() == In:
+		return "Deposit"
+	default:
+		return "Withdrawal"
+	}
+}
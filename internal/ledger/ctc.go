@@ -0,0 +1,192 @@
+package ledger
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/shopspring/decimal"
+)
+
+// The kind of entry for each CTC type. CTC's base currency is the main leg, and
+// the quote currency is the other side of a trade.
+var CTC_KINDS = map[ctc_util.CTCTransactionType]Kind{
+	ctc_util.CTCBuy:                  Buy,
+	ctc_util.CTCSell:                 Sell,
+	ctc_util.CTCFiatDeposit:          FiatDeposit,
+	ctc_util.CTCFiatWithdrawal:       FiatWithdrawal,
+	ctc_util.CTCFee:                  Fee,
+	ctc_util.CTCApproval:             Approval,
+	ctc_util.CTCReceive:              Receive,
+	ctc_util.CTCSend:                 Send,
+	ctc_util.CTCChainSplit:           ChainSplit,
+	ctc_util.CTCExpense:              Expense,
+	ctc_util.CTCStolen:               Stolen,
+	ctc_util.CTCLost:                 Lost,
+	ctc_util.CTCBurn:                 Burn,
+	ctc_util.CTCIncome:               Income,
+	ctc_util.CTCInterest:             Interest,
+	ctc_util.CTCMining:               Mining,
+	ctc_util.CTCAirdrop:              Airdrop,
+	ctc_util.CTCStaking:              Staking,
+	ctc_util.CTCStakingDeposit:       StakingDeposit,
+	ctc_util.CTCStakingWithdrawal:    StakingWithdrawal,
+	ctc_util.CTCRebate:               Rebate,
+	ctc_util.CTCRoyalty:              Royalty,
+	ctc_util.CTCPersonalUse:          PersonalUse,
+	ctc_util.CTCIncomingGift:         GiftIn,
+	ctc_util.CTCOutgoingGift:         GiftOut,
+	ctc_util.CTCBorrow:               Borrow,
+	ctc_util.CTCLoanRepayment:        LoanRepayment,
+	ctc_util.CTCLiquidate:            Liquidation,
+	ctc_util.CTCBridgeIn:             BridgeIn,
+	ctc_util.CTCBridgeOut:            BridgeOut,
+	ctc_util.CTCMint:                 Mint,
+	ctc_util.CTCCollateralWithdrawal: CollateralWithdrawal,
+	ctc_util.CTCCollateralDeposit:    CollateralDeposit,
+	ctc_util.CTCAddLiquidity:         AddLiquidity,
+	ctc_util.CTCReceiveLPToken:       ReceiveLPToken,
+	ctc_util.CTCRemoveLiquidity:      RemoveLiquidity,
+	ctc_util.CTCReturnLPToken:        ReturnLPToken,
+	ctc_util.CTCFailedIn:             FailedIn,
+	ctc_util.CTCFailedOut:            FailedOut,
+	ctc_util.CTCSpam:                 Spam,
+	ctc_util.CTCSwapIn:               SwapIn,
+	ctc_util.CTCSwapOut:              SwapOut,
+	ctc_util.CTCBridgeTradeIn:        BridgeTradeIn,
+	ctc_util.CTCBridgeTradeOut:       BridgeTradeOut,
+	ctc_util.CTCRealizedProfit:       RealizedProfit,
+	ctc_util.CTCRealizedLoss:         RealizedLoss,
+	ctc_util.CTCMarginFee:            MarginFee,
+	ctc_util.CTCOpenPosition:         OpenPosition,
+	ctc_util.CTCClosePosition:        ClosePosition,
+	ctc_util.CTCReceivePQ:            Receive,
+	ctc_util.CTCSendPQ:               Send,
+}
+
+// FromCTC converts a transaction exported by the handlers into an entry.
+func FromCTC(tx ctc_util.CTCTransaction) (Entry, error) {
+	kind, found := CTC_KINDS[tx.Type]
+	if !found {
+		return Entry{}, fmt.Errorf("No entry kind for CTC type '%s' of %s", tx.Type, tx.ID)
+	}
+
+	hash, _ := tx.Sequence()
+	entry := Entry{
+		Time:        tx.Timestamp,
+		Kind:        kind,
+		Fee:         amount(tx.FeeCurrency, tx.FeeAmount, tx.FeeAsset),
+		From:        tx.From,
+		To:          tx.To,
+		Network:     tx.Blockchain,
+		Hash:        hash,
+		ID:          tx.ID,
+		Description: tx.Description,
+		Price:       Amount{Currency: tx.ReferencePriceCurrency, Value: tx.ReferencePricePerUnit},
+	}
+
+	base := amount(tx.BaseCurrency, tx.BaseAmount, tx.BaseAsset)
+	quote := amount(tx.QuoteCurrency, tx.QuoteAmount, tx.QuoteAsset)
+	switch kind.Direction() {
+	case In:
+		entry.Received, entry.Sent = base, quote
+	case Out:
+		entry.Sent, entry.Received = base, quote
+	case FeeOnly:
+		entry.Fee = base
+	}
+
+	return entry, nil
+}
+
+// amount keeps the asset of a leg only when the handler set one.
+func amount(currency string, value decimal.Decimal, asset core.Asset) Amount {
+	a := Amount{Currency: currency, Value: value}
+	if asset != (core.Asset{}) {
+		a.Asset = &asset
+	}
+	return a
+}
+
+func assetOf(a Amount) core.Asset {
+	if a.Asset == nil {
+		return core.Asset{}
+	}
+	return *a.Asset
+}
+
+// FromCTCAll converts every transaction, stopping at the first that can't be.
+func FromCTCAll(txs []ctc_util.CTCTransaction) ([]Entry, error) {
+	entries := make([]Entry, 0, len(txs))
+	for _, tx := range txs {
+		entry, err := FromCTC(tx)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ToCTC converts an entry back into a CTC transaction.
+func ToCTC(entry Entry) ctc_util.CTCTransaction {
+	tx := ctc_util.CTCTransaction{
+		Timestamp:              entry.Time,
+		Type:                   ctcType(entry.Kind),
+		FeeCurrency:            entry.Fee.Currency,
+		FeeAmount:              entry.Fee.Value,
+		FeeAsset:               assetOf(entry.Fee),
+		From:                   entry.From,
+		To:                     entry.To,
+		Blockchain:             entry.Network,
+		ID:                     entry.ID,
+		Description:            entry.Description,
+		ReferencePricePerUnit:  entry.Price.Value,
+		ReferencePriceCurrency: entry.Price.Currency,
+	}
+
+	var base, quote Amount
+	switch entry.Kind.Direction() {
+	case In:
+		base, quote = entry.Received, entry.Sent
+	case Out:
+		base, quote = entry.Sent, entry.Received
+	case FeeOnly:
+		base = entry.Fee
+		tx.FeeCurrency, tx.FeeAmount, tx.FeeAsset = "", decimal.Zero, core.Asset{}
+	}
+	tx.BaseCurrency, tx.BaseAmount, tx.BaseAsset = base.Currency, base.Value, assetOf(base)
+	tx.QuoteCurrency, tx.QuoteAmount, tx.QuoteAsset = quote.Currency, quote.Value, assetOf(quote)
+
+	return tx
+}
+
+// ctcType is the CTC type of a kind, preferring the first in CTC's own list
+// when more than one maps to it.
+func ctcType(kind Kind) ctc_util.CTCTransactionType {
+	for _, ctcType := range ctc_util.CTC_TRANSACTION_TYPES {
+		if CTC_KINDS[ctcType] == kind {
+			return ctcType
+		}
+	}
+	return ""
+}
+
+type ctcExporter struct{}
+
+func (ctcExporter) Name() string {
+	return "ctc"
+}
+
+func (ctcExporter) Extension() string {
+	return "csv"
+}
+
+func (ctcExporter) Write(w io.Writer, entries []Entry) error {
+	txs := make([]ctc_util.CTCTransaction, len(entries))
+	for i, entry := range entries {
+		txs[i] = ToCTC(entry)
+	}
+	return ctc_util.WriteCSV(w, txs)
+}
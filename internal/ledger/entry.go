@@ -0,0 +1,61 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/shopspring/decimal"
+)
+
+// An Entry is one movement of assets, independent of any tax service. Which
+// legs are set depends on the kind: a trade has both Sent and Received, a
+// receive only has Received, and a fee only has Fee.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Kind        Kind      `json:"kind"`
+	Sent        Amount    `json:"sent"`
+	Received    Amount    `json:"received"`
+	Fee         Amount    `json:"fee"`
+	From        string    `json:"from,omitempty"`
+	To          string    `json:"to,omitempty"`
+	Network     string    `json:"network,omitempty"`
+	Hash        string    `json:"hash,omitempty"` // Transaction the entry came from
+	ID          string    `json:"id"`             // Unique per entry: the hash, or hash-1, hash-2 and so on when a transaction has several
+	Description string    `json:"description,omitempty"`
+	Price       Amount    `json:"price"` // Reference price of one unit of the main leg, if known
+}
+
+type Amount struct {
+	Currency string          `json:"currency,omitempty"`
+	Value    decimal.Decimal `json:"value"`
+	Asset    *core.Asset     `json:"asset,omitempty"` // What the currency is, if the handler knew
+}
+
+func (a Amount) IsZero() bool {
+	return a.Currency == "" || a.Value.IsZero()
+}
+
+// Main is the leg the entry is about: what was received for incoming kinds,
+// and what was sent otherwise.
+func (e Entry) Main() Amount {
+	switch e.Kind.Direction() {
+	case In:
+		return e.Received
+	case FeeOnly:
+		return e.Fee
+	default:
+		return e.Sent
+	}
+}
+
+// Worth is the value of the main leg in the price currency, if there is a
+// reference price.
+func (e Entry) Worth() Amount {
+	if e.Price.IsZero() {
+		return Amount{}
+	}
+	return Amount{
+		Currency: e.Price.Currency,
+		Value:    e.Main().Value.Mul(e.Price.Value),
+	}
+}
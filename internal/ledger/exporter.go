@@ -0,0 +1,58 @@
+package ledger
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// An Exporter writes entries in the format of one tax service or tool.
+type Exporter interface {
+	Name() string      // What the format is selected by, and the prefix of its files
+	Extension() string // File extension, without the dot
+	Write(w io.Writer, entries []Entry) error
+}
+
+// Every format, built with the books of the export
+var EXPORTERS = map[string]func(books Books) Exporter{
+	"ctc":          func(Books) Exporter { return ctcExporter{} },
+	"koinly":       func(Books) Exporter { return koinlyExporter{} },
+	"cointracking": func(Books) Exporter { return coinTrackingExporter{} },
+	"jsonl":        func(Books) Exporter { return jsonlExporter{} },
+	"beancount":    func(books Books) Exporter { return beancountExporter{books} },
+	"hledger":      func(books Books) Exporter { return hledgerExporter{books} },
+}
+
+const DEFAULT_FORMAT = "ctc"
+
+// Exporters returns the exporters for the given format names, defaulting to CTC
+// if there are none.
+func Exporters(formats []string, books Books) ([]Exporter, error) {
+	if len(formats) == 0 {
+		formats = []string{DEFAULT_FORMAT}
+	}
+
+	exporters := make([]Exporter, 0, len(formats))
+	for i, format := range formats {
+		newExporter, found := EXPORTERS[format]
+		if !found {
+			return nil, fmt.Errorf("Unknown export format '%s' (expected one of %s)", format, strings.Join(FormatNames(), ", "))
+		}
+		if !slices.Contains(formats[:i], format) {
+			exporters = append(exporters, newExporter(books))
+		}
+	}
+
+	return exporters, nil
+}
+
+func FormatNames() []string {
+	names := make([]string, 0, len(EXPORTERS))
+	for name := range EXPORTERS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
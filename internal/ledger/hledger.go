@@ -0,0 +1,73 @@
+package ledger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// hledgerExporter writes an hledger journal. hledger has no lots, so known
+// prices are written as transaction prices instead.
+type hledgerExporter struct {
+	books Books
+}
+
+func (hledgerExporter) Name() string {
+	return "hledger"
+}
+
+func (hledgerExporter) Extension() string {
+	return "journal"
+}
+
+func (e hledgerExporter) Write(w io.Writer, entries []Entry) error {
+	var out strings.Builder
+
+	for i, tx := range e.books.journal(entries) {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+
+		description := tx.Entry.Description
+		if description == "" {
+			description = string(tx.Entry.Kind)
+		}
+
+		tags := []string{"id:" + tx.Entry.ID, "kind:" + string(tx.Entry.Kind)}
+		if tx.Entry.Network != "" {
+			tags = append(tags, "network:"+tx.Entry.Network)
+		}
+
+		fmt.Fprintf(&out, "%s * %s  ; %s\n", tx.Date.Format("2006-01-02"), strings.ReplaceAll(description, ";", ","), strings.Join(tags, ", "))
+
+		for _, p := range tx.Postings {
+			if p.Amount.IsZero() {
+				fmt.Fprintf(&out, "    %s\n", p.Account)
+				continue
+			}
+
+			amount := fmt.Sprintf("%s %s", p.Amount.Value.String(), hledgerCommodity(p.Amount.Currency))
+			price := p.Price
+			if !p.Cost.IsZero() {
+				price = p.Cost
+			}
+			if !price.IsZero() {
+				amount += fmt.Sprintf(" @ %s %s", price.String(), e.books.fiat())
+			}
+			fmt.Fprintf(&out, "    %s  %s\n", p.Account, amount)
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// hledgerCommodity quotes commodities with anything but letters in them, which
+// hledger would otherwise read as part of the amount.
+func hledgerCommodity(symbol string) string {
+	name := commodity(symbol)
+	if strings.Trim(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return `"` + name + `"`
+	}
+	return name
+}
@@ -0,0 +1,262 @@
+package ledger
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// A PriceFunc looks up the fiat price of one unit of a currency on a network,
+// if it is known.
+type PriceFunc func(currency, network string, at time.Time) (decimal.Decimal, bool)
+
+// Books are what the plain-text accounting exports need beyond the entries
+// themselves.
+type Books struct {
+	Labels   map[string]string // Lowercase address -> label of my addresses, for account names
+	Fiat     string            // Currency that costs are in (default USD)
+	Location *time.Location    // Timezone of the dates (default UTC)
+	Price    PriceFunc         // Prices of lots without a reference price, if any
+}
+
+// Accounts that the other side of an entry is posted to
+const (
+	ACCOUNT_ASSETS    = "Assets:Crypto"
+	ACCOUNT_FEES      = "Expenses:Fees"
+	ACCOUNT_GAINS     = "Income:Crypto:Gains"
+	ACCOUNT_INCOME    = "Income:Crypto"
+	ACCOUNT_EXPENSES  = "Expenses:Crypto"
+	ACCOUNT_LOANS     = "Liabilities:Loans"
+	ACCOUNT_TRANSFERS = "Equity:Transfers"
+)
+
+var TRADE_KINDS = []Kind{Buy, Sell, Mint, SwapIn, SwapOut, BridgeTradeIn, BridgeTradeOut}
+
+var INCOME_KINDS = []Kind{
+	Income,
+	Interest,
+	Mining,
+	Airdrop,
+	Staking,
+	Rebate,
+	Royalty,
+	ChainSplit,
+	RealizedProfit,
+	GiftIn,
+}
+
+var EXPENSE_KINDS = []Kind{
+	Expense,
+	PersonalUse,
+	Stolen,
+	Lost,
+	Burn,
+	RealizedLoss,
+	MarginFee,
+	Liquidation,
+	GiftOut,
+}
+
+// A journal transaction is an entry as balanced postings. The last posting has
+// no amount, and balances the rest.
+type journalTx struct {
+	Date     time.Time
+	Entry    Entry
+	Postings []posting
+}
+
+type posting struct {
+	Account string
+	Amount  Amount          // Negative for what left the account, zero for the balancing posting
+	Cost    decimal.Decimal // Fiat cost of one unit of an acquired lot, if known
+	Price   decimal.Decimal // Fiat price of one unit disposed of, if known
+	Reduce  bool            // Whether the amount disposed of comes out of lots held at cost
+}
+
+// Whether the lots of a currency in an account have a cost
+type lotCosts int
+
+const (
+	noLots lotCosts = iota
+	atCost
+	mixedCost // Some lots had no known cost, so disposals can't pick from them
+)
+
+// journal turns entries into balanced transactions. Spam is left out.
+func (b Books) journal(entries []Entry) []journalTx {
+	costs := make(map[string]lotCosts)
+	txs := make([]journalTx, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Kind == Spam {
+			continue
+		}
+
+		tx := journalTx{
+			Date:     entry.Time.In(b.location()),
+			Entry:    entry,
+			Postings: make([]posting, 0, 5),
+		}
+
+		if !entry.Sent.IsZero() {
+			tx.Postings = append(tx.Postings, b.disposal(costs, entry, b.myAccount(entry, entry.From, entry.To), entry.Sent))
+		}
+		if !entry.Received.IsZero() {
+			account := b.myAccount(entry, entry.To, entry.From)
+			acquisition := posting{
+				Account: account,
+				Amount:  entry.Received,
+				Cost:    b.unitPrice(entry, entry.Received),
+			}
+			key := account + " " + entry.Received.Currency
+			if acquisition.Cost.IsZero() {
+				costs[key] = mixedCost
+			} else if costs[key] == noLots {
+				costs[key] = atCost
+			}
+			tx.Postings = append(tx.Postings, acquisition)
+		}
+		if !entry.Fee.IsZero() {
+			tx.Postings = append(tx.Postings,
+				posting{Account: ACCOUNT_FEES + ":" + accountName(entry.Network), Amount: entry.Fee},
+				b.disposal(costs, entry, b.myAccount(entry, entry.From, entry.To), entry.Fee),
+			)
+		}
+		if len(tx.Postings) == 0 {
+			continue
+		}
+		if !entry.Sent.IsZero() || !entry.Received.IsZero() {
+			tx.Postings = append(tx.Postings, posting{Account: counterAccount(entry)})
+		} else if fee := tx.Postings[1]; fee.Reduce || !fee.Price.IsZero() {
+			// Paying a fee from lots with a cost realizes a gain or loss on them
+			tx.Postings = append(tx.Postings, posting{Account: ACCOUNT_GAINS})
+		}
+
+		txs = append(txs, tx)
+	}
+
+	return txs
+}
+
+func (b Books) disposal(costs map[string]lotCosts, entry Entry, account string, amount Amount) posting {
+	return posting{
+		Account: account,
+		Amount:  Amount{Currency: amount.Currency, Value: amount.Value.Neg(), Asset: amount.Asset},
+		Price:   b.unitPrice(entry, amount),
+		Reduce:  costs[account+" "+amount.Currency] == atCost,
+	}
+}
+
+// myAccount is the account of the first of the addresses that is mine, or of
+// the network if neither is.
+func (b Books) myAccount(entry Entry, addresses ...string) string {
+	for _, address := range addresses {
+		if address == "" {
+			continue
+		}
+		if label, found := b.Labels[strings.ToLower(address)]; found {
+			return ACCOUNT_ASSETS + ":" + accountName(entry.Network) + ":" + accountName(label)
+		}
+	}
+	return ACCOUNT_ASSETS + ":" + accountName(entry.Network)
+}
+
+func counterAccount(entry Entry) string {
+	switch {
+	case slices.Contains(TRADE_KINDS, entry.Kind):
+		return ACCOUNT_GAINS
+	case slices.Contains(INCOME_KINDS, entry.Kind):
+		return ACCOUNT_INCOME + ":" + accountName(string(entry.Kind))
+	case slices.Contains(EXPENSE_KINDS, entry.Kind):
+		return ACCOUNT_EXPENSES + ":" + accountName(string(entry.Kind))
+	case entry.Kind == Borrow || entry.Kind == LoanRepayment:
+		return ACCOUNT_LOANS + ":" + accountName(entry.Network)
+	default:
+		return ACCOUNT_TRANSFERS + ":" + accountName(entry.Network)
+	}
+}
+
+// unitPrice is the fiat price of one unit of an amount, from the entry's
+// reference price if it has one for the amount, or the price source. It is zero
+// if unknown, and for the fiat currency itself.
+func (b Books) unitPrice(entry Entry, amount Amount) decimal.Decimal {
+	if amount.Currency == b.fiat() {
+		return decimal.Zero
+	}
+	if entry.Price.Currency == b.fiat() && !entry.Price.IsZero() && entry.Main().Currency == amount.Currency {
+		return entry.Price.Value
+	}
+	if b.Price != nil {
+		if price, found := b.Price(amount.Currency, entry.Network, entry.Time); found {
+			return price
+		}
+	}
+	return decimal.Zero
+}
+
+func (b Books) fiat() string {
+	if b.Fiat == "" {
+		return "USD"
+	}
+	return strings.ToUpper(b.Fiat)
+}
+
+func (b Books) location() *time.Location {
+	if b.Location == nil {
+		return time.UTC
+	}
+	return b.Location
+}
+
+// accountName turns a label or network into an account name component, like
+// `hot-wallet` into `HotWallet`.
+func accountName(s string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if r > unicode.MaxASCII {
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		name.WriteRune(r)
+	}
+
+	if name.Len() == 0 {
+		return "Unknown"
+	}
+	return name.String()
+}
+
+// commodity turns a symbol into a commodity name both Beancount and hledger
+// accept: uppercase, starting with a letter, and without spaces or symbols
+// other than `'._-`.
+func commodity(symbol string) string {
+	var name strings.Builder
+	for _, r := range strings.ToUpper(symbol) {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("'._-", r):
+			name.WriteRune(r)
+		default:
+			name.WriteRune('-')
+		}
+	}
+
+	result := strings.TrimRight(name.String(), "'._-")
+	if result == "" || result[0] < 'A' || result[0] > 'Z' {
+		result = "X" + result
+	}
+	if len(result) > 24 {
+		result = strings.TrimRight(result[:24], "'._-")
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package ledger_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func books() ledger.Books {
+	return ledger.Books{
+		Labels: map[string]string{"0x1111": "hot-wallet"},
+		Price: func(currency, network string, at time.Time) (decimal.Decimal, bool) {
+			if currency == "ETH" {
+				return decimal.NewFromInt(2000), true
+			}
+			return decimal.Zero, false
+		},
+	}
+}
+
+func journal(t *testing.T, format string, books ledger.Books, txs ...ctc_util.CTCTransaction) string {
+	t.Helper()
+
+	entries, err := ledger.FromCTCAll(txs)
+	assert.NoError(t, err)
+	exporters, err := ledger.Exporters([]string{format}, books)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, exporters[0].Write(&buf, entries))
+	return buf.String()
+}
+
+func buyEth() ctc_util.CTCTransaction {
+	tx := swap()
+	tx.ID = "0xaaaa"
+	tx.From = "0x1111"
+	return tx
+}
+
+func sellEth() ctc_util.CTCTransaction {
+	return ctc_util.CTCTransaction{
+		Timestamp:     NOON.Add(24 * time.Hour),
+		Type:          ctc_util.CTCSell,
+		BaseCurrency:  "ETH",
+		BaseAmount:    decimal.RequireFromString("0.5"),
+		QuoteCurrency: "USDC.e",
+		QuoteAmount:   decimal.RequireFromString("1100"),
+		From:          "0x1111",
+		Blockchain:    "base",
+		ID:            "0xcccc",
+	}
+}
+
+// Journal tests
+
+func TestBeancount(t *testing.T) {
+	spam := fee()
+	spam.Type = ctc_util.CTCSpam
+
+	assert.Equal(t, `option "operating_currency" "USD"
+
+2024-03-01 open Assets:Crypto:Base:HotWallet
+2024-03-01 open Assets:Crypto:Ethereum
+2024-03-01 open Expenses:Fees:Base
+2024-03-01 open Expenses:Fees:Ethereum
+2024-03-01 open Income:Crypto:Gains
+
+2024-03-01 * "uniswap swap"
+  id: "0xaaaa"
+  kind: "sell"
+  network: "base"
+  Assets:Crypto:Base:HotWallet  -3000 USDC
+  Assets:Crypto:Base:HotWallet  1.5 ETH {2000 USD}
+  Expenses:Fees:Base  0.001 ETH
+  Assets:Crypto:Base:HotWallet  -0.001 ETH {} @ 2000 USD
+  Income:Crypto:Gains
+
+2024-03-01 * "fee"
+  id: "0xbbbb"
+  kind: "fee"
+  network: "ethereum"
+  Expenses:Fees:Ethereum  0.002 ETH
+  Assets:Crypto:Ethereum  -0.002 ETH @ 2000 USD
+  Income:Crypto:Gains
+
+2024-03-02 * "sell"
+  id: "0xcccc"
+  kind: "sell"
+  network: "base"
+  Assets:Crypto:Base:HotWallet  -0.5 ETH {} @ 2000 USD
+  Assets:Crypto:Base:HotWallet  1100 USDC.E
+  Income:Crypto:Gains
+`, journal(t, "beancount", books(), buyEth(), fee(), spam, sellEth()))
+}
+
+func TestHledger(t *testing.T) {
+	assert.Equal(t, `2024-03-01 * uniswap swap  ; id:0xaaaa, kind:sell, network:base
+    Assets:Crypto:Base:HotWallet  -3000 USDC
+    Assets:Crypto:Base:HotWallet  1.5 ETH @ 2000 USD
+    Expenses:Fees:Base  0.001 ETH
+    Assets:Crypto:Base:HotWallet  -0.001 ETH @ 2000 USD
+    Income:Crypto:Gains
+
+2024-03-02 * sell  ; id:0xcccc, kind:sell, network:base
+    Assets:Crypto:Base:HotWallet  -0.5 ETH @ 2000 USD
+    Assets:Crypto:Base:HotWallet  1100 "USDC.E"
+    Income:Crypto:Gains
+`, journal(t, "hledger", books(), buyEth(), sellEth()))
+}
+
+func TestJournalWithoutPrices(t *testing.T) {
+	withoutPrices := books()
+	withoutPrices.Price = nil
+
+	actual := journal(t, "beancount", withoutPrices, buyEth(), sellEth())
+	assert.Contains(t, actual, "  Assets:Crypto:Base:HotWallet  1.5 ETH\n")
+	assert.Contains(t, actual, "  Assets:Crypto:Base:HotWallet  -0.5 ETH\n")
+}
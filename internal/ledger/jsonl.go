@@ -0,0 +1,30 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonlExporter writes every entry as is, one JSON object per line, for
+// scripts and for cross-checking the other formats.
+type jsonlExporter struct{}
+
+func (jsonlExporter) Name() string {
+	return "jsonl"
+}
+
+func (jsonlExporter) Extension() string {
+	return "jsonl"
+}
+
+func (jsonlExporter) Write(w io.Writer, entries []Entry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		err := encoder.Encode(entry)
+		if err != nil {
+			return fmt.Errorf("Error writing entry %s: %w", entry.ID, err)
+		}
+	}
+	return nil
+}
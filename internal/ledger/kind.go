@@ -0,0 +1,104 @@
+package ledger
+
+import (
+	"slices"
+)
+
+type Kind string
+
+const (
+	Buy                  Kind = "buy"
+	Sell                 Kind = "sell"
+	FiatDeposit          Kind = "fiat_deposit"
+	FiatWithdrawal       Kind = "fiat_withdrawal"
+	Fee                  Kind = "fee"
+	Approval             Kind = "approval"
+	Receive              Kind = "receive"
+	Send                 Kind = "send"
+	ChainSplit           Kind = "chain_split"
+	Expense              Kind = "expense"
+	Stolen               Kind = "stolen"
+	Lost                 Kind = "lost"
+	Burn                 Kind = "burn"
+	Income               Kind = "income"
+	Interest             Kind = "interest"
+	Mining               Kind = "mining"
+	Airdrop              Kind = "airdrop"
+	Staking              Kind = "staking"
+	StakingDeposit       Kind = "staking_deposit"
+	StakingWithdrawal    Kind = "staking_withdrawal"
+	Rebate               Kind = "rebate"
+	Royalty              Kind = "royalty"
+	PersonalUse          Kind = "personal_use"
+	GiftIn               Kind = "gift_in"
+	GiftOut              Kind = "gift_out"
+	Borrow               Kind = "borrow"
+	LoanRepayment        Kind = "loan_repayment"
+	Liquidation          Kind = "liquidation"
+	BridgeIn             Kind = "bridge_in"
+	BridgeOut            Kind = "bridge_out"
+	Mint                 Kind = "mint"
+	CollateralWithdrawal Kind = "collateral_withdrawal"
+	CollateralDeposit    Kind = "collateral_deposit"
+	AddLiquidity         Kind = "add_liquidity"
+	ReceiveLPToken       Kind = "receive_lp_token"
+	RemoveLiquidity      Kind = "remove_liquidity"
+	ReturnLPToken        Kind = "return_lp_token"
+	FailedIn             Kind = "failed_in"
+	FailedOut            Kind = "failed_out"
+	Spam                 Kind = "spam"
+	SwapIn               Kind = "swap_in"
+	SwapOut              Kind = "swap_out"
+	BridgeTradeIn        Kind = "bridge_trade_in"
+	BridgeTradeOut       Kind = "bridge_trade_out"
+	RealizedProfit       Kind = "realized_profit"
+	RealizedLoss         Kind = "realized_loss"
+	MarginFee            Kind = "margin_fee"
+	OpenPosition         Kind = "open_position"
+	ClosePosition        Kind = "close_position"
+)
+
+// Which way the main leg of an entry moves
+type Direction int
+
+const (
+	In      Direction = iota // Something was received, maybe for something sent
+	Out                      // Something was sent, maybe for something received
+	FeeOnly                  // Only a fee was paid
+)
+
+var OUTGOING_KINDS = []Kind{
+	Sell,
+	FiatWithdrawal,
+	Approval,
+	Send,
+	Expense,
+	Stolen,
+	Lost,
+	Burn,
+	StakingDeposit,
+	PersonalUse,
+	GiftOut,
+	LoanRepayment,
+	Liquidation,
+	BridgeOut,
+	CollateralDeposit,
+	AddLiquidity,
+	ReturnLPToken,
+	FailedOut,
+	SwapOut,
+	BridgeTradeOut,
+	RealizedLoss,
+	MarginFee,
+	OpenPosition,
+}
+
+func (k Kind) Direction() Direction {
+	if k == Fee {
+		return FeeOnly
+	}
+	if slices.Contains(OUTGOING_KINDS, k) {
+		return Out
+	}
+	return In
+}
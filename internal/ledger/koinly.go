@@ -0,0 +1,125 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Koinly's universal CSV format
+var KOINLY_HEADERS = []string{
+	"Date",
+	"Sent Amount",
+	"Sent Currency",
+	"Received Amount",
+	"Received Currency",
+	"Fee Amount",
+	"Fee Currency",
+	"Net Worth Amount",
+	"Net Worth Currency",
+	"Label",
+	"Description",
+	"TxHash",
+}
+
+// Koinly's labels for kinds that aren't plain deposits, withdrawals or trades
+var KOINLY_LABELS = map[Kind]string{
+	Fee:               "cost",
+	Approval:          "cost",
+	ChainSplit:        "fork",
+	Expense:           "cost",
+	PersonalUse:       "cost",
+	Stolen:            "lost",
+	Lost:              "lost",
+	Burn:              "lost",
+	Income:            "income",
+	Royalty:           "income",
+	Interest:          "lending interest",
+	Mining:            "mining",
+	Airdrop:           "airdrop",
+	Staking:           "reward",
+	Rebate:            "reward",
+	GiftIn:            "gift",
+	GiftOut:           "gift",
+	Borrow:            "loan",
+	LoanRepayment:     "loan repayment",
+	Liquidation:       "liquidate",
+	AddLiquidity:      "liquidity in",
+	ReturnLPToken:     "liquidity in",
+	RemoveLiquidity:   "liquidity out",
+	ReceiveLPToken:    "liquidity out",
+	RealizedProfit:    "realized gain",
+	RealizedLoss:      "realized gain",
+	MarginFee:         "margin fee",
+	StakingDeposit:    "stake",
+	StakingWithdrawal: "unstake",
+}
+
+type koinlyExporter struct{}
+
+func (koinlyExporter) Name() string {
+	return "koinly"
+}
+
+func (koinlyExporter) Extension() string {
+	return "csv"
+}
+
+func (koinlyExporter) Write(w io.Writer, entries []Entry) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(KOINLY_HEADERS)
+	if err != nil {
+		return fmt.Errorf("Error writing Koinly CSV headers: %w", err)
+	}
+
+	for _, entry := range entries {
+		sent, received, fee := entry.Sent, entry.Received, entry.Fee
+
+		// Koinly needs something sent or received, so a fee on its own is a cost
+		if entry.Kind.Direction() == FeeOnly {
+			sent, fee = fee, Amount{}
+		}
+
+		description := entry.Description
+		if entry.Kind == Spam {
+			description = "spam: " + description
+		}
+
+		worth := entry.Worth()
+		err = writer.Write([]string{
+			entry.Time.UTC().Format("2006-01-02 15:04:05 UTC"),
+			amountValue(sent),
+			amountCurrency(sent),
+			amountValue(received),
+			amountCurrency(received),
+			amountValue(fee),
+			amountCurrency(fee),
+			amountValue(worth),
+			amountCurrency(worth),
+			KOINLY_LABELS[entry.Kind],
+			description,
+			entry.Hash,
+		})
+		if err != nil {
+			return fmt.Errorf("Error writing Koinly CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func amountValue(a Amount) string {
+	if a.IsZero() {
+		return ""
+	}
+	return a.Value.String()
+}
+
+func amountCurrency(a Amount) string {
+	if a.IsZero() {
+		return ""
+	}
+	return a.Currency
+}
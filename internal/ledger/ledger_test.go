@@ -0,0 +1,153 @@
+package ledger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/ctc_util"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var NOON = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func swap() ctc_util.CTCTransaction {
+	return ctc_util.CTCTransaction{
+		Timestamp:     NOON,
+		Type:          ctc_util.CTCSell,
+		BaseCurrency:  "USDC",
+		BaseAmount:    decimal.RequireFromString("3000"),
+		QuoteCurrency: "ETH",
+		QuoteAmount:   decimal.RequireFromString("1.5"),
+		FeeCurrency:   "ETH",
+		FeeAmount:     decimal.RequireFromString("0.001"),
+		Blockchain:    "base",
+		ID:            "0xaaaa-2",
+		Description:   "uniswap swap",
+	}
+}
+
+func fee() ctc_util.CTCTransaction {
+	return ctc_util.CTCTransaction{
+		Timestamp:    NOON,
+		Type:         ctc_util.CTCFee,
+		BaseCurrency: "ETH",
+		BaseAmount:   decimal.RequireFromString("0.002"),
+		Blockchain:   "ethereum",
+		ID:           "0xbbbb",
+	}
+}
+
+func export(t *testing.T, format string, txs ...ctc_util.CTCTransaction) []string {
+	t.Helper()
+
+	entries, err := ledger.FromCTCAll(txs)
+	assert.NoError(t, err)
+	exporters, err := ledger.Exporters([]string{format}, ledger.Books{})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, exporters[0].Write(&buf, entries))
+	return strings.Split(strings.TrimSpace(buf.String()), "\n")
+}
+
+// Entry tests
+
+func TestFromCTC(t *testing.T) {
+	entry, err := ledger.FromCTC(swap())
+	assert.NoError(t, err)
+	assert.Equal(t, ledger.Sell, entry.Kind)
+	assert.Equal(t, "USDC", entry.Sent.Currency)
+	assert.Equal(t, "ETH", entry.Received.Currency)
+	assert.Equal(t, "0.001", entry.Fee.Value.String())
+	assert.Equal(t, "0xaaaa", entry.Hash)
+	assert.Nil(t, entry.Sent.Asset)
+
+	withAsset := swap()
+	withAsset.BaseAsset = core.Asset{NetworkName: "ethereum", Identifier: "0xa0b8", Symbol: "USDC"}
+	entry, err = ledger.FromCTC(withAsset)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xa0b8", entry.Sent.Asset.Identifier)
+	assert.Equal(t, withAsset.BaseAsset, ledger.ToCTC(entry).BaseAsset)
+
+	entry, err = ledger.FromCTC(fee())
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH", entry.Fee.Currency)
+	assert.True(t, entry.Sent.IsZero())
+
+	_, err = ledger.FromCTC(ctc_util.CTCTransaction{Type: "gift", ID: "0xcccc"})
+	assert.Error(t, err)
+}
+
+func TestCTCRoundTrip(t *testing.T) {
+	for _, ctcType := range ctc_util.CTC_TRANSACTION_TYPES {
+		if ctcType == ctc_util.CTCReceivePQ || ctcType == ctc_util.CTCSendPQ {
+			continue // Exported as plain receives and sends
+		}
+
+		tx := swap()
+		if ctcType == ctc_util.CTCFee {
+			tx = fee()
+		}
+		tx.Type = ctcType
+
+		entry, err := ledger.FromCTC(tx)
+		assert.NoError(t, err, ctcType)
+		roundTrip := ledger.ToCTC(entry)
+		assert.Equal(t, tx.ToCSV(), roundTrip.ToCSV(), ctcType)
+	}
+}
+
+// Exporter tests
+
+func TestExporters(t *testing.T) {
+	exporters, err := ledger.Exporters(nil, ledger.Books{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ctc", exporters[0].Name())
+
+	exporters, err = ledger.Exporters([]string{"koinly", "jsonl", "koinly"}, ledger.Books{})
+	assert.NoError(t, err)
+	assert.Len(t, exporters, 2)
+
+	_, err = ledger.Exporters([]string{"turbotax"}, ledger.Books{})
+	assert.EqualError(t, err, "Unknown export format 'turbotax' (expected one of beancount, cointracking, ctc, hledger, jsonl, koinly)")
+}
+
+func TestKoinly(t *testing.T) {
+	lines := export(t, "koinly", swap(), fee())
+	assert.Equal(t, []string{
+		strings.Join(ledger.KOINLY_HEADERS, ","),
+		"2024-03-01 12:00:00 UTC,3000,USDC,1.5,ETH,0.001,ETH,,,,uniswap swap,0xaaaa",
+		"2024-03-01 12:00:00 UTC,0.002,ETH,,,,,,,cost,,0xbbbb",
+	}, lines)
+}
+
+func TestCoinTracking(t *testing.T) {
+	lines := export(t, "cointracking", swap(), fee())
+	assert.Equal(t, []string{
+		strings.Join(ledger.COINTRACKING_HEADERS, ","),
+		"Trade,1.5,ETH,3000,USDC,0.001,ETH,base,,uniswap swap,2024-03-01 12:00:00,0xaaaa-2",
+		"Other Fee,,,0.002,ETH,,,ethereum,,,2024-03-01 12:00:00,0xbbbb",
+	}, lines)
+}
+
+func TestJSONL(t *testing.T) {
+	lines := export(t, "jsonl", swap(), fee())
+	assert.Len(t, lines, 2)
+
+	var entry ledger.Entry
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, ledger.Sell, entry.Kind)
+	assert.Equal(t, "1.5", entry.Received.Value.String())
+	assert.True(t, entry.Time.Equal(NOON))
+}
+
+func TestCTC(t *testing.T) {
+	lines := export(t, "ctc", swap())
+	assert.Equal(t, "2024-03-01 12:00:01,sell,USDC,3000,ETH,1.5,ETH,0.001,,,base,0xaaaa-2,uniswap swap,,", lines[1])
+}
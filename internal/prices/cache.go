@@ -0,0 +1,82 @@
+package prices
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/shopspring/decimal"
+)
+
+// A CachedSource remembers the daily prices returned by another source in a
+// FileDB collection, including the fact that a price could not be found for a
+// day that is over. Misses for assets the source has no ID for aren't kept,
+// since one can be configured later.
+type CachedSource struct {
+	source     core.PriceSource
+	collection *util.FileDBCollection
+}
+
+type cachedPrice struct {
+	Found bool            `json:"found"`
+	Price decimal.Decimal `json:"price"`
+}
+
+func NewCachedSource(source core.PriceSource, db *util.FileDB) *CachedSource {
+	return &CachedSource{
+		source:     source,
+		collection: db.NewCollection("prices"),
+	}
+}
+
+func (s *CachedSource) PriceAt(asset core.Asset, fiat core.Asset, at time.Time) (core.Price, error) {
+	cacheKey := cacheKeyFor(asset, fiat, at)
+
+	var cached cachedPrice
+	cacheFound, err := s.collection.Read(cacheKey, &cached)
+	if err != nil {
+		fmt.Printf("Error reading from price cache: %s\n", err.Error())
+	}
+	if cacheFound {
+		if !cached.Found {
+			return core.Price{}, fmt.Errorf("%s in %s on %s (cached): %w", asset, fiat.Symbol, at.Format(time.DateOnly), core.PRICE_NOT_FOUND)
+		}
+		return core.NewPrice(asset, fiat, cached.Price)
+	}
+
+	price, err := s.source.PriceAt(asset, fiat, at)
+	if err != nil && !errors.Is(err, core.PRICE_NOT_FOUND) {
+		return core.Price{}, err
+	}
+
+	if err != nil && (errors.Is(err, NO_PRICE_ID) || !dayIsOver(at)) {
+		return price, err
+	}
+
+	err2 := s.collection.Write(cacheKey, cachedPrice{
+		Found: err == nil,
+		Price: price.Value,
+	})
+	if err2 != nil {
+		fmt.Printf("Error writing to price cache: %s\n", err2.Error())
+	}
+
+	return price, err
+}
+
+func dayIsOver(at time.Time) bool {
+	return at.UTC().Format(time.DateOnly) < time.Now().UTC().Format(time.DateOnly)
+}
+
+func cacheKeyFor(asset core.Asset, fiat core.Asset, at time.Time) string {
+	key := fmt.Sprintf("%s-%s-%s-%s",
+		asset.NetworkName,
+		asset.Identifier,
+		fiat.Symbol,
+		at.UTC().Format(time.DateOnly),
+	)
+	return strings.ReplaceAll(key, "/", "_")
+}
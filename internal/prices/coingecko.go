@@ -0,0 +1,225 @@
+package prices
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/shopspring/decimal"
+)
+
+const COINGECKO_API_BASE = "https://api.coingecko.com/api/v3"
+const COINGECKO_KEY_HEADER = "x-cg-demo-api-key"
+const COINGECKO_RPS = 0.5
+const COINGECKO_MAX_RETRIES = 10
+
+// Maps network names to CoinGecko asset platform IDs, for looking up coin IDs
+// by token contract
+var COINGECKO_PLATFORMS = map[string]string{
+	"ethereum":     "ethereum",
+	"base":         "base",
+	"polygon":      "polygon-pos",
+	"optimism":     "optimistic-ethereum",
+	"avalanche":    "avalanche",
+	"arbitrumone":  "arbitrum-one",
+	"arbitrumnova": "arbitrum-nova",
+	"moonbeam":     "moonbeam",
+	"moonriver":    "moonriver",
+	"fantom":       "fantom",
+}
+
+// A CoinGeckoSource looks up daily prices from a CoinGecko-compatible API. The
+// price for a day is the one CoinGecko reports at 00:00 UTC.
+type CoinGeckoSource struct {
+	url       string
+	key       string
+	keyHeader string
+	ids       map[string]string
+	platforms map[string]string
+	http      *http.Client
+	throttle  <-chan time.Time
+
+	mu       sync.Mutex
+	resolved map[string]string // Caches coin IDs looked up by contract, "" if unknown
+}
+
+type coinGeckoHistory struct {
+	ID         string `json:"id"`
+	MarketData *struct {
+		CurrentPrice map[string]decimal.Decimal `json:"current_price"`
+	} `json:"market_data"`
+}
+
+func NewCoinGeckoSource(cfg CoinGeckoConfig) *CoinGeckoSource {
+	baseUrl := cfg.URL
+	if baseUrl == "" {
+		baseUrl = COINGECKO_API_BASE
+	}
+
+	keyHeader := cfg.KeyHeader
+	if keyHeader == "" {
+		keyHeader = COINGECKO_KEY_HEADER
+	}
+
+	rps := cfg.RPS
+	if rps <= 0 {
+		rps = COINGECKO_RPS
+	}
+
+	ids := make(map[string]string, len(cfg.IDs))
+	for key, id := range cfg.IDs {
+		ids[strings.ToLower(key)] = id
+	}
+
+	platforms := make(map[string]string, len(COINGECKO_PLATFORMS))
+	for network, platform := range COINGECKO_PLATFORMS {
+		platforms[network] = platform
+	}
+	for network, platform := range cfg.Platforms {
+		platforms[strings.ToLower(network)] = platform
+	}
+
+	return &CoinGeckoSource{
+		url:       strings.TrimSuffix(baseUrl, "/"),
+		key:       cfg.Key,
+		keyHeader: keyHeader,
+		ids:       ids,
+		platforms: platforms,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		throttle:  time.Tick(time.Duration(float64(time.Second) / rps)),
+		resolved:  make(map[string]string),
+	}
+}
+
+func (s *CoinGeckoSource) PriceAt(asset core.Asset, fiat core.Asset, at time.Time) (core.Price, error) {
+	id, err := s.coinID(asset)
+	if err != nil {
+		return core.Price{}, err
+	}
+	if id == "" {
+		return core.Price{}, fmt.Errorf("No CoinGecko ID for %s: %w", asset, NO_PRICE_ID)
+	}
+
+	params := url.Values{}
+	params.Set("date", at.UTC().Format("02-01-2006"))
+	params.Set("localization", "false")
+
+	var history coinGeckoHistory
+	found, err := s.get("/coins/"+url.PathEscape(id)+"/history", params, &history)
+	if err != nil {
+		return core.Price{}, fmt.Errorf("Could not get CoinGecko price for %s: %w", id, err)
+	}
+	if !found || history.MarketData == nil {
+		return core.Price{}, fmt.Errorf("No CoinGecko market data for %s on %s: %w", id, at.Format(time.DateOnly), core.PRICE_NOT_FOUND)
+	}
+
+	price, ok := history.MarketData.CurrentPrice[strings.ToLower(fiat.Symbol)]
+	if !ok {
+		return core.Price{}, fmt.Errorf("No CoinGecko %s price for %s on %s: %w", fiat.Symbol, id, at.Format(time.DateOnly), core.PRICE_NOT_FOUND)
+	}
+
+	return core.NewPrice(asset, fiat, price)
+}
+
+func (s *CoinGeckoSource) coinID(asset core.Asset) (string, error) {
+	for _, key := range assetKeys(asset) {
+		if id, ok := s.ids[key]; ok {
+			return id, nil
+		}
+	}
+
+	if asset.Kind != core.Erc20Token {
+		return "", nil
+	}
+
+	platform, ok := s.platforms[asset.NetworkName]
+	if !ok {
+		return "", nil
+	}
+
+	contractKey := strings.ToLower(asset.NetworkName + "-" + asset.Identifier)
+
+	s.mu.Lock()
+	id, ok := s.resolved[contractKey]
+	s.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var coin coinGeckoHistory
+	path := "/coins/" + url.PathEscape(platform) + "/contract/" + url.PathEscape(strings.ToLower(asset.Identifier))
+	found, err := s.get(path, nil, &coin)
+	if err != nil {
+		return "", fmt.Errorf("Could not look up CoinGecko ID for %s: %w", asset, err)
+	}
+	if found {
+		id = coin.ID
+	}
+
+	s.mu.Lock()
+	s.resolved[contractKey] = id
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+// Makes a throttled GET request, retrying with back-off when rate limited.
+// Returns false if the API responds with 404.
+func (s *CoinGeckoSource) get(path string, params url.Values, result any) (bool, error) {
+	requestUrl := s.url + path
+	if len(params) > 0 {
+		requestUrl += "?" + params.Encode()
+	}
+
+	rateLimitWaitSeconds := 1
+
+	for {
+		<-s.throttle
+
+		req, err := http.NewRequest(http.MethodGet, requestUrl, nil)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if s.key != "" {
+			req.Header.Set(s.keyHeader, s.key)
+		}
+
+		util.Debugf("GET %s\n", requestUrl)
+		resp, err := s.http.Do(req)
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return false, nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			resp.Body.Close()
+			if rateLimitWaitSeconds > COINGECKO_MAX_RETRIES {
+				return false, fmt.Errorf("Gave up on %s after status %d", path, resp.StatusCode)
+			}
+			time.Sleep(time.Duration(rateLimitWaitSeconds) * time.Second)
+			rateLimitWaitSeconds++
+			continue
+		case resp.StatusCode != http.StatusOK:
+			resp.Body.Close()
+			return false, fmt.Errorf("Unexpected status %d from %s", resp.StatusCode, path)
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return false, fmt.Errorf("Could not decode response from %s: %w", path, err)
+		}
+
+		return true, nil
+	}
+}
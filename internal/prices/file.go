@@ -0,0 +1,146 @@
+package prices
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/shopspring/decimal"
+)
+
+// A FileSource serves prices from a CSV or JSON file of known prices, which is
+// mostly useful for illiquid tokens that no price API knows about. CSV files
+// have the header `time,asset,currency,price`, and JSON files are a list of
+// objects with the same keys. Assets are identified by `<network>-<contract>`
+// (or the symbol, for native assets), and each price applies from its time
+// until the next price for the same asset.
+type FileSource struct {
+	Path   string
+	prices map[string][]filePrice // asset key + currency -> prices sorted by time
+}
+
+type filePrice struct {
+	Time     string          `json:"time"`
+	Asset    string          `json:"asset"`
+	Currency string          `json:"currency"`
+	Price    decimal.Decimal `json:"price"`
+
+	at time.Time
+}
+
+func NewFileSource(path string) (*FileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open price file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var rows []filePrice
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = readCsvPrices(file)
+	case ".json":
+		err = json.NewDecoder(file).Decode(&rows)
+	default:
+		err = fmt.Errorf("unsupported file type")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not read price file %s: %w", path, err)
+	}
+
+	source := &FileSource{
+		Path:   path,
+		prices: make(map[string][]filePrice),
+	}
+
+	for i, row := range rows {
+		row.at, err = parseTime(row.Time)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid time on row %d of price file %s: %w", i+1, path, err)
+		}
+		key := fileKey(strings.ToLower(row.Asset), row.Currency)
+		source.prices[key] = append(source.prices[key], row)
+	}
+
+	for _, prices := range source.prices {
+		sort.SliceStable(prices, func(i, j int) bool {
+			return prices[i].at.Before(prices[j].at)
+		})
+	}
+
+	return source, nil
+}
+
+func (s *FileSource) PriceAt(asset core.Asset, fiat core.Asset, at time.Time) (core.Price, error) {
+	for _, assetKey := range assetKeys(asset) {
+		prices := s.prices[fileKey(assetKey, fiat.Symbol)]
+
+		// Find the first price after the requested time, and use the one before it
+		i := sort.Search(len(prices), func(i int) bool {
+			return prices[i].at.After(at)
+		})
+		if i > 0 {
+			return core.NewPrice(asset, fiat, prices[i-1].Price)
+		}
+	}
+
+	return core.Price{}, fmt.Errorf("%s in %s at %s from %s: %w", asset, fiat.Symbol, at.Format(time.RFC3339), s.Path, core.PRICE_NOT_FOUND)
+}
+
+func readCsvPrices(r io.Reader) ([]filePrice, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]filePrice, 0, len(records))
+	for i, record := range records {
+		// Skip header row
+		if i == 0 && record[0] == "time" {
+			continue
+		}
+
+		price, err := decimal.NewFromString(strings.TrimSpace(record[3]))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid price on row %d: %w", i+1, err)
+		}
+
+		rows = append(rows, filePrice{
+			Time:     strings.TrimSpace(record[0]),
+			Asset:    strings.TrimSpace(record[1]),
+			Currency: strings.TrimSpace(record[2]),
+			Price:    price,
+		})
+	}
+
+	return rows, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Unrecognized time format '%s'", s)
+}
+
+func fileKey(assetKey, fiat string) string {
+	return assetKey + "|" + strings.ToUpper(fiat)
+}
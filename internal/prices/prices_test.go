@@ -0,0 +1,211 @@
+package prices_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/prices"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var eth core.Asset = core.Asset{
+	NetworkKind: core.EvmNetworkKind,
+	NetworkName: "ethereum",
+	Kind:        core.EvmNative,
+	Identifier:  "0x0000000000000000000000000000000000000000",
+	Symbol:      "ETH",
+	Decimals:    18,
+}
+
+var usdc core.Asset = core.Asset{
+	NetworkKind: core.EvmNetworkKind,
+	NetworkName: "base",
+	Kind:        core.Erc20Token,
+	Identifier:  "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+	Symbol:      "USDC",
+	Decimals:    6,
+}
+
+var usd = core.FiatAsset("USD")
+var eur = core.FiatAsset("EUR")
+
+func writeFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(contents), 0644)
+	assert.Nil(t, err)
+	return path
+}
+
+func TestFileSource_Csv(t *testing.T) {
+	path := writeFile(t, "prices.csv", `time,asset,currency,price
+2024-01-01,ETH,USD,2300.50
+2024-02-01,ETH,USD,2500
+2024-01-15,base-0x833589fcd6edb6e08f4c7c32d4f71b54bda02913,USD,0.999
+`)
+	source, err := prices.NewFileSource(path)
+	assert.Nil(t, err)
+
+	price, err := source.PriceAt(eth, usd, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "2300.5", price.Value.String())
+
+	price, err = source.PriceAt(eth, usd, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "2500", price.Value.String())
+
+	price, err = source.PriceAt(usdc, usd, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.999", price.Value.String())
+
+	_, err = source.PriceAt(eth, usd, time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+}
+
+func TestFileSource_Json(t *testing.T) {
+	path := writeFile(t, "prices.json", `[
+		{"time": "1704067200", "asset": "ETH", "currency": "EUR", "price": "2100"}
+	]`)
+	source, err := prices.NewFileSource(path)
+	assert.Nil(t, err)
+
+	price, err := source.PriceAt(eth, eur, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "2100", price.Value.String())
+
+	_, err = source.PriceAt(eth, usd, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+}
+
+func newMockCoinGecko(t *testing.T, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		assert.Equal(t, "secret", r.Header.Get("x-cg-demo-api-key"))
+
+		switch r.URL.Path {
+		case "/coins/base/contract/0x833589fcd6edb6e08f4c7c32d4f71b54bda02913":
+			w.Write([]byte(`{"id": "usd-coin"}`))
+		case "/coins/usd-coin/history":
+			assert.Equal(t, "15-01-2024", r.URL.Query().Get("date"))
+			w.Write([]byte(`{"id": "usd-coin", "market_data": {"current_price": {"usd": 1.001, "eur": 0.92}}}`))
+		case "/coins/ethereum/history":
+			w.Write([]byte(`{"id": "ethereum"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCoinGeckoSource(t *testing.T) {
+	requests := 0
+	server := newMockCoinGecko(t, &requests)
+	defer server.Close()
+
+	source := prices.NewCoinGeckoSource(prices.CoinGeckoConfig{
+		URL: server.URL,
+		Key: "secret",
+		RPS: 1000,
+		IDs: map[string]string{"ETH": "ethereum"},
+	})
+
+	price, err := source.PriceAt(usdc, usd, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "1.001", price.Value.String())
+
+	_, err = source.PriceAt(eth, usd, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+
+	unknown := usdc
+	unknown.Identifier = "0x0000000000000000000000000000000000000001"
+	_, err = source.PriceAt(unknown, usd, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+}
+
+func TestCachedSource(t *testing.T) {
+	requests := 0
+	server := newMockCoinGecko(t, &requests)
+	defer server.Close()
+
+	db := util.NewFileDB(t.TempDir())
+	source := prices.NewCachedSource(prices.NewCoinGeckoSource(prices.CoinGeckoConfig{
+		URL: server.URL,
+		Key: "secret",
+		RPS: 1000,
+	}), db)
+
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	price, err := source.PriceAt(usdc, eur, at)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.92", price.Value.String())
+	assert.Equal(t, 2, requests)
+
+	price, err = source.PriceAt(usdc, eur, at)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.92", price.Value.String())
+	assert.Equal(t, 2, requests)
+}
+
+func TestCachedSourceMisses(t *testing.T) {
+	requests := 0
+	server := newMockCoinGecko(t, &requests)
+	defer server.Close()
+
+	db := util.NewFileDB(t.TempDir())
+	newSource := func() *prices.CachedSource {
+		return prices.NewCachedSource(prices.NewCoinGeckoSource(prices.CoinGeckoConfig{
+			URL: server.URL,
+			Key: "secret",
+			RPS: 1000,
+			IDs: map[string]string{"ETH": "ethereum"},
+		}), db)
+	}
+
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	unknown := usdc
+	unknown.Identifier = "0x0000000000000000000000000000000000000001"
+
+	// CoinGecko answered for the ID, so the miss is kept
+	for range 2 {
+		_, err := newSource().PriceAt(eth, usd, at)
+		assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+	}
+	assert.Equal(t, 1, requests)
+
+	// Without an ID, or for a day that isn't over, it's asked again
+	for range 2 {
+		_, err := newSource().PriceAt(unknown, usd, at)
+		assert.True(t, errors.Is(err, prices.NO_PRICE_ID))
+	}
+	assert.Equal(t, 3, requests)
+
+	for range 2 {
+		_, err := newSource().PriceAt(eth, usd, time.Now())
+		assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+	}
+	assert.Equal(t, 5, requests)
+}
+
+func TestChain(t *testing.T) {
+	path := writeFile(t, "prices.csv", "time,asset,currency,price\n2024-01-01,ETH,USD,2000\n")
+	file, err := prices.NewFileSource(path)
+	assert.Nil(t, err)
+
+	empty := writeFile(t, "empty.csv", "time,asset,currency,price\n")
+	emptyFile, err := prices.NewFileSource(empty)
+	assert.Nil(t, err)
+
+	chain := prices.Chain{emptyFile, file}
+	price, err := chain.PriceAt(eth, usd, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "2000", price.Value.String())
+
+	_, err = chain.PriceAt(usdc, usd, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, core.PRICE_NOT_FOUND))
+}
@@ -0,0 +1,88 @@
+package prices
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+// For sources that don't know which of their assets an asset is, as opposed
+// to knowing it but having no price for it
+var NO_PRICE_ID = fmt.Errorf("no price ID for asset: %w", core.PRICE_NOT_FOUND)
+
+type Config struct {
+	Fiat      string          `mapstructure:"fiat"`
+	Files     []string        `mapstructure:"files"`
+	CoinGecko CoinGeckoConfig `mapstructure:"coingecko"`
+}
+
+type CoinGeckoConfig struct {
+	Enabled   bool              `mapstructure:"enabled"`
+	URL       string            `mapstructure:"url"`
+	Key       string            `mapstructure:"key"`
+	KeyHeader string            `mapstructure:"key_header"`
+	RPS       float64           `mapstructure:"rps"`
+	IDs       map[string]string `mapstructure:"ids"`
+	Platforms map[string]string `mapstructure:"platforms"`
+}
+
+// Chain tries each source in order, returning the first price found.
+type Chain []core.PriceSource
+
+func (c Chain) PriceAt(asset core.Asset, fiat core.Asset, at time.Time) (core.Price, error) {
+	for _, source := range c {
+		price, err := source.PriceAt(asset, fiat, at)
+		if err == nil {
+			return price, nil
+		}
+		if !errors.Is(err, core.PRICE_NOT_FOUND) {
+			return core.Price{}, err
+		}
+	}
+
+	return core.Price{}, fmt.Errorf("%s in %s at %s: %w", asset, fiat.Symbol, at.Format(time.RFC3339), core.PRICE_NOT_FOUND)
+}
+
+// NewSource builds the configured price sources: price files first, so they
+// can override anything else, then CoinGecko with a FileDB cache.
+func NewSource(cfg Config, db *util.FileDB) (Chain, error) {
+	chain := make(Chain, 0)
+
+	for _, path := range cfg.Files {
+		source, err := NewFileSource(path)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, source)
+	}
+
+	if cfg.CoinGecko.Enabled {
+		chain = append(chain, NewCachedSource(NewCoinGeckoSource(cfg.CoinGecko), db))
+	}
+
+	return chain, nil
+}
+
+// The keys an asset can be referred to by in price files and ID mappings, most
+// specific first. Symbols are only accepted for native assets, since anyone can
+// deploy a token with any symbol.
+func assetKeys(asset core.Asset) []string {
+	keys := []string{strings.ToLower(asset.NetworkName + "-" + asset.Identifier)}
+
+	if asset.Kind == core.EvmNative {
+		keys = append(keys, strings.ToLower(asset.Symbol))
+	}
+
+	return keys
+}
+
+func (c Config) FiatAsset() core.Asset {
+	if c.Fiat == "" {
+		return core.FiatAsset("USD")
+	}
+	return core.FiatAsset(c.Fiat)
+}
@@ -0,0 +1,189 @@
+package solana
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+const RPC_RPS = 5
+const SIGNATURES_PAGE_SIZE = 1000
+
+type Client struct {
+	Network  Network // The network the client is for
+	http     *http.Client
+	throttle <-chan time.Time
+}
+
+type rpcRequest struct {
+	JsonRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewClient(network Network) (*Client, error) {
+	if len(network.RPCs) == 0 {
+		return nil, fmt.Errorf("No RPCs configured for %s", network.Name)
+	}
+
+	rps := network.RPS
+	if rps == 0 {
+		rps = RPC_RPS
+	}
+
+	return &Client{
+		Network:  network,
+		http:     &http.Client{Timeout: 30 * time.Second},
+		throttle: time.Tick(time.Second / time.Duration(rps)),
+	}, nil
+}
+
+func NewIndexer(network Network) (core.Indexer, error) {
+	return NewClient(network)
+}
+
+func (c *Client) LatestBlock() (uint64, error) {
+	var slot uint64
+	err := c.call("getSlot", &slot, map[string]string{"commitment": "finalized"})
+	return slot, err
+}
+
+func (c *Client) GetTransaction(signature string) (*Transaction, error) {
+	var tx *Transaction
+	err := c.call("getTransaction", &tx, signature, map[string]any{
+		"encoding":                       "json",
+		"commitment":                     "finalized",
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("Transaction %s not found on %s", signature, c.Network.Name)
+	}
+	return tx, nil
+}
+
+func (c *Client) GetSignaturesForAddress(address string, before string) ([]Signature, error) {
+	options := map[string]any{
+		"limit":      SIGNATURES_PAGE_SIZE,
+		"commitment": "finalized",
+	}
+	if before != "" {
+		options["before"] = before
+	}
+
+	var signatures []Signature
+	err := c.call("getSignaturesForAddress", &signatures, address, options)
+	return signatures, err
+}
+
+// GetAllTransactionHashes pages backwards through the signatures of an
+// address, newest first, stopping once it is past the start slot.
+func (c *Client) GetAllTransactionHashes(address string, startBlock, endBlock *int) ([]string, error) {
+	hashes := make([]string, 0)
+	before := ""
+
+	for {
+		page, err := c.GetSignaturesForAddress(address, before)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sig := range page {
+			if endBlock != nil && sig.Slot > uint64(*endBlock) {
+				continue
+			}
+			if startBlock != nil && sig.Slot < uint64(*startBlock) {
+				return hashes, nil
+			}
+			hashes = append(hashes, sig.Signature)
+		}
+
+		if len(page) < SIGNATURES_PAGE_SIZE {
+			return hashes, nil
+		}
+		before = page[len(page)-1].Signature
+	}
+}
+
+func (c *Client) NativeAsset() (core.Asset, error) {
+	return c.Network.NativeAsset(), nil
+}
+
+func (c *Client) OpenTransactionInExplorer(hash string, wait ...bool) {
+	c.Network.OpenTransactionInExplorer(hash, wait...)
+}
+
+// Tries each RPC in turn, returning the first successful result
+func (c *Client) call(method string, result any, params ...any) error {
+	errs := make([]error, 0)
+
+	for _, url := range c.Network.RPCs {
+		err := c.callRPC(url, method, result, params)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+
+	return fmt.Errorf("Could not call %s on %s: %w", method, c.Network.Name, errors.Join(errs...))
+}
+
+func (c *Client) callRPC(url, method string, result any, params []any) error {
+	<-c.throttle
+
+	body, err := json.Marshal(rpcRequest{
+		JsonRPC: "2.0",
+		ID:      1,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	util.Debugf("%s %s %s\n", url, method, body)
+	resp, err := c.http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	var rpcResp rpcResponse
+	err = json.Unmarshal(respBody, &rpcResp)
+	if err != nil {
+		return fmt.Errorf("Could not decode response: %w", err)
+	}
+
+	if rpcResp.Error != nil {
+		return fmt.Errorf("RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
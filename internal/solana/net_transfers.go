@@ -0,0 +1,156 @@
+package solana
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/shopspring/decimal"
+)
+
+type BalanceChanges map[core.SolanaAddress]*core.Amount
+type NetTransfers map[core.Asset]BalanceChanges
+
+func (nt NetTransfers) String() string {
+	s := ""
+
+	if len(nt) == 0 {
+		return s
+	}
+
+	for asset, changes := range nt {
+		s += fmt.Sprintf("  %s:\n", asset)
+		for addr, amount := range changes {
+			s += fmt.Sprintf("    %s: %s\n", addr, amount)
+		}
+	}
+
+	return s[:len(s)-1]
+}
+
+func (nt NetTransfers) Print() {
+	fmt.Printf("net transfers:\n%s\n", nt.String())
+}
+
+// OnlyMine removes every address that isn't mine, and any zero changes.
+func (nt NetTransfers) OnlyMine(isMine func(core.SolanaAddress) bool) NetTransfers {
+	for asset, changes := range nt {
+		for addr, amount := range changes {
+			if !isMine(addr) || amount.IsZero() {
+				delete(changes, addr)
+			}
+		}
+		if len(changes) == 0 {
+			delete(nt, asset)
+		}
+	}
+
+	return nt
+}
+
+type tokenKey struct {
+	mint  string
+	owner core.SolanaAddress
+}
+
+// NetBalanceChanges works out how much of each asset every account gained or
+// lost in a transaction, from the balances before and after it. Unlike EVM
+// logs, Solana doesn't need any decoding of instructions to do this, since the
+// node reports balances directly.
+//
+// SOL changes are by account, and exclude the transaction fee so that it can
+// be recorded separately. Token changes are by owner of the token account
+// (the wallet) rather than the token account itself.
+func NetBalanceChanges(network Network, tx *Transaction) (NetTransfers, error) {
+	netTransfers := make(NetTransfers)
+
+	keys := tx.AccountKeys()
+	if len(tx.Meta.PreBalances) != len(keys) || len(tx.Meta.PostBalances) != len(keys) {
+		return nil, fmt.Errorf(
+			"Mismatched balances for %s: %d accounts, %d pre, %d post",
+			tx.Signature(),
+			len(keys),
+			len(tx.Meta.PreBalances),
+			len(tx.Meta.PostBalances),
+		)
+	}
+
+	nativeAsset := network.NativeAsset()
+
+	for i, key := range keys {
+		pre := lamports(tx.Meta.PreBalances[i])
+		post := lamports(tx.Meta.PostBalances[i])
+		change := post.Sub(pre)
+
+		if i == 0 {
+			change = change.Add(lamports(tx.Meta.Fee))
+		}
+
+		if change.IsZero() {
+			continue
+		}
+
+		netTransfers.add(nativeAsset, core.SolanaAddress(key), change)
+	}
+
+	decimals := make(map[string]uint8)
+	tokenChanges := make(map[tokenKey]decimal.Decimal)
+	order := make([]tokenKey, 0)
+
+	balances := [][]TokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances}
+	for i, side := range balances {
+		for _, balance := range side {
+			owner := balance.Owner
+			if owner == "" {
+				if balance.AccountIndex < 0 || balance.AccountIndex >= len(keys) {
+					return nil, fmt.Errorf("Invalid token account index %d in %s", balance.AccountIndex, tx.Signature())
+				}
+				owner = keys[balance.AccountIndex]
+			}
+
+			amount, err := decimal.NewFromString(balance.UiTokenAmount.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid token amount '%s' in %s: %w", balance.UiTokenAmount.Amount, tx.Signature(), err)
+			}
+			if i == 0 {
+				amount = amount.Neg()
+			}
+
+			key := tokenKey{balance.Mint, core.SolanaAddress(owner)}
+			if _, seen := tokenChanges[key]; !seen {
+				order = append(order, key)
+			}
+			tokenChanges[key] = tokenChanges[key].Add(amount)
+			decimals[balance.Mint] = balance.UiTokenAmount.Decimals
+		}
+	}
+
+	for _, key := range order {
+		change := tokenChanges[key]
+		if change.IsZero() {
+			continue
+		}
+
+		asset := network.TokenAsset(key.mint, decimals[key.mint])
+		netTransfers.add(asset, key.owner, change)
+	}
+
+	return netTransfers, nil
+}
+
+func (nt NetTransfers) add(asset core.Asset, addr core.SolanaAddress, atomicChange decimal.Decimal) {
+	if nt[asset] == nil {
+		nt[asset] = make(BalanceChanges)
+	}
+
+	change := core.NewSignedAmountFromDecimal(asset, atomicChange.Shift(-int32(asset.Decimals)))
+	if existing := nt[asset][addr]; existing != nil {
+		change.Value = change.Value.Add(existing.Value)
+	}
+
+	nt[asset][addr] = &change
+}
+
+func lamports(v uint64) decimal.Decimal {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(v), 0)
+}
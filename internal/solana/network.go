@@ -0,0 +1,85 @@
+package solana
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+type Network struct {
+	Name              string            `mapstructure:"name"`
+	NativeAssetSymbol string            `mapstructure:"native_asset"`
+	RPCs              []string          `mapstructure:"rpcs"`
+	RPS               uint              `mapstructure:"rps"`
+	Tokens            map[string]string `mapstructure:"tokens"` // mint -> symbol, keys are lowercased by viper
+	Deprecated        bool              `mapstructure:"deprecated"`
+	ExplorerURLs      struct {
+		Tx   string `mapstructure:"tx"`
+		Addr string `mapstructure:"addr"`
+	} `mapstructure:"explorer_urls"`
+}
+
+func (n Network) GetKind() core.NetworkKind {
+	return core.SolanaNetworkKind
+}
+
+func (n Network) GetName() string {
+	return n.Name
+}
+
+func (n Network) GetDeprecated() bool {
+	return n.Deprecated
+}
+
+func (n Network) NativeAsset() core.Asset {
+	return core.Asset{
+		NetworkKind: core.SolanaNetworkKind,
+		NetworkName: n.Name,
+		Kind:        core.SvmNative,
+		Identifier:  n.NativeAssetSymbol,
+		Symbol:      n.NativeAssetSymbol,
+		Decimals:    9,
+	}
+}
+
+// TokenAsset returns the asset for an SPL token mint. Mints with no decimals
+// are treated as NFTs, which is how Metaplex (and nearly every other NFT
+// standard on Solana) creates them.
+func (n Network) TokenAsset(mint string, decimals uint8) core.Asset {
+	kind := core.SplToken
+	if decimals == 0 {
+		kind = core.SplNft
+	}
+
+	symbol, found := n.Tokens[strings.ToLower(mint)]
+	if !found {
+		symbol = mint
+	}
+
+	return core.Asset{
+		NetworkKind: core.SolanaNetworkKind,
+		NetworkName: n.Name,
+		Kind:        kind,
+		Identifier:  mint,
+		Symbol:      symbol,
+		Decimals:    decimals,
+	}
+}
+
+func (n Network) OpenTransactionInExplorer(hash string, wait ...bool) {
+	url := strings.Replace(n.ExplorerURLs.Tx, "TX", hash, 1)
+	time.Sleep(time.Millisecond * 2000)
+	cmd := exec.Command("/usr/bin/open", "-u", url, "-a", "Google Chrome")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Println(cmd.String())
+		fmt.Println(string(output))
+		fmt.Println(err.Error())
+	}
+	if len(wait) > 0 && wait[0] {
+		_, _ = fmt.Scanln()
+	}
+}
@@ -0,0 +1,220 @@
+package solana_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/solana"
+	"github.com/stretchr/testify/assert"
+)
+
+const ALICE = "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin"
+const BOB = "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T"
+const USDC_MINT = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+var NETWORK = solana.Network{
+	Name:              "solana",
+	NativeAssetSymbol: "SOL",
+	Tokens:            map[string]string{"epjfwdd5aufqssqem2qn1xzybapc8g4weggkzwytdt1v": "USDC"},
+}
+
+// Alice pays a 5000 lamport fee to send 1 SOL and 2.5 USDC to Bob, and also
+// creates Bob's USDC account (paying its rent).
+const TRANSFER_TX = `{
+	"slot": 300000000,
+	"blockTime": 1735700000,
+	"meta": {
+		"err": null,
+		"fee": 5000,
+		"preBalances": [5000000000, 1000000000, 0, 2039280, 1],
+		"postBalances": [3997955720, 2000000000, 2039280, 2039280, 1],
+		"preTokenBalances": [
+			{"accountIndex": 3, "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "owner": "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin", "uiTokenAmount": {"amount": "10000000", "decimals": 6}}
+		],
+		"postTokenBalances": [
+			{"accountIndex": 2, "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "owner": "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T", "uiTokenAmount": {"amount": "2500000", "decimals": 6}},
+			{"accountIndex": 3, "mint": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", "owner": "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin", "uiTokenAmount": {"amount": "7500000", "decimals": 6}}
+		],
+		"loadedAddresses": {"writable": [], "readonly": ["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"]}
+	},
+	"transaction": {
+		"signatures": ["sig1"],
+		"message": {
+			"accountKeys": [
+				"9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin",
+				"4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T",
+				"BobUsdcAccount11111111111111111111111111111",
+				"AliceUsdcAccount111111111111111111111111111"
+			]
+		}
+	}
+}`
+
+func parseTx(t *testing.T, raw string) *solana.Transaction {
+	var tx solana.Transaction
+	err := json.Unmarshal([]byte(raw), &tx)
+	assert.Nil(t, err)
+	return &tx
+}
+
+func amountString(changes solana.BalanceChanges, addr string) string {
+	amount, found := changes[core.SolanaAddress(addr)]
+	if !found {
+		return ""
+	}
+	return amount.Value.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// NetBalanceChanges tests
+
+func TestNetBalanceChanges(t *testing.T) {
+	tx := parseTx(t, TRANSFER_TX)
+	assert.Equal(t, "sig1", tx.Signature())
+	assert.Equal(t, ALICE, tx.FeePayer())
+	assert.True(t, tx.Success())
+
+	netTransfers, err := solana.NetBalanceChanges(NETWORK, tx)
+	assert.Nil(t, err)
+
+	sol := netTransfers[NETWORK.NativeAsset()]
+	assert.Equal(t, "-1.00203928", amountString(sol, ALICE)) // Excludes the fee
+	assert.Equal(t, "1", amountString(sol, BOB))
+	assert.Equal(t, "0.00203928", amountString(sol, "BobUsdcAccount11111111111111111111111111111"))
+	assert.Equal(t, "", amountString(sol, "AliceUsdcAccount111111111111111111111111111"))
+
+	usdcAsset := NETWORK.TokenAsset(USDC_MINT, 6)
+	assert.Equal(t, core.SplToken, usdcAsset.Kind)
+	assert.Equal(t, "USDC", usdcAsset.Symbol)
+
+	usdc := netTransfers[usdcAsset]
+	assert.Equal(t, "-2.5", amountString(usdc, ALICE))
+	assert.Equal(t, "2.5", amountString(usdc, BOB))
+}
+
+func TestNetBalanceChangesOnlyMine(t *testing.T) {
+	netTransfers, err := solana.NetBalanceChanges(NETWORK, parseTx(t, TRANSFER_TX))
+	assert.Nil(t, err)
+
+	mine := netTransfers.OnlyMine(func(addr core.SolanaAddress) bool {
+		return addr == BOB
+	})
+
+	assert.Len(t, mine, 2)
+	for _, changes := range mine {
+		assert.Len(t, changes, 1)
+		assert.True(t, changes[BOB].IsPositive())
+	}
+}
+
+func TestNetBalanceChangesMismatched(t *testing.T) {
+	tx := parseTx(t, TRANSFER_TX)
+	tx.Meta.PostBalances = tx.Meta.PostBalances[1:]
+
+	_, err := solana.NetBalanceChanges(NETWORK, tx)
+	assert.NotNil(t, err)
+}
+
+func TestTokenAssetNft(t *testing.T) {
+	asset := NETWORK.TokenAsset("NftMint1111111111111111111111111111111111111", 0)
+	assert.Equal(t, core.SplNft, asset.Kind)
+	assert.Equal(t, "NftMint1111111111111111111111111111111111111", asset.Symbol)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Client tests
+
+type rpcCall struct {
+	Method string `json:"method"`
+	Params []any  `json:"params"`
+}
+
+// A fake RPC with 2500 signatures for an address, one per slot, newest first
+func newFakeRPC(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var call rpcCall
+		assert.Nil(t, json.Unmarshal(body, &call))
+
+		var result any
+		switch call.Method {
+		case "getSlot":
+			result = 3000
+		case "getSignaturesForAddress":
+			options := call.Params[1].(map[string]any)
+			slot := 2500
+			if before, ok := options["before"]; ok {
+				var s int
+				_, err := fmt.Sscanf(before.(string), "sig%d", &s)
+				assert.Nil(t, err)
+				slot = s - 1
+			}
+			page := make([]map[string]any, 0)
+			for ; slot > 0 && len(page) < int(options["limit"].(float64)); slot-- {
+				page = append(page, map[string]any{"signature": sig(slot), "slot": slot})
+			}
+			result = page
+		case "getTransaction":
+			result = json.RawMessage(TRANSFER_TX)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func sig(slot int) string {
+	return fmt.Sprintf("sig%d", slot)
+}
+
+func newTestClient(t *testing.T, url string) *solana.Client {
+	network := NETWORK
+	network.RPCs = []string{"http://127.0.0.1:1", url}
+	network.RPS = 1000
+
+	client, err := solana.NewClient(network)
+	assert.Nil(t, err)
+	return client
+}
+
+func TestClient(t *testing.T) {
+	server := newFakeRPC(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+
+	slot, err := client.LatestBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3000), slot)
+
+	tx, err := client.GetTransaction("sig1")
+	assert.Nil(t, err)
+	assert.Equal(t, "sig1", tx.Signature())
+}
+
+func TestGetAllTransactionHashes(t *testing.T) {
+	server := newFakeRPC(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+
+	hashes, err := client.GetAllTransactionHashes(ALICE, nil, nil)
+	assert.Nil(t, err)
+	assert.Len(t, hashes, 2500)
+	assert.Equal(t, sig(2500), hashes[0])
+	assert.Equal(t, sig(1), hashes[2499])
+
+	start, end := 1200, 2100
+	hashes, err = client.GetAllTransactionHashes(ALICE, &start, &end)
+	assert.Nil(t, err)
+	assert.Len(t, hashes, 901)
+	assert.Equal(t, sig(2100), hashes[0])
+	assert.Equal(t, sig(1200), hashes[900])
+}
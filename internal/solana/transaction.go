@@ -0,0 +1,77 @@
+package solana
+
+// A Transaction is the result of getTransaction with "json" encoding, keeping
+// only the fields needed to work out balance changes.
+type Transaction struct {
+	Slot        uint64          `json:"slot"`
+	BlockTime   int64           `json:"blockTime"`
+	Meta        TransactionMeta `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys []string `json:"accountKeys"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+type TransactionMeta struct {
+	Err               any            `json:"err"`
+	Fee               uint64         `json:"fee"`
+	PreBalances       []uint64       `json:"preBalances"`
+	PostBalances      []uint64       `json:"postBalances"`
+	PreTokenBalances  []TokenBalance `json:"preTokenBalances"`
+	PostTokenBalances []TokenBalance `json:"postTokenBalances"`
+	LoadedAddresses   *struct {
+		Writable []string `json:"writable"`
+		Readonly []string `json:"readonly"`
+	} `json:"loadedAddresses"`
+}
+
+type TokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UiTokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals uint8  `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// A Signature is an entry from getSignaturesForAddress.
+type Signature struct {
+	Signature string `json:"signature"`
+	Slot      uint64 `json:"slot"`
+	BlockTime *int64 `json:"blockTime"`
+	Err       any    `json:"err"`
+}
+
+func (t *Transaction) Signature() string {
+	if len(t.Transaction.Signatures) == 0 {
+		return ""
+	}
+	return t.Transaction.Signatures[0]
+}
+
+func (t *Transaction) Success() bool {
+	return t.Meta.Err == nil
+}
+
+// FeePayer is always the first account of the message.
+func (t *Transaction) FeePayer() string {
+	if len(t.Transaction.Message.AccountKeys) == 0 {
+		return ""
+	}
+	return t.Transaction.Message.AccountKeys[0]
+}
+
+// AccountKeys returns every account the transaction touched, in the order used
+// by the balance lists: static keys, then writable and readonly addresses
+// loaded from lookup tables (for versioned transactions).
+func (t *Transaction) AccountKeys() []string {
+	keys := append([]string{}, t.Transaction.Message.AccountKeys...)
+	if t.Meta.LoadedAddresses != nil {
+		keys = append(keys, t.Meta.LoadedAddresses.Writable...)
+		keys = append(keys, t.Meta.LoadedAddresses.Readonly...)
+	}
+	return keys
+}
@@ -0,0 +1,155 @@
+package tax_period
+
+import (
+	"fmt"
+	"time"
+)
+
+const DATE_FORMAT = "2006-01-02"
+
+type Config struct {
+	Timezone  string `mapstructure:"timezone"`   // IANA name, like America/Chicago (default UTC)
+	YearStart string `mapstructure:"year_start"` // MM-DD the tax year starts on, like 04-06 (default 01-01)
+}
+
+// A Period is a span of time to export, starting at From (inclusive) and
+// ending at To (exclusive).
+type Period struct {
+	Name string // The tax year (like 2024), or from_to for other ranges
+	From time.Time
+	To   time.Time
+}
+
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.From) && t.Before(p.To)
+}
+
+func (p Period) String() string {
+	return fmt.Sprintf("%s (%s to %s)", p.Name, p.From.Format(time.RFC3339), p.To.Format(time.RFC3339))
+}
+
+func (c Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid tax timezone '%s': %w", c.Timezone, err)
+	}
+
+	return loc, nil
+}
+
+func (c Config) yearStart() (time.Month, int, error) {
+	if c.YearStart == "" {
+		return time.January, 1, nil
+	}
+
+	start, err := time.Parse("01-02", c.YearStart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid tax year start '%s', expected MM-DD: %w", c.YearStart, err)
+	}
+
+	return start.Month(), start.Day(), nil
+}
+
+// Year returns the tax year starting in the given calendar year.
+func (c Config) Year(year int) (Period, error) {
+	loc, err := c.Location()
+	if err != nil {
+		return Period{}, err
+	}
+
+	month, day, err := c.yearStart()
+	if err != nil {
+		return Period{}, err
+	}
+
+	return Period{
+		Name: fmt.Sprint(year),
+		From: time.Date(year, month, day, 0, 0, 0, 0, loc),
+		To:   time.Date(year+1, month, day, 0, 0, 0, 0, loc),
+	}, nil
+}
+
+// YearOf returns the tax year containing the given time.
+func (c Config) YearOf(t time.Time) (Period, error) {
+	loc, err := c.Location()
+	if err != nil {
+		return Period{}, err
+	}
+
+	period, err := c.Year(t.In(loc).Year())
+	if err != nil {
+		return Period{}, err
+	}
+
+	if t.Before(period.From) {
+		return c.Year(t.In(loc).Year() - 1)
+	}
+
+	return period, nil
+}
+
+// Range returns the period between two dates (YYYY-MM-DD) at midnight in the
+// configured timezone.
+func (c Config) Range(from, to string) (Period, error) {
+	loc, err := c.Location()
+	if err != nil {
+		return Period{}, err
+	}
+
+	fromTime, err := time.ParseInLocation(DATE_FORMAT, from, loc)
+	if err != nil {
+		return Period{}, fmt.Errorf("Invalid date '%s', expected YYYY-MM-DD: %w", from, err)
+	}
+
+	toTime, err := time.ParseInLocation(DATE_FORMAT, to, loc)
+	if err != nil {
+		return Period{}, fmt.Errorf("Invalid date '%s', expected YYYY-MM-DD: %w", to, err)
+	}
+
+	if !fromTime.Before(toTime) {
+		return Period{}, fmt.Errorf("Empty period from %s to %s", from, to)
+	}
+
+	return Period{
+		Name: fmt.Sprintf("%s_%s", from, to),
+		From: fromTime,
+		To:   toTime,
+	}, nil
+}
+
+// SplitByYear splits a period into the tax years it overlaps. Years that are
+// only partially covered are cut down to the period, and named by their range
+// so they can't be mistaken for the whole year.
+func (c Config) SplitByYear(period Period) ([]Period, error) {
+	periods := make([]Period, 0)
+
+	year, err := c.YearOf(period.From)
+	if err != nil {
+		return nil, err
+	}
+
+	for year.From.Before(period.To) {
+		part := year
+		if part.From.Before(period.From) {
+			part.From = period.From
+		}
+		if part.To.After(period.To) {
+			part.To = period.To
+		}
+		if part != year {
+			part.Name = fmt.Sprintf("%s_%s", part.From.Format(DATE_FORMAT), part.To.Format(DATE_FORMAT))
+		}
+		periods = append(periods, part)
+
+		year, err = c.YearOf(year.To)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return periods, nil
+}
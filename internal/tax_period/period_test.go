@@ -0,0 +1,104 @@
+package tax_period_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/stretchr/testify/assert"
+)
+
+// Year tests
+
+func TestYearDefaultsToCalendarYearInUTC(t *testing.T) {
+	year, err := tax_period.Config{}.Year(2025)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025", year.Name)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), year.From)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), year.To)
+}
+
+func TestYearInTimezone(t *testing.T) {
+	cfg := tax_period.Config{Timezone: "America/Chicago"}
+	year, err := cfg.Year(2025)
+	assert.NoError(t, err)
+
+	// Late on New Year's Eve in Chicago is already the next year in UTC
+	newYearsEve := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	assert.True(t, year.Contains(newYearsEve))
+	assert.False(t, year.Contains(time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)))
+}
+
+func TestYearWithCustomStart(t *testing.T) {
+	cfg := tax_period.Config{Timezone: "Europe/London", YearStart: "04-06"}
+
+	year, err := cfg.YearOf(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024", year.Name)
+	assert.Equal(t, "2024-04-06", year.From.Format(tax_period.DATE_FORMAT))
+	assert.Equal(t, "2025-04-06", year.To.Format(tax_period.DATE_FORMAT))
+
+	year, err = cfg.YearOf(time.Date(2025, 4, 6, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "2025", year.Name)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := tax_period.Config{Timezone: "Mars/Olympus_Mons"}.Year(2025)
+	assert.Error(t, err)
+
+	_, err = tax_period.Config{YearStart: "April 6"}.Year(2025)
+	assert.Error(t, err)
+}
+
+// Range tests
+
+func TestRange(t *testing.T) {
+	period, err := tax_period.Config{}.Range("2024-07-01", "2025-07-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-07-01_2025-07-01", period.Name)
+	assert.True(t, period.Contains(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, period.Contains(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)))
+
+	_, err = tax_period.Config{}.Range("2025-07-01", "2024-07-01")
+	assert.Error(t, err)
+
+	_, err = tax_period.Config{}.Range("2025-7-1", "2026-01-01")
+	assert.Error(t, err)
+}
+
+// SplitByYear tests
+
+func TestSplitByYear(t *testing.T) {
+	cfg := tax_period.Config{}
+	period, err := cfg.Range("2023-07-01", "2025-03-01")
+	assert.NoError(t, err)
+
+	years, err := cfg.SplitByYear(period)
+	assert.NoError(t, err)
+	assert.Len(t, years, 3)
+
+	names := make([]string, 0)
+	for _, year := range years {
+		names = append(names, year.Name)
+	}
+	assert.Equal(t, []string{"2023-07-01_2024-01-01", "2024", "2025-01-01_2025-03-01"}, names)
+	assert.Equal(t, period.From, years[0].From)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), years[0].To)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), years[2].From)
+	assert.Equal(t, period.To, years[2].To)
+}
+
+func TestSplitByYearOnBoundaries(t *testing.T) {
+	cfg := tax_period.Config{}
+	period, err := cfg.Range("2024-01-01", "2026-01-01")
+	assert.NoError(t, err)
+
+	years, err := cfg.SplitByYear(period)
+	assert.NoError(t, err)
+	assert.Len(t, years, 2)
+
+	first, _ := cfg.Year(2024)
+	second, _ := cfg.Year(2025)
+	assert.Equal(t, []tax_period.Period{first, second}, years)
+}
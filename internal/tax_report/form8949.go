@@ -0,0 +1,71 @@
+package tax_report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const FORM_8949_DATE_FORMAT = "01/02/2006"
+
+var FORM_8949_HEADERS = []string{
+	"Description",
+	"Date Acquired",
+	"Date Sold",
+	"Proceeds",
+	"Cost Basis",
+	"Gain or Loss",
+	"Term",
+	"ID",
+}
+
+// WriteForm8949 writes the rows of Form 8949 as CSV, short-term (Part I) before
+// long-term (Part II), each in the order they were sold.
+func WriteForm8949(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(FORM_8949_HEADERS)
+	if err != nil {
+		return fmt.Errorf("Error writing Form 8949 headers: %w", err)
+	}
+
+	for _, term := range []Term{ShortTerm, LongTerm} {
+		for _, row := range report.Rows {
+			if row.Term != term {
+				continue
+			}
+			err = writer.Write([]string{
+				row.Description,
+				formatDate(row.Acquired, report),
+				formatDate(row.Sold, report),
+				money(row.Proceeds),
+				money(row.CostBasis),
+				money(row.Gain),
+				string(row.Term),
+				row.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("Error writing Form 8949 row: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Dates are in the timezone of the period, since that's what the tax year is
+// defined by. Amounts without a known lot have no acquisition date.
+func formatDate(t time.Time, report *Report) string {
+	if t.IsZero() {
+		return "UNKNOWN"
+	}
+	return t.In(report.Period.From.Location()).Format(FORM_8949_DATE_FORMAT)
+}
+
+func money(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
@@ -0,0 +1,307 @@
+package tax_report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cost_basis"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/shopspring/decimal"
+)
+
+type Term string
+
+const (
+	ShortTerm Term = "short"
+	LongTerm  Term = "long"
+)
+
+// A Row is one line of Form 8949: the part of a disposal that came out of one
+// lot.
+type Row struct {
+	Description string    // Amount and currency, like 0.5 ETH
+	Acquired    time.Time // Zero if no lot was known for the amount
+	Sold        time.Time
+	Proceeds    decimal.Decimal
+	CostBasis   decimal.Decimal
+	Gain        decimal.Decimal
+	Term        Term
+	ID          string // Entry of the disposal
+}
+
+type Totals struct {
+	Rows      int
+	Proceeds  decimal.Decimal
+	CostBasis decimal.Decimal
+	Gain      decimal.Decimal
+}
+
+// A Report is every disposal in a period, with Schedule D totals per holding
+// period.
+type Report struct {
+	Period   tax_period.Period
+	Fiat     string
+	Method   cost_basis.Method
+	Rows     []Row
+	Totals   map[Term]Totals
+	Warnings []string // Disposals without a price or a lot, which need checking
+
+	decimals map[string]uint8 // Finest precision seen for each currency
+}
+
+// Entries that acquire lots, at their fair value, besides trades
+var INCOME_KINDS = []ledger.Kind{
+	ledger.Income,
+	ledger.Interest,
+	ledger.Mining,
+	ledger.Airdrop,
+	ledger.Staking,
+	ledger.Rebate,
+	ledger.Royalty,
+}
+
+// Entries that dispose of lots, at their fair value, besides trades
+var SPENDING_KINDS = []ledger.Kind{
+	ledger.Expense,
+	ledger.PersonalUse,
+}
+
+// Build runs every entry up to the end of the period, in order, through the
+// cost-basis engine, and reports the disposals within the period. Lots are acquired by
+// trades and income, and disposed of by trades, spending and fees paid in
+// crypto. Everything else (transfers, bridges, deposits into protocols) moves
+// assets between accounts without changing lots. Lots are pooled by asset, see
+// event.
+func Build(
+	entries []ledger.Entry,
+	period tax_period.Period,
+	method cost_basis.Method,
+	fiat string,
+	price ledger.PriceFunc,
+) (*Report, error) {
+	engine, err := cost_basis.NewEngine(method)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Period:   period,
+		Fiat:     strings.ToUpper(fiat),
+		Method:   method,
+		Rows:     make([]Row, 0),
+		Totals:   make(map[Term]Totals),
+		Warnings: make([]string, 0),
+		decimals: make(map[string]uint8),
+	}
+
+	for _, entry := range entries {
+		if !entry.Time.Before(period.To) {
+			break
+		}
+
+		warned := len(report.Warnings)
+		events := report.events(entry, price)
+		realizations, err := engine.Process(events)
+		if err != nil {
+			return nil, err
+		}
+
+		// Earlier entries only matter for their lots
+		if !period.Contains(entry.Time) {
+			report.Warnings = report.Warnings[:warned]
+			continue
+		}
+		for _, realization := range realizations {
+			report.addRealization(realization)
+		}
+	}
+
+	return report, nil
+}
+
+// events are the acquisitions and disposals of one entry, valued in fiat. The
+// fee, whatever the kind of entry, disposes of what it was paid in last.
+func (r *Report) events(entry ledger.Entry, price ledger.PriceFunc) []cost_basis.Event {
+	events := make([]cost_basis.Event, 0, 3)
+	isTrade := slices.Contains(ledger.TRADE_KINDS, entry.Kind)
+
+	for _, amount := range []ledger.Amount{entry.Sent, entry.Received, entry.Fee} {
+		if !amount.IsZero() {
+			r.decimals[amount.Currency] = max(r.decimals[amount.Currency], precision(amount.Value))
+		}
+	}
+
+	switch {
+	case isTrade && !entry.Sent.IsZero() && !entry.Received.IsZero():
+		value, found := r.tradeValue(entry, price)
+		if !found {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s: no price for %s or %s, valued at zero", entry.ID, entry.Sent.Currency, entry.Received.Currency))
+		}
+		if !r.isFiat(entry.Sent) {
+			events = append(events, r.event(cost_basis.Disposal, entry, entry.Sent, value))
+		}
+		if !r.isFiat(entry.Received) {
+			events = append(events, r.event(cost_basis.Acquisition, entry, entry.Received, value))
+		}
+
+	case slices.Contains(INCOME_KINDS, entry.Kind) && !entry.Received.IsZero() && !r.isFiat(entry.Received):
+		value, found := r.fairValue(entry, entry.Received, price)
+		if !found {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s: no price for %s income, acquired at zero cost", entry.ID, entry.Received.Currency))
+		}
+		events = append(events, r.event(cost_basis.Acquisition, entry, entry.Received, value))
+
+	case slices.Contains(SPENDING_KINDS, entry.Kind) && !entry.Sent.IsZero() && !r.isFiat(entry.Sent):
+		value, found := r.fairValue(entry, entry.Sent, price)
+		if !found {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s: no price for %s spent, proceeds of zero", entry.ID, entry.Sent.Currency))
+		}
+		events = append(events, r.event(cost_basis.Disposal, entry, entry.Sent, value))
+	}
+
+	if !entry.Fee.IsZero() && !r.isFiat(entry.Fee) {
+		value, found := r.fairValue(entry, entry.Fee, price)
+		if !found {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s: no price for %s fee, proceeds of zero", entry.ID, entry.Fee.Currency))
+		}
+		events = append(events, r.event(cost_basis.Disposal, entry, entry.Fee, value))
+	}
+
+	return events
+}
+
+// tradeValue is the fiat value of a trade: the fiat side if there is one, or
+// else the fair value of what was received or sent.
+func (r *Report) tradeValue(entry ledger.Entry, price ledger.PriceFunc) (decimal.Decimal, bool) {
+	if r.isFiat(entry.Received) {
+		return entry.Received.Value, true
+	}
+	if r.isFiat(entry.Sent) {
+		return entry.Sent.Value, true
+	}
+	if value, found := r.fairValue(entry, entry.Received, price); found {
+		return value, true
+	}
+	return r.fairValue(entry, entry.Sent, price)
+}
+
+func (r *Report) fairValue(entry ledger.Entry, amount ledger.Amount, price ledger.PriceFunc) (decimal.Decimal, bool) {
+	if entry.Price.Currency == r.Fiat && !entry.Price.IsZero() && entry.Main().Currency == amount.Currency {
+		return amount.Value.Mul(entry.Price.Value), true
+	}
+	if price != nil {
+		if unitPrice, found := price(amount.Currency, entry.Network, entry.Time); found {
+			return amount.Value.Mul(unitPrice), true
+		}
+	}
+	return decimal.Zero, false
+}
+
+func (r *Report) isFiat(amount ledger.Amount) bool {
+	return strings.ToUpper(amount.Currency) == r.Fiat
+}
+
+func (r *Report) addRealization(realization cost_basis.Realization) {
+	disposal := realization.Disposal
+	total := disposal.Amount.Value
+
+	// Proceeds are split between lots by how much came out of each
+	proceedsOf := func(amount decimal.Decimal) decimal.Decimal {
+		if total.IsZero() {
+			return decimal.Zero
+		}
+		return realization.Proceeds.Mul(amount).Div(total)
+	}
+
+	for _, usage := range realization.Lots {
+		proceeds := proceedsOf(usage.Amount.Value)
+		r.addRow(Row{
+			Description: describe(usage.Amount),
+			Acquired:    usage.Acquired,
+			Sold:        disposal.Time,
+			Proceeds:    proceeds,
+			CostBasis:   usage.Cost,
+			Gain:        proceeds.Sub(usage.Cost),
+			Term:        term(usage.Acquired, disposal.Time, r.Period.From.Location()),
+			ID:          disposal.ID,
+		})
+	}
+
+	if realization.Unmatched.Value.IsPositive() {
+		proceeds := proceedsOf(realization.Unmatched.Value)
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s: no lots for %s, reported with zero cost basis", disposal.ID, describe(realization.Unmatched)))
+		r.addRow(Row{
+			Description: describe(realization.Unmatched),
+			Sold:        disposal.Time,
+			Proceeds:    proceeds,
+			CostBasis:   decimal.Zero,
+			Gain:        proceeds,
+			Term:        ShortTerm,
+			ID:          disposal.ID,
+		})
+	}
+}
+
+func (r *Report) addRow(row Row) {
+	r.Rows = append(r.Rows, row)
+
+	totals := r.Totals[row.Term]
+	totals.Rows++
+	totals.Proceeds = totals.Proceeds.Add(row.Proceeds)
+	totals.CostBasis = totals.CostBasis.Add(row.CostBasis)
+	totals.Gain = totals.Gain.Add(row.Gain)
+	r.Totals[row.Term] = totals
+}
+
+// Assets the handlers knew are pooled by their canonical ID, so the same token
+// bridged or wrapped is one asset while unrelated tokens that share a symbol
+// stay apart. Anything else is only known by currency, so the same currency on
+// every network is one asset, with the finest precision the ledger has shown
+// for it, which is as precise as the lots split under average cost can be.
+func (r *Report) event(kind cost_basis.EventKind, entry ledger.Entry, amount ledger.Amount, value decimal.Decimal) cost_basis.Event {
+	asset := core.Asset{
+		Identifier: amount.Currency,
+		Symbol:     amount.Currency,
+		Decimals:   r.decimals[amount.Currency],
+	}
+	if amount.Asset != nil {
+		asset = *amount.Asset
+		asset.Symbol = amount.Currency
+	}
+	return cost_basis.Event{
+		Kind:   kind,
+		ID:     entry.ID,
+		Time:   entry.Time,
+		Amount: core.Amount{Value: amount.Value, Asset: asset},
+		Value:  value,
+	}
+}
+
+func describe(amount core.Amount) string {
+	return fmt.Sprintf("%s %s", amount.Value.String(), amount.Asset.Symbol)
+}
+
+// precision is the number of decimal places of a value, as written.
+func precision(value decimal.Decimal) uint8 {
+	return uint8(min(max(-value.Exponent(), 0), 255))
+}
+
+// term is long if the lot was held for more than a year, which goes by
+// calendar date in the tax timezone: a sale on the anniversary of the
+// acquisition is still short-term, whatever the time of day.
+func term(acquired, sold time.Time, loc *time.Location) Term {
+	acquiredYear, acquiredMonth, acquiredDay := acquired.In(loc).Date()
+	anniversary := time.Date(acquiredYear+1, acquiredMonth, acquiredDay, 0, 0, 0, 0, time.UTC)
+
+	soldYear, soldMonth, soldDay := sold.In(loc).Date()
+	soldDate := time.Date(soldYear, soldMonth, soldDay, 0, 0, 0, 0, time.UTC)
+
+	if soldDate.After(anniversary) {
+		return LongTerm
+	}
+	return ShortTerm
+}
@@ -0,0 +1,229 @@
+package tax_report_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/cost_basis"
+	"github.com/ksmithbaylor/gohodl/internal/ledger"
+	"github.com/ksmithbaylor/gohodl/internal/tax_period"
+	"github.com/ksmithbaylor/gohodl/internal/tax_report"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var YEAR_2024 = tax_period.Period{
+	Name: "2024",
+	From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func amount(currency, value string) ledger.Amount {
+	return ledger.Amount{Currency: currency, Value: decimal.RequireFromString(value)}
+}
+
+func trade(id string, at time.Time, kind ledger.Kind, sent, received ledger.Amount) ledger.Entry {
+	return ledger.Entry{Time: at, Kind: kind, Sent: sent, Received: received, Network: "ethereum", ID: id}
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+func build(t *testing.T, price ledger.PriceFunc, entries ...ledger.Entry) *tax_report.Report {
+	t.Helper()
+
+	report, err := tax_report.Build(entries, YEAR_2024, cost_basis.FIFO, "usd", price)
+	assert.NoError(t, err)
+	return report
+}
+
+// Report tests
+
+func TestBuildSplitsTermsAndProceeds(t *testing.T) {
+	report := build(t, nil,
+		trade("buy-1", day(2022, time.June, 1), ledger.Buy, amount("USD", "1000"), amount("ETH", "1")),
+		trade("buy-2", day(2024, time.March, 1), ledger.Buy, amount("USD", "3000"), amount("ETH", "1")),
+		trade("sell", day(2024, time.June, 1), ledger.Sell, amount("ETH", "1.5"), amount("USD", "6000")),
+	)
+
+	assert.Empty(t, report.Warnings)
+	assert.Len(t, report.Rows, 2)
+
+	long := report.Rows[0]
+	assert.Equal(t, tax_report.LongTerm, long.Term)
+	assert.Equal(t, "1 ETH", long.Description)
+	assert.Equal(t, "4000", long.Proceeds.String())
+	assert.Equal(t, "1000", long.CostBasis.String())
+	assert.Equal(t, "3000", long.Gain.String())
+
+	short := report.Rows[1]
+	assert.Equal(t, tax_report.ShortTerm, short.Term)
+	assert.Equal(t, "0.5 ETH", short.Description)
+	assert.Equal(t, "2000", short.Proceeds.String())
+	assert.Equal(t, "1500", short.CostBasis.String())
+	assert.Equal(t, "500", short.Gain.String())
+
+	assert.Equal(t, "3500", report.NetGain().String())
+	assert.Equal(t, 1, report.Totals[tax_report.LongTerm].Rows)
+}
+
+func TestBuildOnlyReportsThePeriod(t *testing.T) {
+	report := build(t, nil,
+		trade("buy", day(2023, time.June, 1), ledger.Buy, amount("USD", "1000"), amount("ETH", "2")),
+		trade("sell-2023", day(2023, time.July, 1), ledger.Sell, amount("ETH", "1"), amount("USD", "900")),
+		trade("sell-2024", day(2024, time.July, 1), ledger.Sell, amount("ETH", "1"), amount("USD", "800")),
+		trade("sell-2025", day(2025, time.July, 1), ledger.Sell, amount("ETH", "1"), amount("USD", "700")),
+	)
+
+	assert.Len(t, report.Rows, 1)
+	assert.Equal(t, "sell-2024", report.Rows[0].ID)
+	assert.Equal(t, tax_report.LongTerm, report.Rows[0].Term)
+	assert.Equal(t, "300", report.Rows[0].Gain.String())
+}
+
+func TestBuildValuesCryptoTradesAndIncome(t *testing.T) {
+	price := func(currency, network string, at time.Time) (decimal.Decimal, bool) {
+		if currency == "ETH" {
+			return decimal.NewFromInt(2000), true
+		}
+		return decimal.Zero, false
+	}
+
+	report := build(t, price,
+		ledger.Entry{Time: day(2024, time.January, 1), Kind: ledger.Staking, Received: amount("ETH", "1"), ID: "reward"},
+		trade("swap", day(2024, time.February, 1), ledger.Sell, amount("ETH", "1"), amount("USDC", "2100")),
+		ledger.Entry{Time: day(2024, time.March, 1), Kind: ledger.Airdrop, Received: amount("SPAM", "100"), ID: "airdrop"},
+	)
+
+	assert.Len(t, report.Rows, 1)
+	assert.Equal(t, "2000", report.Rows[0].CostBasis.String())
+	assert.Equal(t, "2000", report.Rows[0].Proceeds.String())
+	assert.Equal(t, []string{"airdrop: no price for SPAM income, acquired at zero cost"}, report.Warnings)
+}
+
+func TestBuildUnmatchedDisposal(t *testing.T) {
+	report := build(t, nil,
+		trade("sell", day(2024, time.June, 1), ledger.Sell, amount("ETH", "1"), amount("USD", "2000")),
+	)
+
+	assert.Len(t, report.Rows, 1)
+	assert.True(t, report.Rows[0].Acquired.IsZero())
+	assert.Equal(t, "2000", report.Rows[0].Gain.String())
+	assert.Equal(t, []string{"sell: no lots for 1 ETH, reported with zero cost basis"}, report.Warnings)
+
+	var buf bytes.Buffer
+	assert.NoError(t, tax_report.WriteForm8949(&buf, report))
+	assert.Equal(t, `Description,Date Acquired,Date Sold,Proceeds,Cost Basis,Gain or Loss,Term,ID
+1 ETH,UNKNOWN,06/01/2024,2000.00,0.00,2000.00,short,sell
+`, buf.String())
+}
+
+func TestWriteScheduleD(t *testing.T) {
+	report := build(t, nil,
+		trade("buy", day(2024, time.March, 1), ledger.Buy, amount("USD", "1000"), amount("ETH", "1")),
+		trade("sell", day(2024, time.June, 1), ledger.Sell, amount("ETH", "1"), amount("USD", "1250.5")),
+	)
+
+	var text bytes.Buffer
+	assert.NoError(t, tax_report.WriteScheduleD(&text, report))
+	assert.Contains(t, text.String(), "Part I (short-term)  1     1250.50   1000.00     250.50")
+	assert.Contains(t, text.String(), "Net gain or loss: 250.50\n")
+
+	var html bytes.Buffer
+	assert.NoError(t, tax_report.WriteScheduleDHTML(&html, report))
+	assert.Contains(t, html.String(), `<td class="amount">250.50</td>`)
+	assert.NotContains(t, html.String(), "Warnings")
+}
+
+func TestBuildDisposesOfFees(t *testing.T) {
+	price := func(currency, network string, at time.Time) (decimal.Decimal, bool) {
+		return decimal.NewFromInt(3000), currency == "ETH"
+	}
+
+	report := build(t, price,
+		trade("buy", day(2024, time.January, 1), ledger.Buy, amount("USD", "2000"), amount("ETH", "1")),
+		ledger.Entry{Time: day(2024, time.February, 1), Kind: ledger.Send, Sent: amount("ETH", "0.5"), Fee: amount("ETH", "0.01"), ID: "send"},
+		ledger.Entry{Time: day(2024, time.March, 1), Kind: ledger.Fee, Fee: amount("ETH", "0.01"), ID: "approve"},
+		ledger.Entry{Time: day(2024, time.April, 1), Kind: ledger.Fee, Fee: amount("USD", "5"), ID: "fiat-fee"},
+		trade("sell", day(2024, time.June, 1), ledger.Sell, amount("ETH", "0.98"), amount("USD", "2940")),
+	)
+
+	assert.Empty(t, report.Warnings)
+	assert.Len(t, report.Rows, 3)
+
+	assert.Equal(t, "send", report.Rows[0].ID)
+	assert.Equal(t, "0.01 ETH", report.Rows[0].Description)
+	assert.Equal(t, "30", report.Rows[0].Proceeds.String())
+	assert.Equal(t, "20", report.Rows[0].CostBasis.String())
+
+	assert.Equal(t, "approve", report.Rows[1].ID)
+
+	// The gas left the pool, so the sale doesn't match it
+	assert.Equal(t, "sell", report.Rows[2].ID)
+	assert.Equal(t, "1960", report.Rows[2].CostBasis.String())
+}
+
+func TestBuildTermGoesByCalendarDate(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.NoError(t, err)
+	period := tax_period.Period{
+		Name: "2024",
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, chicago),
+		To:   time.Date(2025, 1, 1, 0, 0, 0, 0, chicago),
+	}
+
+	// Bought in the evening of June 1st in Chicago, which is June 2nd in UTC
+	bought := time.Date(2023, time.June, 2, 3, 0, 0, 0, time.UTC)
+	report, err := tax_report.Build([]ledger.Entry{
+		trade("buy", bought, ledger.Buy, amount("USD", "1000"), amount("ETH", "3")),
+		trade("anniversary", time.Date(2024, time.June, 1, 23, 30, 0, 0, chicago), ledger.Sell, amount("ETH", "1"), amount("USD", "500")),
+		trade("next-day-utc", time.Date(2024, time.June, 2, 4, 0, 0, 0, time.UTC), ledger.Sell, amount("ETH", "1"), amount("USD", "500")),
+		trade("next-day", time.Date(2024, time.June, 2, 0, 0, 0, 0, chicago), ledger.Sell, amount("ETH", "1"), amount("USD", "500")),
+	}, period, cost_basis.FIFO, "usd", nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, report.Rows, 3)
+	assert.Equal(t, tax_report.ShortTerm, report.Rows[0].Term)
+	assert.Equal(t, tax_report.ShortTerm, report.Rows[1].Term)
+	assert.Equal(t, tax_report.LongTerm, report.Rows[2].Term)
+}
+
+func TestBuildKeepsLedgerPrecision(t *testing.T) {
+	report, err := tax_report.Build([]ledger.Entry{
+		trade("buy-1", day(2024, time.January, 1), ledger.Buy, amount("USD", "100"), amount("BTC", "1.00000000")),
+		trade("buy-2", day(2024, time.February, 1), ledger.Buy, amount("USD", "200"), amount("BTC", "2.00000000")),
+		trade("sell", day(2024, time.March, 1), ledger.Sell, amount("BTC", "1.00000000"), amount("USD", "150")),
+	}, YEAR_2024, cost_basis.AverageCost, "usd", nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, report.Rows, 2)
+	assert.Equal(t, "0.33333333 BTC", report.Rows[0].Description)
+	assert.Equal(t, "0.66666667 BTC", report.Rows[1].Description)
+}
+
+func TestBuildPoolsByAsset(t *testing.T) {
+	token := func(network, identifier, value string) ledger.Amount {
+		a := amount("USDC", value)
+		a.Asset = &core.Asset{
+			NetworkKind: core.EvmNetworkKind,
+			NetworkName: network,
+			Kind:        core.Erc20Token,
+			Identifier:  identifier,
+			Symbol:      "USDC",
+			Decimals:    6,
+		}
+		return a
+	}
+
+	report := build(t, nil,
+		trade("buy-eth", day(2024, time.January, 1), ledger.Buy, amount("USD", "100"), token("ethereum", "0xa0b8", "100")),
+		trade("buy-fake", day(2024, time.February, 1), ledger.Buy, amount("USD", "1"), token("polygon", "0xdead", "100")),
+		trade("sell", day(2024, time.March, 1), ledger.Sell, token("polygon", "0xdead", "100"), amount("USD", "2")),
+	)
+
+	assert.Len(t, report.Rows, 1)
+	assert.Equal(t, "1", report.Rows[0].CostBasis.String())
+}
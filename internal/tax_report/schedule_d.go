@@ -0,0 +1,110 @@
+package tax_report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+)
+
+// A ScheduleDLine is the total of one holding period, as it goes on Schedule D.
+type ScheduleDLine struct {
+	Part  string // Part of Schedule D the line belongs in
+	Term  Term
+	Total Totals
+}
+
+// ScheduleD returns the totals of each holding period, short-term first.
+func (r *Report) ScheduleD() []ScheduleDLine {
+	return []ScheduleDLine{
+		{Part: "Part I (short-term)", Term: ShortTerm, Total: r.Totals[ShortTerm]},
+		{Part: "Part II (long-term)", Term: LongTerm, Total: r.Totals[LongTerm]},
+	}
+}
+
+// NetGain is the gain or loss of the whole period, before any carryover.
+func (r *Report) NetGain() decimal.Decimal {
+	return r.Totals[ShortTerm].Gain.Add(r.Totals[LongTerm].Gain)
+}
+
+// WriteScheduleD writes a plain text summary of the Schedule D totals and any
+// warnings, meant for printing.
+func WriteScheduleD(w io.Writer, report *Report) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "Schedule D summary for %s\n", report.Period)
+	fmt.Fprintf(&out, "Cost basis method: %s, amounts in %s\n\n", report.Method, report.Fiat)
+
+	table := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Holding period\tRows\tProceeds\tCost Basis\tGain or Loss\t")
+	for _, line := range report.ScheduleD() {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\t\n", line.Part, line.Total.Rows, money(line.Total.Proceeds), money(line.Total.CostBasis), money(line.Total.Gain))
+	}
+	table.Flush()
+
+	fmt.Fprintf(&out, "\nNet gain or loss: %s\n", money(report.NetGain()))
+
+	if len(report.Warnings) > 0 {
+		fmt.Fprintf(&out, "\n%d warnings:\n", len(report.Warnings))
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(&out, "  %s\n", warning)
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+var SCHEDULE_D_HTML = template.Must(template.New("schedule_d").Funcs(template.FuncMap{
+	"money": money,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Schedule D summary for {{.Report.Period.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #999; padding: 0.25em 0.75em; }
+td.amount { text-align: right; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Schedule D summary for {{.Report.Period.Name}}</h1>
+<p>{{.Report.Period}}<br>Cost basis method: {{.Report.Method}}, amounts in {{.Report.Fiat}}</p>
+
+<table>
+<tr><th>Holding period</th><th>Rows</th><th>Proceeds</th><th>Cost Basis</th><th>Gain or Loss</th></tr>
+{{- range .Lines}}
+<tr><td>{{.Part}}</td><td class="amount">{{.Total.Rows}}</td><td class="amount">{{money .Total.Proceeds}}</td><td class="amount">{{money .Total.CostBasis}}</td><td class="amount">{{money .Total.Gain}}</td></tr>
+{{- end}}
+<tr><th colspan="4">Net gain or loss</th><td class="amount">{{money .Report.NetGain}}</td></tr>
+</table>
+{{- if .Report.Warnings}}
+
+<h2>Warnings</h2>
+<ul>
+{{- range .Report.Warnings}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteScheduleDHTML writes the same summary as WriteScheduleD as a page that
+// can be opened and printed from a browser.
+func WriteScheduleDHTML(w io.Writer, report *Report) error {
+	err := SCHEDULE_D_HTML.Execute(w, struct {
+		Report *Report
+		Lines  []ScheduleDLine
+	}{report, report.ScheduleD()})
+	if err != nil {
+		return fmt.Errorf("Error writing Schedule D summary: %w", err)
+	}
+	return nil
+}
@@ -56,6 +56,28 @@ func (db *FileDB) NewCollection(collectionName string) *FileDBCollection {
 	return collection
 }
 
+// OpenCollection returns a collection that was written to in this or a
+// previous run, or false if it does not exist on disk.
+func (db *FileDB) OpenCollection(collectionName string) (*FileDBCollection, bool) {
+	if collection, found := db.Collections[collectionName]; found {
+		return collection, true
+	}
+
+	collection := &FileDBCollection{
+		DB:   db,
+		Name: collectionName,
+	}
+
+	info, err := os.Stat(collection.Folder())
+	if err != nil || !info.IsDir() {
+		return nil, false
+	}
+
+	db.Collections[collectionName] = collection
+
+	return collection, true
+}
+
 func (c *FileDBCollection) Write(key string, value any) error {
 	path := c.pathFor(key)
 
@@ -114,6 +136,20 @@ func (c *FileDBCollection) Read(key string, val any) (bool, error) {
 	return true, nil
 }
 
+// Delete removes a key, if it exists.
+func (c *FileDBCollection) Delete(key string) error {
+	err := os.Remove(c.pathFor(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Could not delete key %s from collection %s: %w", key, c.Name, err)
+	}
+	return nil
+}
+
+func (c *FileDBCollection) Has(key string) bool {
+	_, err := os.Stat(c.pathFor(key))
+	return err == nil
+}
+
 func (c *FileDBCollection) List() ([]string, error) {
 	entries, err := os.ReadDir(c.Folder())
 	if err != nil {
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"sync"
+)
+
+// RunPool runs the jobs on at most the given number of workers at once, and
+// returns once all of them are done.
+func RunPool(workers int, jobs []func()) {
+	queue := make(chan func())
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(workers, len(jobs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+
+	wg.Wait()
+}
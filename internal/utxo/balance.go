@@ -0,0 +1,67 @@
+package utxo
+
+// A BalanceChange is how a transaction moved funds in and out of a set of
+// addresses, in atomic units.
+type BalanceChange struct {
+	Received uint64 // Paid to the addresses
+	Spent    uint64 // Spent from the addresses, the fee included
+	Fee      uint64 // The fee, if the addresses funded all of the transaction
+	Coinbase bool   // Newly mined rather than paid by anyone
+	From     string // First of the addresses spent from, if any
+	To       string // First of the addresses paid to, if any
+}
+
+// Addresses returns every address a transaction spends from or pays to.
+func (tx *EsploraTx) Addresses() []string {
+	addresses := make([]string, 0, len(tx.Vin)+len(tx.Vout))
+	for _, input := range tx.Vin {
+		if input.Prevout != nil && input.Prevout.ScriptPubKeyAddress != "" {
+			addresses = append(addresses, input.Prevout.ScriptPubKeyAddress)
+		}
+	}
+	for _, output := range tx.Vout {
+		if output.ScriptPubKeyAddress != "" {
+			addresses = append(addresses, output.ScriptPubKeyAddress)
+		}
+	}
+	return addresses
+}
+
+// BalanceChange adds up the inputs and outputs of a transaction that belong
+// to the addresses isMine accepts.
+func (tx *EsploraTx) BalanceChange(isMine func(address string) bool) BalanceChange {
+	var change BalanceChange
+	funded := len(tx.Vin) > 0
+
+	for _, input := range tx.Vin {
+		if input.IsCoinbase {
+			change.Coinbase = true
+		}
+		if input.Prevout == nil || !isMine(input.Prevout.ScriptPubKeyAddress) {
+			funded = false
+			continue
+		}
+		change.Spent += input.Prevout.Value
+		if change.From == "" {
+			change.From = input.Prevout.ScriptPubKeyAddress
+		}
+	}
+
+	for _, output := range tx.Vout {
+		if !isMine(output.ScriptPubKeyAddress) {
+			continue
+		}
+		change.Received += output.Value
+		if change.To == "" {
+			change.To = output.ScriptPubKeyAddress
+		}
+	}
+
+	// The fee can't be split between senders, so it only counts when all of
+	// the inputs were spent from the addresses
+	if funded {
+		change.Fee = tx.Fee
+	}
+
+	return change
+}
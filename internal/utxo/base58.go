@@ -0,0 +1,91 @@
+package utxo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const BASE58_ALPHABET = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(data []byte) string {
+	num := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	encoded := make([]byte, 0, len(data)*138/100+1)
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		encoded = append(encoded, BASE58_ALPHABET[mod.Int64()])
+	}
+
+	// Leading zero bytes are encoded as leading ones
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, BASE58_ALPHABET[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	num := new(big.Int)
+	radix := big.NewInt(58)
+
+	for _, c := range []byte(s) {
+		digit := bytes.IndexByte([]byte(BASE58_ALPHABET), c)
+		if digit < 0 {
+			return nil, errors.New("Invalid base58 character")
+		}
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(digit)))
+	}
+
+	decoded := num.Bytes()
+
+	leadingZeros := 0
+	for leadingZeros < len(s) && s[leadingZeros] == BASE58_ALPHABET[0] {
+		leadingZeros++
+	}
+
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
+
+func base58CheckEncode(version []byte, payload []byte) string {
+	data := make([]byte, 0, len(version)+len(payload)+4)
+	data = append(data, version...)
+	data = append(data, payload...)
+	data = append(data, checksum(data)...)
+	return base58Encode(data)
+}
+
+// Returns the decoded data (including any version bytes) without the checksum
+func base58CheckDecode(s string) ([]byte, error) {
+	data, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errors.New("Base58 data too short for checksum")
+	}
+
+	payload, sum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(checksum(payload), sum) {
+		return nil, errors.New("Invalid base58 checksum")
+	}
+
+	return payload, nil
+}
+
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
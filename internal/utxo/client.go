@@ -0,0 +1,42 @@
+package utxo
+
+import (
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+type Client struct {
+	Network Network        // The network the client is for
+	Esplora *EsploraClient // Used both for chain state and address indexing
+}
+
+func NewClient(network Network) (*Client, error) {
+	esplora, err := NewEsploraClient(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Network: network,
+		Esplora: esplora,
+	}, nil
+}
+
+func NewIndexer(network Network) (core.Indexer, error) {
+	return NewEsploraClient(network)
+}
+
+func (c *Client) LatestBlock() (uint64, error) {
+	return c.Esplora.LatestBlock()
+}
+
+func (c *Client) GetTransaction(txid string) (*EsploraTx, error) {
+	return c.Esplora.GetTransaction(txid)
+}
+
+func (c *Client) NativeAsset() (core.Asset, error) {
+	return c.Network.NativeAsset(), nil
+}
+
+func (c *Client) OpenTransactionInExplorer(hash string, wait ...bool) {
+	c.Network.OpenTransactionInExplorer(hash, wait...)
+}
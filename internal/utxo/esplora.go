@@ -0,0 +1,229 @@
+package utxo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/util"
+)
+
+const ESPLORA_RPS = 5
+const ESPLORA_PAGE_SIZE = 25
+
+// An EsploraClient talks to an Esplora-compatible API (blockstream.info,
+// mempool.space, or a self-hosted electrs).
+type EsploraClient struct {
+	network  Network
+	url      string
+	http     *http.Client
+	throttle <-chan time.Time
+}
+
+type EsploraTx struct {
+	Txid string `json:"txid"`
+	Vin  []struct {
+		Txid       string         `json:"txid"`
+		Vout       uint32         `json:"vout"`
+		Prevout    *EsploraOutput `json:"prevout"`
+		IsCoinbase bool           `json:"is_coinbase"`
+	} `json:"vin"`
+	Vout   []EsploraOutput `json:"vout"`
+	Fee    uint64          `json:"fee"`
+	Status struct {
+		Confirmed   bool   `json:"confirmed"`
+		BlockHeight int    `json:"block_height"`
+		BlockHash   string `json:"block_hash"`
+		BlockTime   int64  `json:"block_time"`
+	} `json:"status"`
+}
+
+type EsploraOutput struct {
+	ScriptPubKeyAddress string `json:"scriptpubkey_address"`
+	Value               uint64 `json:"value"`
+}
+
+type esploraAddressStats struct {
+	Address    string `json:"address"`
+	ChainStats struct {
+		TxCount int `json:"tx_count"`
+	} `json:"chain_stats"`
+	MempoolStats struct {
+		TxCount int `json:"tx_count"`
+	} `json:"mempool_stats"`
+}
+
+func NewEsploraClient(network Network) (*EsploraClient, error) {
+	if network.Esplora.URL == "" {
+		return nil, fmt.Errorf("No esplora URL configured for %s", network.Name)
+	}
+
+	rps := network.Esplora.RPS
+	if rps == 0 {
+		rps = ESPLORA_RPS
+	}
+
+	return &EsploraClient{
+		network:  network,
+		url:      strings.TrimSuffix(network.Esplora.URL, "/"),
+		http:     &http.Client{Timeout: 30 * time.Second},
+		throttle: time.Tick(time.Second / time.Duration(rps)),
+	}, nil
+}
+
+func (c *EsploraClient) LatestBlock() (uint64, error) {
+	body, err := c.get("/blocks/tip/height")
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := strconv.ParseUint(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid tip height from %s: %w", c.url, err)
+	}
+
+	return height, nil
+}
+
+func (c *EsploraClient) GetTransaction(txid string) (*EsploraTx, error) {
+	var tx EsploraTx
+	err := c.getJSON("/tx/"+txid, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (c *EsploraClient) AddressTxCount(address string) (int, error) {
+	var stats esploraAddressStats
+	err := c.getJSON("/address/"+address, &stats)
+	if err != nil {
+		return 0, err
+	}
+	return stats.ChainStats.TxCount + stats.MempoolStats.TxCount, nil
+}
+
+// AddressTransactions pages through the confirmed transactions of an address,
+// newest first, stopping once it is past the start block.
+func (c *EsploraClient) AddressTransactions(address string, startBlock, endBlock *int) ([]EsploraTx, error) {
+	txs := make([]EsploraTx, 0)
+	lastSeen := ""
+
+	for {
+		path := "/address/" + address + "/txs/chain"
+		if lastSeen != "" {
+			path += "/" + lastSeen
+		}
+
+		var page []EsploraTx
+		err := c.getJSON(path, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range page {
+			height := tx.Status.BlockHeight
+			if endBlock != nil && height > *endBlock {
+				continue
+			}
+			if startBlock != nil && height < *startBlock {
+				return txs, nil
+			}
+			txs = append(txs, tx)
+		}
+
+		if len(page) < ESPLORA_PAGE_SIZE {
+			return txs, nil
+		}
+		lastSeen = page[len(page)-1].Txid
+	}
+}
+
+// GetAllTransactionHashes accepts either a single address or a wallet
+// descriptor (see Wallet.Descriptor), in which case the transactions of every
+// used address in the wallet are returned.
+func (c *EsploraClient) GetAllTransactionHashes(address string, startBlock, endBlock *int) ([]string, error) {
+	addresses := []string{address}
+
+	if wallet, ok := ParseWalletDescriptor(address); ok {
+		derived, err := c.DiscoverAddresses(wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = make([]string, 0, len(derived))
+		for _, d := range derived {
+			addresses = append(addresses, d.Address)
+		}
+	}
+
+	hashLists := make([][]string, 0, len(addresses))
+	for _, addr := range addresses {
+		txs, err := c.AddressTransactions(addr, startBlock, endBlock)
+		if err != nil {
+			return nil, fmt.Errorf("Could not get txs for %s: %w", addr, err)
+		}
+
+		hashes := make([]string, len(txs))
+		for i, tx := range txs {
+			hashes[i] = tx.Txid
+		}
+		hashLists = append(hashLists, hashes)
+	}
+
+	return util.UniqueItems(hashLists...), nil
+}
+
+// DiscoverAddresses scans the receive and change chains of a wallet, returning
+// every address that has been used. Scanning a chain stops after the network's
+// gap limit of consecutive unused addresses.
+func (c *EsploraClient) DiscoverAddresses(wallet Wallet) ([]DerivedAddress, error) {
+	used, err := discoverAddresses(c.network, wallet, c.AddressTxCount)
+	if err != nil {
+		return nil, err
+	}
+
+	util.Debugf("Found %d used addresses for wallet %s on %s\n", len(used), wallet.Label, c.network.Name)
+
+	return used, nil
+}
+
+func (c *EsploraClient) getJSON(path string, result any) error {
+	body, err := c.get(path)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("Could not decode response from %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *EsploraClient) get(path string) ([]byte, error) {
+	<-c.throttle
+
+	util.Debugf("GET %s%s\n", c.url, path)
+	resp, err := c.http.Get(c.url + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected status %d from %s: %s", resp.StatusCode, path, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,100 @@
+package utxo
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+const DEFAULT_GAP_LIMIT = 20
+
+type AddressParams struct {
+	PubKeyHashVersion byte
+	ScriptHashVersion byte
+	Bech32Hrp         string
+}
+
+var ADDRESS_PARAMS = map[string]AddressParams{
+	"bitcoin":         {0x00, 0x05, "bc"},
+	"bitcoin-testnet": {0x6f, 0xc4, "tb"},
+	"litecoin":        {0x30, 0x32, "ltc"},
+	"dogecoin":        {0x1e, 0x16, ""},
+}
+
+type Network struct {
+	Name              string `mapstructure:"name"`
+	Chain             string `mapstructure:"chain"` // Key into ADDRESS_PARAMS, defaults to Name
+	NativeAssetSymbol string `mapstructure:"native_asset"`
+	GapLimit          int    `mapstructure:"gap_limit"`
+	Deprecated        bool   `mapstructure:"deprecated"`
+	ExplorerURLs      struct {
+		Tx   string `mapstructure:"tx"`
+		Addr string `mapstructure:"addr"`
+	} `mapstructure:"explorer_urls"`
+	Esplora struct {
+		URL string `mapstructure:"url"`
+		RPS uint   `mapstructure:"rps"`
+	} `mapstructure:"esplora"`
+}
+
+func (n Network) GetKind() core.NetworkKind {
+	return core.UtxoNetworkKind
+}
+
+func (n Network) GetName() string {
+	return n.Name
+}
+
+func (n Network) GetDeprecated() bool {
+	return n.Deprecated
+}
+
+func (n Network) NativeAsset() core.Asset {
+	return core.Asset{
+		NetworkKind: core.UtxoNetworkKind,
+		NetworkName: n.Name,
+		Kind:        core.UtxoNative,
+		Identifier:  n.NativeAssetSymbol,
+		Symbol:      n.NativeAssetSymbol,
+		Decimals:    8,
+	}
+}
+
+func (n Network) AddressParams() (AddressParams, error) {
+	chain := n.Chain
+	if chain == "" {
+		chain = n.Name
+	}
+
+	params, ok := ADDRESS_PARAMS[chain]
+	if !ok {
+		return AddressParams{}, fmt.Errorf("Unknown address format for UTXO chain '%s'", chain)
+	}
+
+	return params, nil
+}
+
+func (n Network) gapLimit() int {
+	if n.GapLimit <= 0 {
+		return DEFAULT_GAP_LIMIT
+	}
+	return n.GapLimit
+}
+
+func (n Network) OpenTransactionInExplorer(hash string, wait ...bool) {
+	url := strings.Replace(n.ExplorerURLs.Tx, "TX", hash, 1)
+	time.Sleep(time.Millisecond * 2000)
+	cmd := exec.Command("/usr/bin/open", "-u", url, "-a", "Google Chrome")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Println(cmd.String())
+		fmt.Println(string(output))
+		fmt.Println(err.Error())
+	}
+	if len(wait) > 0 && wait[0] {
+		_, _ = fmt.Scanln()
+	}
+}
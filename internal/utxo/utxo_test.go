@@ -0,0 +1,236 @@
+package utxo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"github.com/ksmithbaylor/gohodl/internal/utxo"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from BIP-44 and BIP-84
+const BIP44_XPUB = "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj"
+const BIP84_ZPUB = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+var BITCOIN = utxo.ADDRESS_PARAMS["bitcoin"]
+
+////////////////////////////////////////////////////////////////////////////////
+// Derivation tests
+
+func TestParseExtendedPublicKey(t *testing.T) {
+	xpub, err := utxo.ParseExtendedPublicKey(BIP84_ZPUB)
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(3), xpub.Depth)
+	assert.Equal(t, BIP84_ZPUB, xpub.String())
+
+	_, err = utxo.ParseExtendedPublicKey(BIP84_ZPUB[:len(BIP84_ZPUB)-1] + "t")
+	assert.NotNil(t, err)
+}
+
+func TestDeriveP2wpkh(t *testing.T) {
+	wallet := utxo.Wallet{Scheme: core.P2wpkh, Key: BIP84_ZPUB}
+
+	addr, err := wallet.DeriveAddress(BITCOIN, utxo.RECEIVE_CHAIN, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", addr)
+
+	addr, err = wallet.DeriveAddress(BITCOIN, utxo.RECEIVE_CHAIN, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g", addr)
+
+	addr, err = wallet.DeriveAddress(BITCOIN, utxo.CHANGE_CHAIN, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el", addr)
+}
+
+func TestDeriveP2pkh(t *testing.T) {
+	wallet := utxo.Wallet{Scheme: core.P2pkh, Key: BIP44_XPUB}
+
+	addr, err := wallet.DeriveAddress(BITCOIN, utxo.RECEIVE_CHAIN, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", addr)
+}
+
+func TestWalletDescriptor(t *testing.T) {
+	wallet := utxo.Wallet{Scheme: core.P2shP2wpkh, Key: BIP44_XPUB}
+
+	parsed, ok := utxo.ParseWalletDescriptor(wallet.Descriptor())
+	assert.True(t, ok)
+	assert.Equal(t, wallet, parsed)
+
+	_, ok = utxo.ParseWalletDescriptor("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu")
+	assert.False(t, ok)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Esplora tests
+
+type fakeEsplora struct {
+	txs map[string][]utxo.EsploraTx // address -> txs, newest first
+}
+
+func (f fakeEsplora) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api")
+
+	switch {
+	case path == "/blocks/tip/height":
+		_, _ = w.Write([]byte("850000"))
+	case strings.HasSuffix(path, "/txs/chain"):
+		address := strings.TrimSuffix(strings.TrimPrefix(path, "/address/"), "/txs/chain")
+		_ = json.NewEncoder(w).Encode(f.txs[address])
+	case strings.HasPrefix(path, "/address/"):
+		address := strings.TrimPrefix(path, "/address/")
+		stats := map[string]any{
+			"address":       address,
+			"chain_stats":   map[string]int{"tx_count": len(f.txs[address])},
+			"mempool_stats": map[string]int{"tx_count": 0},
+		}
+		_ = json.NewEncoder(w).Encode(stats)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func esploraTx(txid string, height int) utxo.EsploraTx {
+	var tx utxo.EsploraTx
+	tx.Txid = txid
+	tx.Status.Confirmed = true
+	tx.Status.BlockHeight = height
+	return tx
+}
+
+func newTestNetwork(url string) utxo.Network {
+	network := utxo.Network{Name: "bitcoin", NativeAssetSymbol: "BTC", GapLimit: 5}
+	network.Esplora.URL = url + "/api"
+	network.Esplora.RPS = 1000
+	return network
+}
+
+func TestDiscoverAddresses(t *testing.T) {
+	wallet := utxo.Wallet{Label: "savings", Scheme: core.P2wpkh, Key: BIP84_ZPUB}
+
+	derive := func(chain, index uint32) string {
+		addr, err := wallet.DeriveAddress(BITCOIN, chain, index)
+		assert.Nil(t, err)
+		return addr
+	}
+
+	// Receive addresses 0 and 4 are used (a gap of 3, within the limit of 5),
+	// and 10 is past the gap limit so should not be found.
+	fake := fakeEsplora{txs: map[string][]utxo.EsploraTx{
+		derive(utxo.RECEIVE_CHAIN, 0):  {esploraTx("a", 100)},
+		derive(utxo.RECEIVE_CHAIN, 4):  {esploraTx("c", 300), esploraTx("b", 200)},
+		derive(utxo.RECEIVE_CHAIN, 10): {esploraTx("x", 400)},
+		derive(utxo.CHANGE_CHAIN, 0):   {esploraTx("c", 300)},
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := utxo.NewClient(newTestNetwork(server.URL))
+	assert.Nil(t, err)
+
+	block, err := client.LatestBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(850000), block)
+
+	used, err := client.Esplora.DiscoverAddresses(wallet)
+	assert.Nil(t, err)
+	assert.Equal(t, []utxo.DerivedAddress{
+		{Address: derive(utxo.RECEIVE_CHAIN, 0), Chain: utxo.RECEIVE_CHAIN, Index: 0, TxCount: 1},
+		{Address: derive(utxo.RECEIVE_CHAIN, 4), Chain: utxo.RECEIVE_CHAIN, Index: 4, TxCount: 2},
+		{Address: derive(utxo.CHANGE_CHAIN, 0), Chain: utxo.CHANGE_CHAIN, Index: 0, TxCount: 1},
+	}, used)
+
+	hashes, err := client.Esplora.GetAllTransactionHashes(wallet.Descriptor(), nil, nil)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, hashes)
+
+	start := 150
+	hashes, err = client.Esplora.GetAllTransactionHashes(wallet.Descriptor(), &start, nil)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"b", "c"}, hashes)
+}
+
+func TestNewClientRequiresEsplora(t *testing.T) {
+	_, err := utxo.NewClient(utxo.Network{Name: "bitcoin"})
+	assert.NotNil(t, err)
+}
+
+func TestUsedAddresses(t *testing.T) {
+	wallet := utxo.Wallet{Label: "savings", Scheme: core.P2wpkh, Key: BIP84_ZPUB}
+
+	derive := func(chain, index uint32) string {
+		addr, err := wallet.DeriveAddress(BITCOIN, chain, index)
+		assert.Nil(t, err)
+		return addr
+	}
+
+	seen := map[string]bool{
+		derive(utxo.RECEIVE_CHAIN, 0):  true,
+		derive(utxo.RECEIVE_CHAIN, 4):  true,
+		derive(utxo.RECEIVE_CHAIN, 10): true,
+		derive(utxo.CHANGE_CHAIN, 0):   true,
+	}
+
+	used, err := newTestNetwork("").UsedAddresses(wallet, seen)
+	assert.Nil(t, err)
+	assert.Equal(t, []utxo.DerivedAddress{
+		{Address: derive(utxo.RECEIVE_CHAIN, 0), Chain: utxo.RECEIVE_CHAIN, Index: 0, TxCount: 1},
+		{Address: derive(utxo.RECEIVE_CHAIN, 4), Chain: utxo.RECEIVE_CHAIN, Index: 4, TxCount: 1},
+		{Address: derive(utxo.CHANGE_CHAIN, 0), Chain: utxo.CHANGE_CHAIN, Index: 0, TxCount: 1},
+	}, used)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Balance tests
+
+func parseEsploraTx(t *testing.T, raw string) *utxo.EsploraTx {
+	var tx utxo.EsploraTx
+	assert.Nil(t, json.Unmarshal([]byte(raw), &tx))
+	return &tx
+}
+
+func TestBalanceChange(t *testing.T) {
+	mine := func(address string) bool {
+		return address == "mine1" || address == "mine2"
+	}
+
+	// Spends 5000 of mine, pays 3000 out, 1500 back as change and 500 in fees
+	spend := parseEsploraTx(t, `{
+		"txid": "spend",
+		"vin": [{"prevout": {"scriptpubkey_address": "mine1", "value": 5000}}],
+		"vout": [
+			{"scriptpubkey_address": "theirs", "value": 3000},
+			{"scriptpubkey_address": "mine2", "value": 1500}
+		],
+		"fee": 500
+	}`)
+	assert.ElementsMatch(t, []string{"mine1", "theirs", "mine2"}, spend.Addresses())
+	assert.Equal(t, utxo.BalanceChange{
+		Received: 1500,
+		Spent:    5000,
+		Fee:      500,
+		From:     "mine1",
+		To:       "mine2",
+	}, spend.BalanceChange(mine))
+
+	// Someone else pays the fee of a payment to me
+	receive := parseEsploraTx(t, `{
+		"txid": "receive",
+		"vin": [{"prevout": {"scriptpubkey_address": "theirs", "value": 2000}}],
+		"vout": [{"scriptpubkey_address": "mine1", "value": 1800}],
+		"fee": 200
+	}`)
+	assert.Equal(t, utxo.BalanceChange{Received: 1800, To: "mine1"}, receive.BalanceChange(mine))
+
+	coinbase := parseEsploraTx(t, `{
+		"txid": "coinbase",
+		"vin": [{"is_coinbase": true}],
+		"vout": [{"scriptpubkey_address": "mine1", "value": 625000000}]
+	}`)
+	assert.Equal(t, utxo.BalanceChange{Received: 625000000, Coinbase: true, To: "mine1"}, coinbase.BalanceChange(mine))
+}
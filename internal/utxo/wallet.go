@@ -0,0 +1,133 @@
+package utxo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ksmithbaylor/gohodl/internal/core"
+)
+
+const (
+	RECEIVE_CHAIN uint32 = 0
+	CHANGE_CHAIN  uint32 = 1
+)
+
+// A Wallet is the extended public key of a single account (m/purpose'/coin'/
+// account'), along with the kind of addresses derived from it.
+type Wallet struct {
+	Label  string
+	Scheme core.UtxoWalletScheme
+	Key    string
+}
+
+// A DerivedAddress is an address derived from a wallet at m/.../chain/index.
+type DerivedAddress struct {
+	Address string
+	Chain   uint32
+	Index   uint32
+	TxCount int
+}
+
+// Descriptor encodes the wallet as a single string, so it can be passed
+// anywhere an address is expected (like core.Indexer).
+func (w Wallet) Descriptor() string {
+	return string(w.Scheme) + ":" + w.Key
+}
+
+func ParseWalletDescriptor(descriptor string) (Wallet, bool) {
+	scheme, key, found := strings.Cut(descriptor, ":")
+	if !found {
+		return Wallet{}, false
+	}
+
+	switch core.UtxoWalletScheme(scheme) {
+	case core.P2pkh, core.P2shP2wpkh, core.P2wpkh:
+		return Wallet{Scheme: core.UtxoWalletScheme(scheme), Key: key}, true
+	default:
+		return Wallet{}, false
+	}
+}
+
+// DeriveAddress returns the address at m/.../chain/index for the wallet.
+func (w Wallet) DeriveAddress(params AddressParams, chain, index uint32) (string, error) {
+	xpub, err := ParseExtendedPublicKey(w.Key)
+	if err != nil {
+		return "", fmt.Errorf("Could not parse key for wallet %s: %w", w.Label, err)
+	}
+
+	child, err := xpub.Derive(chain, index)
+	if err != nil {
+		return "", fmt.Errorf("Could not derive %d/%d for wallet %s: %w", chain, index, w.Label, err)
+	}
+
+	return child.Address(w.Scheme, params)
+}
+
+// UsedAddresses finds the addresses of a wallet among those seen in
+// transactions already fetched, scanning its chains like DiscoverAddresses
+// does without asking an explorer.
+func (n Network) UsedAddresses(wallet Wallet, seen map[string]bool) ([]DerivedAddress, error) {
+	return discoverAddresses(n, wallet, func(address string) (int, error) {
+		if seen[address] {
+			return 1, nil
+		}
+		return 0, nil
+	})
+}
+
+// discoverAddresses derives the receive and change addresses of a wallet in
+// order, keeping the ones txCount says were used, until the network's gap
+// limit of consecutive unused addresses on each chain.
+func discoverAddresses(network Network, wallet Wallet, txCount func(address string) (int, error)) ([]DerivedAddress, error) {
+	params, err := network.AddressParams()
+	if err != nil {
+		return nil, err
+	}
+
+	xpub, err := ParseExtendedPublicKey(wallet.Key)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse key for wallet %s: %w", wallet.Label, err)
+	}
+
+	used := make([]DerivedAddress, 0)
+
+	for _, chain := range []uint32{RECEIVE_CHAIN, CHANGE_CHAIN} {
+		chainKey, err := xpub.Child(chain)
+		if err != nil {
+			return nil, err
+		}
+
+		gap := 0
+		for index := uint32(0); gap < network.gapLimit(); index++ {
+			child, err := chainKey.Child(index)
+			if err != nil {
+				return nil, err
+			}
+
+			address, err := child.Address(wallet.Scheme, params)
+			if err != nil {
+				return nil, err
+			}
+
+			count, err := txCount(address)
+			if err != nil {
+				return nil, fmt.Errorf("Could not check usage of %s: %w", address, err)
+			}
+
+			if count == 0 {
+				gap++
+				continue
+			}
+
+			gap = 0
+			used = append(used, DerivedAddress{
+				Address: address,
+				Chain:   chain,
+				Index:   index,
+				TxCount: count,
+			})
+		}
+	}
+
+	return used, nil
+}
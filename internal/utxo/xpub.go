@@ -0,0 +1,150 @@
+package utxo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ksmithbaylor/gohodl/internal/core"
+	"golang.org/x/crypto/ripemd160"
+)
+
+const HARDENED_KEY_START = 0x80000000
+
+// An ExtendedPublicKey is a BIP-32 extended public key (xpub, ypub, zpub, and
+// their testnet equivalents). Only public, non-hardened derivation is possible.
+type ExtendedPublicKey struct {
+	Version     [4]byte
+	Depth       uint8
+	Fingerprint [4]byte
+	ChildNumber uint32
+	ChainCode   [32]byte
+	PublicKey   [33]byte // Compressed secp256k1 public key
+}
+
+func ParseExtendedPublicKey(key string) (*ExtendedPublicKey, error) {
+	data, err := base58CheckDecode(key)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid extended public key: %w", err)
+	}
+	if len(data) != 78 {
+		return nil, fmt.Errorf("Invalid extended public key: expected 78 bytes, got %d", len(data))
+	}
+
+	var xpub ExtendedPublicKey
+	copy(xpub.Version[:], data[0:4])
+	xpub.Depth = data[4]
+	copy(xpub.Fingerprint[:], data[5:9])
+	xpub.ChildNumber = binary.BigEndian.Uint32(data[9:13])
+	copy(xpub.ChainCode[:], data[13:45])
+	copy(xpub.PublicKey[:], data[45:78])
+
+	if xpub.PublicKey[0] != 0x02 && xpub.PublicKey[0] != 0x03 {
+		return nil, errors.New("Invalid extended public key: not a public key")
+	}
+	if _, err := crypto.DecompressPubkey(xpub.PublicKey[:]); err != nil {
+		return nil, fmt.Errorf("Invalid extended public key: %w", err)
+	}
+
+	return &xpub, nil
+}
+
+func (k *ExtendedPublicKey) String() string {
+	data := make([]byte, 0, 78)
+	data = append(data, k.Version[:]...)
+	data = append(data, k.Depth)
+	data = append(data, k.Fingerprint[:]...)
+	data = binary.BigEndian.AppendUint32(data, k.ChildNumber)
+	data = append(data, k.ChainCode[:]...)
+	data = append(data, k.PublicKey[:]...)
+	return base58CheckEncode(nil, data)
+}
+
+// Child derives the non-hardened child key at the given index (BIP-32 CKDpub).
+func (k *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	if index >= HARDENED_KEY_START {
+		return nil, fmt.Errorf("Cannot derive hardened child %d from a public key", index)
+	}
+
+	mac := hmac.New(sha512.New, k.ChainCode[:])
+	mac.Write(k.PublicKey[:])
+	mac.Write(binary.BigEndian.AppendUint32(nil, index))
+	sum := mac.Sum(nil)
+
+	curve := crypto.S256()
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("Invalid child %d, try the next index", index)
+	}
+
+	parent, err := crypto.DecompressPubkey(k.PublicKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	tweakX, tweakY := curve.ScalarBaseMult(sum[:32])
+	childX, childY := curve.Add(parent.X, parent.Y, tweakX, tweakY)
+	if childX.Sign() == 0 && childY.Sign() == 0 {
+		return nil, fmt.Errorf("Invalid child %d, try the next index", index)
+	}
+
+	child := &ExtendedPublicKey{
+		Version:     k.Version,
+		Depth:       k.Depth + 1,
+		ChildNumber: index,
+	}
+	copy(child.Fingerprint[:], hash160(k.PublicKey[:])[:4])
+	copy(child.ChainCode[:], sum[32:])
+	copy(child.PublicKey[:], compressPoint(childX, childY))
+
+	return child, nil
+}
+
+// Derive follows a path of non-hardened child indexes.
+func (k *ExtendedPublicKey) Derive(path ...uint32) (*ExtendedPublicKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		key, err = key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Address encodes the key's public key as an address of the given scheme.
+func (k *ExtendedPublicKey) Address(scheme core.UtxoWalletScheme, params AddressParams) (string, error) {
+	pubKeyHash := hash160(k.PublicKey[:])
+
+	switch scheme {
+	case core.P2pkh:
+		return base58CheckEncode([]byte{params.PubKeyHashVersion}, pubKeyHash), nil
+	case core.P2shP2wpkh:
+		redeemScript := append([]byte{0x00, 0x14}, pubKeyHash...)
+		return base58CheckEncode([]byte{params.ScriptHashVersion}, hash160(redeemScript)), nil
+	case core.P2wpkh:
+		return segwitV0Encode(params.Bech32Hrp, pubKeyHash)
+	default:
+		return "", fmt.Errorf("Unsupported UTXO wallet scheme '%s'", scheme)
+	}
+}
+
+func compressPoint(x, y *big.Int) []byte {
+	compressed := make([]byte, 33)
+	compressed[0] = 0x02 | byte(y.Bit(0))
+	x.FillBytes(compressed[1:])
+	return compressed
+}
+
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	ripemd := ripemd160.New()
+	ripemd.Write(sha[:])
+	return ripemd.Sum(nil)
+}